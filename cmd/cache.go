@@ -0,0 +1,162 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/cache"
+)
+
+func init() {
+	rootCmd.AddCommand(NewCacheCmd())
+}
+
+type cacheOptions struct {
+	kinds   []string
+	output  string
+	maxAge  time.Duration
+	maxSize string
+}
+
+// NewCacheCmd creates a command to inspect and clean up the charts, provenance
+// files and keyrings chart-verifier caches between runs.
+func NewCacheCmd() *cobra.Command {
+	cacheOpts := &cacheOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "cache {list,prune,clear}",
+		Short: "Manages the charts, provenance files and keyrings cached by chart-verifier",
+	}
+
+	cmd.PersistentFlags().StringVar(&settings.RepositoryCache, "repository-cache", settings.RepositoryCache, "path to the directory containing cached repository indexes")
+	cmd.PersistentFlags().StringSliceVar(&cacheOpts.kinds, "kind", nil, fmt.Sprintf("only consider entries of the informed kinds: %s, %s or %s (default: all)", cache.ChartEntry, cache.ProvEntry, cache.KeyringEntry))
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "Lists the cache entries, least recently used first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, kinds, err := openCache(cacheOpts)
+			if err != nil {
+				return err
+			}
+			entries, err := store.List(kinds...)
+			if err != nil {
+				return err
+			}
+			return writeCacheEntries(cmd.OutOrStdout(), entries, cacheOpts.output)
+		},
+	}
+	listCmd.Flags().StringVarP(&cacheOpts.output, "output", "o", "", "the output format: default, json or yaml")
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.NoArgs,
+		Short: "Removes cache entries exceeding the age and size limits",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, kinds, err := openCache(cacheOpts)
+			if err != nil {
+				return err
+			}
+			maxSize, err := cache.ParseSize(cacheOpts.maxSize)
+			if err != nil {
+				return fmt.Errorf("invalid --max-size %q: %w", cacheOpts.maxSize, err)
+			}
+			removed, err := store.Prune(cache.Limits{MaxAge: cacheOpts.maxAge, MaxSize: maxSize}, nil, kinds...)
+			writeRemovedCacheEntries(cmd.OutOrStdout(), removed)
+			return err
+		},
+	}
+	pruneCmd.Flags().DurationVar(&cacheOpts.maxAge, "max-age", cache.DefaultMaxAge, "remove entries not used for longer than this duration (0 disables the limit)")
+	pruneCmd.Flags().StringVar(&cacheOpts.maxSize, "max-size", "1Gi", "remove the least recently used entries until the cache is smaller than this size (0 disables the limit)")
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Args:  cobra.NoArgs,
+		Short: "Removes all cache entries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, kinds, err := openCache(cacheOpts)
+			if err != nil {
+				return err
+			}
+			removed, err := store.Clear(kinds...)
+			writeRemovedCacheEntries(cmd.OutOrStdout(), removed)
+			return err
+		},
+	}
+
+	cmd.AddCommand(listCmd, pruneCmd, clearCmd)
+
+	return cmd
+}
+
+func openCache(cacheOpts *cacheOptions) (*cache.Cache, []cache.EntryKind, error) {
+	root := cache.Dir(settings)
+	if root == "" {
+		return nil, nil, errors.New("unable to determine the cache directory")
+	}
+
+	var kinds []cache.EntryKind
+	for _, kind := range cacheOpts.kinds {
+		kinds = append(kinds, cache.EntryKind(kind))
+	}
+
+	return cache.New(root), kinds, nil
+}
+
+func writeCacheEntries(out io.Writer, entries []cache.Entry, format string) error {
+	switch format {
+	case "json":
+		b, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(b))
+	case "yaml":
+		b, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, string(b))
+	default:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tNAME\tSIZE\tLAST USED")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", e.Kind, e.Name, e.Size, e.ModTime.Format(time.RFC3339))
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+func writeRemovedCacheEntries(out io.Writer, removed []cache.Entry) {
+	var total int64
+	for _, e := range removed {
+		fmt.Fprintf(out, "removed %s %s\n", e.Kind, e.Name)
+		total += e.Size
+	}
+	fmt.Fprintf(out, "%d entries removed, %d bytes freed\n", len(removed), total)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/cache"
+)
+
+// newTestCache returns a repository cache directory with a chart, a
+// provenance file and a keyring entry, the chart not used for two days.
+func newTestCache(t *testing.T) string {
+	repositoryCache := t.TempDir()
+	store := cache.New(cache.Dir(&cli.EnvSettings{RepositoryCache: repositoryCache}))
+	for kind, age := range map[cache.EntryKind]time.Duration{
+		cache.ChartEntry:   48 * time.Hour,
+		cache.ProvEntry:    time.Hour,
+		cache.KeyringEntry: time.Hour,
+	} {
+		dir := store.EntryDir(kind, "entry")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, 10), 0o644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(dir, modTime, modTime))
+	}
+	return repositoryCache
+}
+
+func executeCacheCmd(t *testing.T, args ...string) (string, error) {
+	out := bytes.NewBufferString("")
+	cmd := NewCacheCmd()
+	cmd.SetOut(out)
+	cmd.SetErr(bytes.NewBufferString(""))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func listCacheKinds(t *testing.T, repositoryCache string) []cache.EntryKind {
+	out, err := executeCacheCmd(t, "list", "--repository-cache", repositoryCache, "-o", "json")
+	require.NoError(t, err)
+	var entries []cache.Entry
+	require.NoError(t, json.Unmarshal([]byte(out), &entries))
+	var kinds []cache.EntryKind
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func TestCacheCmd(t *testing.T) {
+	// --repository-cache sets the shared settings of the commands.
+	savedRepositoryCache := settings.RepositoryCache
+	t.Cleanup(func() { settings.RepositoryCache = savedRepositoryCache })
+
+	t.Run("Should list entries least recently used first", func(t *testing.T) {
+		repositoryCache := newTestCache(t)
+		require.Equal(t, cache.ChartEntry, listCacheKinds(t, repositoryCache)[0])
+
+		out, err := executeCacheCmd(t, "list", "--repository-cache", repositoryCache, "--kind", string(cache.ProvEntry))
+		require.NoError(t, err)
+		require.Contains(t, out, "KIND")
+		require.Contains(t, out, string(cache.ProvEntry))
+		require.NotContains(t, out, string(cache.KeyringEntry))
+	})
+
+	t.Run("Should prune entries older than max age", func(t *testing.T) {
+		repositoryCache := newTestCache(t)
+		out, err := executeCacheCmd(t, "prune", "--repository-cache", repositoryCache, "--max-age", "24h", "--max-size", "0")
+		require.NoError(t, err)
+		require.Contains(t, out, "removed charts entry")
+		require.Contains(t, out, "1 entries removed, 10 bytes freed")
+		require.ElementsMatch(t, []cache.EntryKind{cache.ProvEntry, cache.KeyringEntry}, listCacheKinds(t, repositoryCache))
+	})
+
+	t.Run("Should prune entries over max size", func(t *testing.T) {
+		repositoryCache := newTestCache(t)
+		_, err := executeCacheCmd(t, "prune", "--repository-cache", repositoryCache, "--max-age", "0", "--max-size", "25")
+		require.NoError(t, err)
+		require.Len(t, listCacheKinds(t, repositoryCache), 2)
+	})
+
+	t.Run("Should error for an invalid max size", func(t *testing.T) {
+		_, err := executeCacheCmd(t, "prune", "--repository-cache", newTestCache(t), "--max-size", "lots")
+		require.Error(t, err)
+	})
+
+	t.Run("Should clear entries of a kind", func(t *testing.T) {
+		repositoryCache := newTestCache(t)
+		out, err := executeCacheCmd(t, "clear", "--repository-cache", repositoryCache, "--kind", string(cache.KeyringEntry))
+		require.NoError(t, err)
+		require.Contains(t, out, "1 entries removed")
+		require.ElementsMatch(t, []cache.EntryKind{cache.ChartEntry, cache.ProvEntry}, listCacheKinds(t, repositoryCache))
+	})
+
+	t.Run("Should clear all entries", func(t *testing.T) {
+		repositoryCache := newTestCache(t)
+		_, err := executeCacheCmd(t, "clear", "--repository-cache", repositoryCache)
+		require.NoError(t, err)
+		require.Empty(t, listCacheKinds(t, repositoryCache))
+	})
+}
@@ -38,10 +38,21 @@ func NewReportCmd(config *viper.Viper) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			reportName := ""
 			reportFormat := apireportsummary.JSONReport
+			switch outputFormatFlag {
+			case "markdown":
+				reportFormat = apireportsummary.MarkdownReport
+			case "html":
+				reportFormat = apireportsummary.HTMLReport
+			}
 			if reportToFile {
-				if outputFormatFlag == "json" {
+				switch outputFormatFlag {
+				case "json":
 					reportName = "report-info.json"
-				} else {
+				case "markdown":
+					reportName = "report-info.md"
+				case "html":
+					reportName = "report-info.html"
+				default:
 					reportName = "report-info.yaml"
 					reportFormat = apireportsummary.YAMLReport
 				}
@@ -109,7 +120,7 @@ func NewReportCmd(config *viper.Viper) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputFormatFlag, "output", "o", "", "the output format: json (default) or yaml")
+	cmd.Flags().StringVarP(&outputFormatFlag, "output", "o", "", "the output format: json (default), yaml, or, for all, markdown or html")
 
 	cmd.Flags().StringSliceVarP(&reportOpts.Values, "set", "s", []string{}, "set report configuration values: profile vendor type and version")
 
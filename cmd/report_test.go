@@ -270,6 +270,60 @@ func TestReport(t *testing.T) {
 	}
 }
 
+func TestReportRenderers(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		contains []string
+	}{
+		{
+			name: "Should render all as markdown",
+			args: []string{"-o", "markdown", string(apireportsummary.AllSummary), "test/report.yaml"},
+			contains: []string{
+				"# Chart verifier report: chart 0.1.0-v3.valid",
+				"**:x: FAIL**: 11 passed, 1 failed, 0 skipped",
+				"### v1.0/contains-values",
+				"| Tested OpenShift version | 4.7.8 |",
+			},
+		},
+		{
+			name: "Should render all as html",
+			args: []string{"-o", "html", string(apireportsummary.AllSummary), "test/report.yaml"},
+			contains: []string{
+				"<!DOCTYPE html>",
+				"<h3>v1.0/contains-values</h3>",
+				"<tr><th>Tested OpenShift version</th><td>4.7.8</td></tr>",
+			},
+		},
+		{
+			name:    "Should error rendering results as markdown",
+			args:    []string{"-o", "markdown", string(apireportsummary.ResultsSummary), "test/report.yaml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outBuff := bytes.NewBufferString("")
+			cmd := NewReportCmd(viper.New())
+			cmd.SetOut(outBuff)
+			utils.CmdStdout = outBuff
+			cmd.SetErr(bytes.NewBufferString(""))
+			cmd.SetArgs(tc.args)
+
+			if tc.wantErr {
+				require.Error(t, cmd.Execute())
+				return
+			}
+			require.NoError(t, cmd.Execute())
+			for _, want := range tc.contains {
+				require.Contains(t, outBuff.String(), want)
+			}
+		})
+	}
+}
+
 func compareMetadata(expected *apireportsummary.MetadataReport, result *apireportsummary.MetadataReport) bool {
 	outcome := true
 	if expected.ProfileVersion != result.ProfileVersion {
@@ -60,6 +60,12 @@ var (
 	suppressErrorLog bool
 	// skip helm cleanup
 	skipCleanup bool
+	// apply releases using server-side apply
+	serverSideApply bool
+	// helm wait strategy
+	waitStrategy string
+	// provider of the throwaway cluster to run chart-testing against
+	ephemeralCluster string
 	// distribution method is web-catalog-only.
 	webCatalogOnly bool
 	// client timeout
@@ -137,17 +143,20 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 		Short: "Verifies a Helm chart by checking some of its characteristics",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			reportFormat := apireport.YamlReport
-			if outputFormatFlag == "json" {
+			reportName := "report.yaml"
+			switch outputFormatFlag {
+			case "json":
 				reportFormat = apireport.JSONReport
+				reportName = "report.json"
+			case "markdown":
+				reportFormat = apireport.MarkdownReport
+				reportName = "report.md"
+			case "html":
+				reportFormat = apireport.HTMLReport
+				reportName = "report.html"
 			}
-
-			reportName := ""
-			if reportToFile {
-				if outputFormatFlag == "json" {
-					reportName = "report.json"
-				} else {
-					reportName = "report.yaml"
-				}
+			if !reportToFile {
+				reportName = ""
 			}
 
 			enabledChecks, unEnabledChecks, checksErr := buildChecks(enabledChecksFlag, disabledChecksFlag)
@@ -184,6 +193,9 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 			verifier, runErr = verifier.SetBoolean(apiverifier.WebCatalogOnly, webCatalogOnly).
 				SetBoolean(apiverifier.SuppressErrorLog, suppressErrorLog).
 				SetBoolean(apiverifier.SkipCleanup, skipCleanup).
+				SetBoolean(apiverifier.ServerSideApply, serverSideApply).
+				SetString(apiverifier.WaitStrategy, []string{waitStrategy}).
+				SetString(apiverifier.EphemeralCluster, []string{ephemeralCluster}).
 				SetDuration(apiverifier.Timeout, clientTimeout).
 				SetDuration(apiverifier.HelmInstallTimeout, helmInstallTimeout).
 				SetString(apiverifier.OpenshiftVersion, []string{openshiftVersionFlag}).
@@ -251,7 +263,7 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&disabledChecksFlag, "disable", "x", nil, "all checks will be enabled except the informed ones")
 
-	cmd.Flags().StringVarP(&outputFormatFlag, "output", "o", "", "the output format: default, json or yaml")
+	cmd.Flags().StringVarP(&outputFormatFlag, "output", "o", "", "the output format: default, json, yaml, markdown or html")
 
 	cmd.Flags().StringSliceVarP(&verifyOpts.Values, "set", "s", []string{}, "overrides a configuration, e.g: dummy.ok=false")
 
@@ -261,6 +273,10 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 	cmd.Flags().BoolVarP(&reportToFile, "write-to-file", "w", false, "write report to ./chartverifier/report.yaml (default: stdout)")
 	cmd.Flags().BoolVarP(&suppressErrorLog, "suppress-error-log", "E", false, "suppress the error log (default: written to ./chartverifier/verifier-<timestamp>.log)")
 	cmd.Flags().BoolVarP(&skipCleanup, "skip-cleanup", "c", false, "set this to skip resource cleanup after verifier run")
+	cmd.Flags().BoolVar(&serverSideApply, "server-side-apply", false, "set this to install and upgrade the chart using server-side apply")
+	cmd.Flags().StringVar(&waitStrategy, "wait-strategy", "legacy", "strategy used by helm to wait for resources: legacy, watcher or hookOnly")
+	cmd.Flags().StringVar(&ephemeralCluster, "ephemeral-cluster", "", "run chart-testing against a throwaway local cluster started with the given provider: kind")
+	cmd.Flags().Lookup("ephemeral-cluster").NoOptDefVal = "kind"
 	cmd.Flags().BoolVarP(&webCatalogOnly, "web-catalog-only", "W", false, "set this to indicate that the distribution method is web catalog only (default: false)")
 	cmd.Flags().StringVarP(&pgpPublicKeyFile, "pgp-public-key", "k", "", "file containing gpg public key of the key used to sign the chart")
 	cmd.Flags().DurationVar(&helmInstallTimeout, "helm-install-timeout", 5*time.Minute, "helm install timeout")
@@ -19,6 +19,7 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -189,6 +190,41 @@ func TestCertify(t *testing.T) {
 				require.Equal(t, certificate.Results[0].Reason, checks.Helm3Reason)
 			},
 		},
+		{
+			name: "Should display markdown report when option --output markdown is given",
+			args: []string{
+				"-e", "is-helm-v3",
+				"-V", "4.9",
+				"-o", "markdown",
+				"../internal/chartverifier/checks/chart-0.1.0-v3.valid.tgz",
+				"-E",
+			},
+			validateErrorFunc: func(err error) {
+				require.NoError(t, err)
+			},
+			validateOutputFunc: func(output *bytes.Buffer) {
+				require.Contains(t, output.String(), "# Chart verifier report: chart 0.1.0-v3.valid\n")
+				require.Contains(t, output.String(), "**:white_check_mark: PASS**: 1 passed, 0 failed, 0 skipped")
+				require.Contains(t, output.String(), "| v1.0/is-helm-v3 | Mandatory | :white_check_mark: PASS |")
+			},
+		},
+		{
+			name: "Should display HTML report when option --output html is given",
+			args: []string{
+				"-e", "is-helm-v3",
+				"-V", "4.9",
+				"-o", "html",
+				"../internal/chartverifier/checks/chart-0.1.0-v3.valid.tgz",
+				"-E",
+			},
+			validateErrorFunc: func(err error) {
+				require.NoError(t, err)
+			},
+			validateOutputFunc: func(output *bytes.Buffer) {
+				require.True(t, strings.HasPrefix(output.String(), "<!DOCTYPE html>"))
+				require.Contains(t, output.String(), "<tr><td>v1.0/is-helm-v3</td><td>Mandatory</td>")
+			},
+		},
 		{
 			name: "Should see webCatalogOnly is true for -W flag and chart-uri is not set",
 			args: []string{
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
 	"helm.sh/helm/v4/pkg/cli"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier"
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/profiles"
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
+	"github.com/redhat-certification/chart-verifier/internal/tool"
 	apichecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	apireport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
@@ -22,6 +26,8 @@ func init() {
 type RunOptions struct {
 	APIVersion         string
 	Values             map[string]interface{}
+	ValuesFiles        []string
+	SetValues          []string
 	ViperConfig        *viper.Viper
 	Overrides          map[string]interface{}
 	ChecksToRun        []apichecks.CheckName
@@ -29,6 +35,9 @@ type RunOptions struct {
 	WebCatalogOnly     bool
 	SuppressErrorLog   bool
 	SkipCleanup        bool
+	ServerSideApply    bool
+	WaitStrategy       string
+	EphemeralCluster   string
 	ClientTimeout      time.Duration
 	HelmInstallTimeout time.Duration
 	ChartURI           string
@@ -42,6 +51,8 @@ func Run(options RunOptions) (*apireport.Report, error) {
 	verifierBuilder := chartverifier.NewVerifierBuilder()
 
 	verifierBuilder.SetValues(options.Values).
+		SetValuesFiles(options.ValuesFiles).
+		SetSetValues(options.SetValues).
 		SetConfig(options.ViperConfig).
 		SetOverrides(options.Overrides).
 		SetSettings(options.Settings)
@@ -58,12 +69,31 @@ func Run(options RunOptions) (*apireport.Report, error) {
 		}
 	}
 
+	if _, ok := checkRegistry[apichecks.ChartTesting]; ok && options.EphemeralCluster != "" {
+		if options.Settings == nil {
+			options.Settings = cli.New()
+		}
+		cluster, err := startEphemeralCluster(options.EphemeralCluster)
+		if err != nil {
+			return verifyReport, err
+		}
+		options.Settings = ephemeralClusterSettings(options.Settings, cluster.Kubeconfig())
+		defer func() {
+			if err := cluster.Stop(); err != nil {
+				utils.LogWarning(fmt.Sprintf("Error deleting %s cluster: %v", cluster.Provider(), err))
+			}
+		}()
+	}
+
 	verifier, err := verifierBuilder.
 		SetChecks(checkRegistry).
 		SetToolVersion(options.APIVersion).
 		SetOpenShiftVersion(options.OpenShiftVersion).
 		SetWebCatalogOnly(options.WebCatalogOnly).
 		SetSkipCleanup(options.SkipCleanup).
+		SetServerSideApply(options.ServerSideApply).
+		SetWaitStrategy(options.WaitStrategy).
+		SetEphemeralCluster(options.EphemeralCluster).
 		SetTimeout(options.ClientTimeout).
 		SetHelmInstallTimeout(options.HelmInstallTimeout).
 		SetSettings(options.Settings).
@@ -80,3 +110,44 @@ func Run(options RunOptions) (*apireport.Report, error) {
 
 	return verifyReport, nil
 }
+
+// startEphemeralCluster starts a cluster run by provider.
+func startEphemeralCluster(provider string) (tool.EphemeralCluster, error) {
+	cluster, err := tool.NewEphemeralCluster(provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := cluster.Start(); err != nil {
+		//nolint:errcheck // the start error is the one worth reporting
+		cluster.Stop()
+		return nil, fmt.Errorf("starting %s cluster: %w", provider, err)
+	}
+	return cluster, nil
+}
+
+// ephemeralClusterSettings returns a copy of settings pointed at the cluster
+// of kubeconfig, replacing the user's cluster connection settings. The copy
+// has its own client configuration, so settings, and the clients already
+// built from them, keep using the user's cluster.
+func ephemeralClusterSettings(settings *cli.EnvSettings, kubeconfig string) *cli.EnvSettings {
+	s := cli.New()
+	// The namespace informed by the user, if any, is only reachable through
+	// the client configuration.
+	if flags, ok := settings.RESTClientGetter().(*genericclioptions.ConfigFlags); ok && flags.Namespace != nil {
+		s.SetNamespace(*flags.Namespace)
+	}
+	s.KubeConfig = kubeconfig
+	s.KubeAsUser = settings.KubeAsUser
+	s.KubeAsGroups = settings.KubeAsGroups
+	s.Debug = settings.Debug
+	s.RegistryConfig = settings.RegistryConfig
+	s.RepositoryConfig = settings.RepositoryConfig
+	s.RepositoryCache = settings.RepositoryCache
+	s.PluginsDirectory = settings.PluginsDirectory
+	s.MaxHistory = settings.MaxHistory
+	s.BurstLimit = settings.BurstLimit
+	s.QPS = settings.QPS
+	s.ColorMode = settings.ColorMode
+	s.ContentCache = settings.ContentCache
+	return s
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestEphemeralClusterSettings(t *testing.T) {
+	settings := cli.New()
+	settings.SetNamespace("charts")
+	settings.KubeConfig = "/home/user/.kube/config"
+	settings.KubeContext = "production"
+	settings.KubeToken = "token"
+	settings.RepositoryCache = "/home/user/.cache/helm/repository"
+	getter := settings.RESTClientGetter()
+
+	ephemeral := ephemeralClusterSettings(settings, "/tmp/kind/kubeconfig")
+	require.Equal(t, "/tmp/kind/kubeconfig", ephemeral.KubeConfig)
+	require.Empty(t, ephemeral.KubeContext)
+	require.Empty(t, ephemeral.KubeToken)
+	require.Equal(t, "charts", ephemeral.Namespace())
+	require.Equal(t, settings.RepositoryCache, ephemeral.RepositoryCache)
+	require.NotSame(t, getter, ephemeral.RESTClientGetter())
+
+	// The user's settings are left untouched.
+	require.Equal(t, "/home/user/.kube/config", settings.KubeConfig)
+	require.Equal(t, "production", settings.KubeContext)
+	require.Equal(t, "token", settings.KubeToken)
+	require.Same(t, getter, settings.RESTClientGetter())
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cache manages the on-disk artifacts chart-verifier keeps between
+// runs: extracted charts, downloaded chart packages with their provenance
+// files, and the keyrings built from user supplied public keys.
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type EntryKind string
+
+const (
+	ChartEntry   EntryKind = "charts"
+	ProvEntry    EntryKind = "prov"
+	KeyringEntry EntryKind = "keyrings"
+
+	// DefaultMaxAge is how long an entry may go unused before it is evicted.
+	DefaultMaxAge = 7 * 24 * time.Hour
+	// DefaultMaxSize is the total size, in bytes, the cache is trimmed down to.
+	DefaultMaxSize int64 = 1 << 30
+
+	// MaxAgeEnvVar and MaxSizeEnvVar override the limits enforced after each
+	// chart is added to the cache.
+	MaxAgeEnvVar  = "CHART_VERIFIER_CACHE_MAX_AGE"
+	MaxSizeEnvVar = "CHART_VERIFIER_CACHE_MAX_SIZE"
+
+	cacheDirName = "chart-verifier"
+)
+
+// AllKinds lists every kind of entry stored in the cache.
+var AllKinds = []EntryKind{ChartEntry, ProvEntry, KeyringEntry}
+
+// kindDirs maps each entry kind to its directory under the cache root. The
+// keyring directory keeps its historical name.
+var kindDirs = map[EntryKind]string{
+	ChartEntry:   "charts",
+	ProvEntry:    "prov",
+	KeyringEntry: "pgp",
+}
+
+// Entry is a single top-level item of the cache, e.g. one extracted chart.
+type Entry struct {
+	Kind    EntryKind `json:"kind" yaml:"kind"`
+	Name    string    `json:"name" yaml:"name"`
+	Path    string    `json:"path" yaml:"path"`
+	Size    int64     `json:"size" yaml:"size"`
+	ModTime time.Time `json:"lastUsed" yaml:"lastUsed"`
+}
+
+// Limits bounds the cache. A zero value disables the corresponding limit.
+type Limits struct {
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// DefaultLimits returns the limits enforced automatically, taking the
+// environment overrides into account.
+func DefaultLimits() (Limits, error) {
+	limits := Limits{MaxAge: DefaultMaxAge, MaxSize: DefaultMaxSize}
+
+	if v := os.Getenv(MaxAgeEnvVar); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			return limits, fmt.Errorf("invalid %s %q: %w", MaxAgeEnvVar, v, err)
+		}
+		limits.MaxAge = maxAge
+	}
+
+	if v := os.Getenv(MaxSizeEnvVar); v != "" {
+		maxSize, err := ParseSize(v)
+		if err != nil {
+			return limits, fmt.Errorf("invalid %s %q: %w", MaxSizeEnvVar, v, err)
+		}
+		limits.MaxSize = maxSize
+	}
+
+	return limits, nil
+}
+
+// ParseSize parses sizes such as "1048576", "500Mi" or "1G" into bytes.
+func ParseSize(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	return q.Value(), nil
+}
+
+// Dir returns the cache root for the given Helm settings. Helm's repository
+// cache is preferred so that all Helm related artifacts live together,
+// falling back to the user cache directory. An empty string is returned if
+// neither can be determined.
+func Dir(settings *cli.EnvSettings) string {
+	cacheDir := ""
+	if settings != nil {
+		cacheDir = settings.RepositoryCache
+	}
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(cacheDir, cacheDirName)
+}
+
+// Cache gives access to the entries stored under a cache root.
+type Cache struct {
+	root string
+}
+
+func New(root string) *Cache {
+	return &Cache{root: root}
+}
+
+func (c *Cache) Root() string {
+	return c.root
+}
+
+// KindDir returns the directory holding the entries of the given kind.
+func (c *Cache) KindDir(kind EntryKind) string {
+	return filepath.Join(c.root, kindDirs[kind])
+}
+
+// EntryDir returns the directory of the named entry of the given kind.
+func (c *Cache) EntryDir(kind EntryKind, name string) string {
+	return filepath.Join(c.KindDir(kind), name)
+}
+
+// Touch marks the entry at path as recently used, so that it survives age
+// and size based eviction.
+func (c *Cache) Touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
+
+// List returns the entries of the requested kinds, or of all kinds if none
+// is given, oldest first.
+func (c *Cache) List(kinds ...EntryKind) ([]Entry, error) {
+	if len(kinds) == 0 {
+		kinds = AllKinds
+	}
+
+	var entries []Entry
+	for _, kind := range kinds {
+		if _, ok := kindDirs[kind]; !ok {
+			return nil, fmt.Errorf("unknown cache entry kind %q", kind)
+		}
+		dirEntries, err := os.ReadDir(c.KindDir(kind))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, dirEntry := range dirEntries {
+			info, err := dirEntry.Info()
+			if err != nil {
+				return nil, err
+			}
+			entryPath := c.EntryDir(kind, dirEntry.Name())
+			size, err := diskUsage(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry{
+				Kind:    kind,
+				Name:    dirEntry.Name(),
+				Path:    entryPath,
+				Size:    size,
+				ModTime: info.ModTime(),
+			})
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.ModTime.Compare(b.ModTime)
+	})
+
+	return entries, nil
+}
+
+// Prune removes entries not used within limits.MaxAge and then, oldest first,
+// as many entries as needed to bring the total size under limits.MaxSize.
+// Entries for which keep returns true are never removed. The removed entries
+// are returned.
+func (c *Cache) Prune(limits Limits, keep func(Entry) bool, kinds ...EntryKind) ([]Entry, error) {
+	entries, err := c.List(kinds...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		removed   []Entry
+		remaining []Entry
+		totalSize int64
+	)
+
+	for _, entry := range entries {
+		if keep != nil && keep(entry) {
+			remaining = append(remaining, entry)
+			totalSize += entry.Size
+			continue
+		}
+		if limits.MaxAge > 0 && time.Since(entry.ModTime) > limits.MaxAge {
+			if err := os.RemoveAll(entry.Path); err != nil {
+				return removed, err
+			}
+			removed = append(removed, entry)
+			continue
+		}
+		remaining = append(remaining, entry)
+		totalSize += entry.Size
+	}
+
+	if limits.MaxSize > 0 {
+		// remaining is sorted oldest first.
+		for _, entry := range remaining {
+			if totalSize <= limits.MaxSize {
+				break
+			}
+			if keep != nil && keep(entry) {
+				continue
+			}
+			if err := os.RemoveAll(entry.Path); err != nil {
+				return removed, err
+			}
+			removed = append(removed, entry)
+			totalSize -= entry.Size
+		}
+	}
+
+	return removed, nil
+}
+
+// Clear removes every entry of the requested kinds, or of all kinds if none
+// is given, and returns the removed entries.
+func (c *Cache) Clear(kinds ...EntryKind) ([]Entry, error) {
+	entries, err := c.List(kinds...)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return entries[:i], err
+		}
+	}
+	return entries, nil
+}
+
+// diskUsage returns the total size of the regular files under path.
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
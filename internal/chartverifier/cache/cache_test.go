@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func addEntry(t *testing.T, c *Cache, kind EntryKind, name string, size int, age time.Duration) {
+	dir := c.EntryDir(kind, name)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, size), 0o644))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(dir, modTime, modTime))
+}
+
+func entryNames(entries []Entry) []string {
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func TestList(t *testing.T) {
+	c := New(t.TempDir())
+	addEntry(t, c, ChartEntry, "new", 10, time.Hour)
+	addEntry(t, c, ProvEntry, "old", 20, 2*time.Hour)
+	addEntry(t, c, KeyringEntry, "keys", 30, 30*time.Minute)
+
+	entries, err := c.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"old", "new", "keys"}, entryNames(entries))
+	require.Equal(t, int64(20), entries[0].Size)
+	require.Equal(t, ProvEntry, entries[0].Kind)
+
+	entries, err = c.List(ChartEntry)
+	require.NoError(t, err)
+	require.Equal(t, []string{"new"}, entryNames(entries))
+
+	_, err = c.List("unknown")
+	require.Error(t, err)
+}
+
+func TestPrune(t *testing.T) {
+	t.Run("Removes entries older than the max age", func(t *testing.T) {
+		c := New(t.TempDir())
+		addEntry(t, c, ChartEntry, "stale", 10, 48*time.Hour)
+		addEntry(t, c, ChartEntry, "fresh", 10, time.Minute)
+
+		removed, err := c.Prune(Limits{MaxAge: 24 * time.Hour}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"stale"}, entryNames(removed))
+		require.NoDirExists(t, c.EntryDir(ChartEntry, "stale"))
+		require.DirExists(t, c.EntryDir(ChartEntry, "fresh"))
+	})
+
+	t.Run("Removes least recently used entries above the max size", func(t *testing.T) {
+		c := New(t.TempDir())
+		addEntry(t, c, ChartEntry, "oldest", 100, 3*time.Hour)
+		addEntry(t, c, ChartEntry, "older", 100, 2*time.Hour)
+		addEntry(t, c, ChartEntry, "newest", 100, time.Hour)
+
+		removed, err := c.Prune(Limits{MaxSize: 150}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"oldest", "older"}, entryNames(removed))
+		require.DirExists(t, c.EntryDir(ChartEntry, "newest"))
+	})
+
+	t.Run("Keeps entries in use", func(t *testing.T) {
+		c := New(t.TempDir())
+		addEntry(t, c, ChartEntry, "in-use", 100, 48*time.Hour)
+		addEntry(t, c, ChartEntry, "unused", 100, time.Hour)
+
+		keep := func(e Entry) bool { return e.Name == "in-use" }
+		removed, err := c.Prune(Limits{MaxAge: 24 * time.Hour, MaxSize: 150}, keep)
+		require.NoError(t, err)
+		require.Equal(t, []string{"unused"}, entryNames(removed))
+		require.DirExists(t, c.EntryDir(ChartEntry, "in-use"))
+	})
+}
+
+func TestClear(t *testing.T) {
+	c := New(t.TempDir())
+	addEntry(t, c, ChartEntry, "chart", 10, time.Hour)
+	addEntry(t, c, KeyringEntry, "keys", 10, time.Hour)
+
+	removed, err := c.Clear(KeyringEntry)
+	require.NoError(t, err)
+	require.Equal(t, []string{"keys"}, entryNames(removed))
+
+	entries, err := c.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"chart"}, entryNames(entries))
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"1048576": 1 << 20, "500Mi": 500 << 20, "1G": 1000000000} {
+		got, err := ParseSize(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got, in)
+	}
+	_, err := ParseSize("lots")
+	require.Error(t, err)
+}
@@ -0,0 +1,321 @@
+package checks
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/opdev/getocprange"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v4/pkg/chart/common"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const (
+	// OpenShiftVersionConfigString lists the OpenShift versions, e.g.
+	// openshift-4.12,openshift-4.14, the chart is checked against instead of
+	// the ones of its kubeVersion.
+	OpenShiftVersionConfigString string = "version"
+
+	defaultAPICatalogVersion = "v1.0"
+)
+
+//go:embed apis/*
+var apiCatalogContent embed.FS
+
+// KubernetesAPI is a Kubernetes API version of some kinds, the Kubernetes
+// versions introducing, deprecating and removing it, when they are part of
+// the catalog, and the API replacing it.
+type KubernetesAPI struct {
+	APIVersion  string   `yaml:"apiVersion"`
+	Kinds       []string `yaml:"kinds"`
+	Introduced  string   `yaml:"introduced"`
+	Deprecated  string   `yaml:"deprecated"`
+	Removed     string   `yaml:"removed"`
+	Replacement string   `yaml:"replacement"`
+}
+
+// servedBy returns whether the API is served by kubeVersion.
+func (a KubernetesAPI) servedBy(kubeVersion string) bool {
+	if a.Introduced != "" && compareVersions(kubeVersion, a.Introduced) < 0 {
+		return false
+	}
+	return a.Removed == "" || compareVersions(kubeVersion, a.Removed) < 0
+}
+
+// APICatalog is a versioned list of the Kubernetes versions of the OpenShift
+// releases and of the Kubernetes APIs introduced, deprecated or removed along
+// them.
+type APICatalog struct {
+	Version   string            `yaml:"version"`
+	OpenShift map[string]string `yaml:"openshift"`
+	APIs      []KubernetesAPI   `yaml:"apis"`
+}
+
+// GetAPICatalog returns the embedded API catalog of version.
+func GetAPICatalog(version string) (*APICatalog, error) {
+	data, err := apiCatalogContent.ReadFile(path.Join("apis", fmt.Sprintf("kubernetes-apis-%s.yaml", version)))
+	if err != nil {
+		return nil, fmt.Errorf("unknown API catalog version %q", version)
+	}
+	catalog := APICatalog{}
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("error parsing API catalog %s: %w", version, err)
+	}
+	return &catalog, nil
+}
+
+// OpenShiftVersions returns the OpenShift versions of the catalog, oldest
+// first.
+func (c *APICatalog) OpenShiftVersions() []string {
+	versions := make([]string, 0, len(c.OpenShift))
+	for version := range c.OpenShift {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// release returns the Kubernetes version along with the OpenShift version
+// shipping it, if any, e.g. Kubernetes 1.25 (OpenShift 4.12).
+func (c *APICatalog) release(kubeVersion string) string {
+	for openShiftVersion, k := range c.OpenShift {
+		if k == kubeVersion {
+			return fmt.Sprintf("Kubernetes %s (OpenShift %s)", kubeVersion, openShiftVersion)
+		}
+	}
+	return fmt.Sprintf("Kubernetes %s", kubeVersion)
+}
+
+// capabilities returns the capabilities of a cluster of OpenShift version to
+// render charts for: its Kubernetes version, and the default API versions of
+// helm along with the ones of the catalog it serves, less the ones of the
+// catalog it doesn't serve. A group version listed in the catalog is served
+// when one of its entries is.
+func (c *APICatalog) capabilities(version string) (*common.Capabilities, error) {
+	kubeVersion, err := common.ParseKubeVersion(c.OpenShift[version])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the kubernetes version of OpenShift %s: %w", version, err)
+	}
+
+	served := map[string]bool{}
+	for _, apiVersion := range common.DefaultCapabilities.APIVersions {
+		served[apiVersion] = true
+	}
+	catalogGroupVersions := map[string]bool{}
+	for _, api := range c.APIs {
+		catalogGroupVersions[api.APIVersion] = catalogGroupVersions[api.APIVersion] || api.servedBy(c.OpenShift[version])
+		for _, kind := range api.Kinds {
+			served[path.Join(api.APIVersion, kind)] = api.servedBy(c.OpenShift[version])
+		}
+	}
+	for apiVersion, isServed := range catalogGroupVersions {
+		served[apiVersion] = isServed
+	}
+
+	caps := common.DefaultCapabilities.Copy()
+	caps.KubeVersion = *kubeVersion
+	caps.APIVersions = nil
+	for apiVersion, isServed := range served {
+		if isServed {
+			caps.APIVersions = append(caps.APIVersions, apiVersion)
+		}
+	}
+	sort.Strings(caps.APIVersions)
+	return caps, nil
+}
+
+// APIVersionsSupported renders the chart for each OpenShift version of its
+// kubeVersion and checks that the API versions of its objects are served by
+// that version, reporting the ones not introduced yet or removed as failures
+// and the deprecated ones as warnings.
+func APIVersionsSupported(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	catalog, err := GetAPICatalog(defaultAPICatalogVersion)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", APIVersionsNotEvaluated, err)), nil
+	}
+	versions, err := testedOpenShiftVersions(opts, c, catalog)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", APIVersionsNotEvaluated, err)), nil
+	}
+
+	var findings []apiVersionFinding
+	for _, version := range versions {
+		caps, err := catalog.capabilities(version)
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s: %v", APIVersionsNotEvaluated, err)), nil
+		}
+		objects, err := renderValuesSets(opts, c, func(values map[string]interface{}) (string, error) {
+			return renderManifestsWithCapabilities(opts.URI, values, caps)
+		})
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s: OpenShift %s: %v", APIVersionsNotEvaluated, version, err)), nil
+		}
+		findings = append(findings, apiVersionFindings(objects, catalog, version)...)
+	}
+
+	tested := fmt.Sprintf("OpenShift %s", versionsLabel(catalog.OpenShiftVersions(), versions))
+	unsupported, deprecated := splitAPIVersionFindings(findings)
+	r := NewResult(true, fmt.Sprintf("%s (%s)", APIVersionsAreSupported, tested))
+	if len(unsupported) > 0 {
+		r.SetResult(false, fmt.Sprintf("%s (%s)", APIVersionsNotServed, tested))
+		for _, finding := range unsupported {
+			r.AddResult(false, finding)
+		}
+	}
+	for _, finding := range deprecated {
+		r.AddResult(true, fmt.Sprintf("%s: %s", APIVersionsDeprecated, finding))
+	}
+	return r, nil
+}
+
+// testedOpenShiftVersions returns the OpenShift versions set in the check
+// config, or else the ones of the catalog matching the kubeVersion of c, or
+// else all of them, oldest first.
+func testedOpenShiftVersions(opts *CheckOptions, c *chartv2.Chart, catalog *APICatalog) ([]string, error) {
+	catalogVersions := catalog.OpenShiftVersions()
+
+	if opts.ViperConfig.IsSet(OpenShiftVersionConfigString) {
+		var versions []string
+		for _, value := range opts.ViperConfig.GetStringSlice(OpenShiftVersionConfigString) {
+			for _, version := range strings.Split(value, ",") {
+				version = strings.TrimPrefix(strings.TrimSpace(version), "openshift-")
+				if !slices.Contains(catalogVersions, version) {
+					return nil, fmt.Errorf("OpenShift version %q is not in the API catalog %s", version, catalog.Version)
+				}
+				if !slices.Contains(versions, version) {
+					versions = append(versions, version)
+				}
+			}
+		}
+		sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) < 0 })
+		return versions, nil
+	}
+
+	if c.Metadata.KubeVersion == "" {
+		return catalogVersions, nil
+	}
+	ocpRange, err := getocprange.GetOCPRange(c.Metadata.KubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	constraint, err := semver.NewConstraint(ocpRange)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing OpenShift range %q: %w", ocpRange, err)
+	}
+	var versions []string
+	for _, version := range catalogVersions {
+		if v, err := semver.NewVersion(version); err == nil && constraint.Check(v) {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no OpenShift version of the API catalog %s matches %s", catalog.Version, ocpRange)
+	}
+	return versions, nil
+}
+
+// apiVersionFinding is an object using an API version that an OpenShift
+// version doesn't serve, or deprecates.
+type apiVersionFinding struct {
+	// subject is the object and the API version it uses.
+	subject string
+	// detail tells when the API version is introduced, deprecated or
+	// removed.
+	detail     string
+	deprecated bool
+}
+
+func (f apiVersionFinding) String() string {
+	return fmt.Sprintf("%s, %s", f.subject, f.detail)
+}
+
+// apiVersionFindings returns the objects of objects, as rendered for
+// OpenShift version, using an API version it doesn't serve yet, or anymore,
+// or deprecates.
+func apiVersionFindings(objects []renderedObject, catalog *APICatalog, version string) []apiVersionFinding {
+	kubeVersion := catalog.OpenShift[version]
+
+	var findings []apiVersionFinding
+	for _, obj := range objects {
+		for _, api := range catalog.APIs {
+			if api.APIVersion != obj.GetAPIVersion() || !slices.Contains(api.Kinds, obj.GetKind()) {
+				continue
+			}
+			finding := apiVersionFinding{subject: fmt.Sprintf("%s in %s uses %s", obj, obj.Location(), api.APIVersion)}
+			replacement := fmt.Sprintf("use %s instead", api.Replacement)
+			switch {
+			case api.Introduced != "" && compareVersions(kubeVersion, api.Introduced) < 0:
+				finding.detail = fmt.Sprintf("introduced in %s", catalog.release(api.Introduced))
+			case api.Removed != "" && compareVersions(kubeVersion, api.Removed) >= 0:
+				finding.detail = fmt.Sprintf("removed in %s, %s", catalog.release(api.Removed), replacement)
+			case api.Deprecated != "" && compareVersions(kubeVersion, api.Deprecated) >= 0:
+				finding.detail = fmt.Sprintf("deprecated in %s and removed in %s, %s", catalog.release(api.Deprecated), catalog.release(api.Removed), replacement)
+				finding.deprecated = true
+			default:
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// splitAPIVersionFindings returns the descriptions of the findings of API
+// versions not served by one of the OpenShift versions, and of the ones of
+// API versions deprecated in one of them, unless the same object and API
+// version is not served by another one, each once and sorted.
+func splitAPIVersionFindings(findings []apiVersionFinding) (unsupported, deprecated []string) {
+	notServed := map[string]bool{}
+	for _, finding := range findings {
+		if !finding.deprecated {
+			notServed[finding.subject] = true
+			unsupported = append(unsupported, finding.String())
+		}
+	}
+	for _, finding := range findings {
+		if finding.deprecated && !notServed[finding.subject] {
+			deprecated = append(deprecated, finding.String())
+		}
+	}
+	sort.Strings(unsupported)
+	sort.Strings(deprecated)
+	return slices.Compact(unsupported), slices.Compact(deprecated)
+}
+
+// versionsLabel returns the range of versions, when they are consecutive
+// versions of catalogVersions, or else the list of versions.
+func versionsLabel(catalogVersions, versions []string) string {
+	if len(versions) > 1 {
+		first := slices.Index(catalogVersions, versions[0])
+		if first >= 0 && first+len(versions) <= len(catalogVersions) && slices.Equal(catalogVersions[first:first+len(versions)], versions) {
+			return fmt.Sprintf("%s - %s", versions[0], versions[len(versions)-1])
+		}
+	}
+	return strings.Join(versions, ", ")
+}
+
+// compareVersions compares two major.minor versions, returning -1, 0 or 1.
+// Versions that don't parse are considered older than the ones that do.
+func compareVersions(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.Compare(vb)
+}
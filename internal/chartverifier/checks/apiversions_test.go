@@ -0,0 +1,205 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestGetAPICatalog(t *testing.T) {
+	catalog, err := GetAPICatalog(defaultAPICatalogVersion)
+	require.NoError(t, err)
+	require.Equal(t, defaultAPICatalogVersion, catalog.Version)
+
+	versions := catalog.OpenShiftVersions()
+	require.Equal(t, "4.1", versions[0])
+	require.Equal(t, []string{"4.9", "4.10", "4.11"}, versions[8:11])
+	for _, api := range catalog.APIs {
+		if api.Removed != "" {
+			require.Less(t, compareVersions(api.Deprecated, api.Removed), 0, api.APIVersion)
+			require.NotEmpty(t, api.Replacement, api.APIVersion)
+		}
+		if api.Introduced != "" && api.Deprecated != "" {
+			require.Less(t, compareVersions(api.Introduced, api.Deprecated), 0, api.APIVersion)
+		}
+	}
+
+	_, err = GetAPICatalog("v0.1")
+	require.Error(t, err)
+}
+
+func TestAPIVersionFindings(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/cronjob.yaml
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+# Source: chart/templates/ingress.yaml
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: app
+---
+# Source: chart/templates/hpa.yaml
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: app
+---
+# Source: chart/templates/widget.yaml
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  name: widget
+`
+
+	objects, err := decodeRenderedManifests(manifests)
+	require.NoError(t, err)
+	catalog, err := GetAPICatalog(defaultAPICatalogVersion)
+	require.NoError(t, err)
+
+	findings := func(versions ...string) ([]string, []string) {
+		var findings []apiVersionFinding
+		for _, version := range versions {
+			findings = append(findings, apiVersionFindings(objects, catalog, version)...)
+		}
+		return splitAPIVersionFindings(findings)
+	}
+
+	unsupported, deprecated := findings("4.6", "4.7", "4.8")
+	require.Equal(t, []string{
+		`HorizontalPodAutoscaler "app" in chart/templates/hpa.yaml uses autoscaling/v2, introduced in Kubernetes 1.23 (OpenShift 4.10)`,
+	}, unsupported)
+	require.Equal(t, []string{
+		`CronJob "backup" in chart/templates/cronjob.yaml uses batch/v1beta1, deprecated in Kubernetes 1.21 (OpenShift 4.8) and removed in Kubernetes 1.25 (OpenShift 4.12), use batch/v1 instead`,
+		`Ingress "app" in chart/templates/ingress.yaml uses extensions/v1beta1, deprecated in Kubernetes 1.14 (OpenShift 4.2) and removed in Kubernetes 1.22 (OpenShift 4.9), use networking.k8s.io/v1 instead`,
+	}, deprecated)
+
+	unsupported, deprecated = findings("4.11", "4.12")
+	require.Empty(t, deprecated)
+	require.Equal(t, []string{
+		`CronJob "backup" in chart/templates/cronjob.yaml uses batch/v1beta1, removed in Kubernetes 1.25 (OpenShift 4.12), use batch/v1 instead`,
+		`Ingress "app" in chart/templates/ingress.yaml uses extensions/v1beta1, removed in Kubernetes 1.22 (OpenShift 4.9), use networking.k8s.io/v1 instead`,
+	}, unsupported)
+
+	unsupported, deprecated = findings("4.1")
+	require.Equal(t, []string{
+		`HorizontalPodAutoscaler "app" in chart/templates/hpa.yaml uses autoscaling/v2, introduced in Kubernetes 1.23 (OpenShift 4.10)`,
+	}, unsupported)
+	require.Empty(t, deprecated)
+}
+
+func TestAPICatalogCapabilities(t *testing.T) {
+	catalog, err := GetAPICatalog(defaultAPICatalogVersion)
+	require.NoError(t, err)
+
+	caps, err := catalog.capabilities("4.7")
+	require.NoError(t, err)
+	require.Equal(t, "v1.20", caps.KubeVersion.Version)
+	for _, apiVersion := range []string{"v1", "apps/v1", "policy/v1beta1", "policy/v1beta1/PodDisruptionBudget", "storage.k8s.io/v1", "batch/v1beta1/CronJob"} {
+		require.True(t, caps.APIVersions.Has(apiVersion), apiVersion)
+	}
+	for _, apiVersion := range []string{"policy/v1", "policy/v1/PodDisruptionBudget", "storage.k8s.io/v1/CSIStorageCapacity", "batch/v1/CronJob", "autoscaling/v2"} {
+		require.False(t, caps.APIVersions.Has(apiVersion), apiVersion)
+	}
+
+	caps, err = catalog.capabilities("4.12")
+	require.NoError(t, err)
+	require.True(t, caps.APIVersions.Has("policy/v1/PodDisruptionBudget"))
+	require.False(t, caps.APIVersions.Has("policy/v1beta1"))
+	require.False(t, caps.APIVersions.Has("batch/v1beta1/CronJob"))
+}
+
+func TestVersionsLabel(t *testing.T) {
+	catalogVersions := []string{"4.8", "4.9", "4.10", "4.11"}
+	require.Equal(t, "4.9", versionsLabel(catalogVersions, []string{"4.9"}))
+	require.Equal(t, "4.9 - 4.11", versionsLabel(catalogVersions, []string{"4.9", "4.10", "4.11"}))
+	require.Equal(t, "4.8, 4.10", versionsLabel(catalogVersions, []string{"4.8", "4.10"}))
+}
+
+func TestAPIVersionsSupported(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	pdbChart := func(t *testing.T, apiVersion string) string {
+		return writeTestChart(t, "pdb", map[string]string{
+			"Chart.yaml":         "apiVersion: v2\nname: pdb\nversion: 0.1.0\n",
+			"templates/pdb.yaml": "apiVersion: " + apiVersion + "\nkind: PodDisruptionBudget\nmetadata:\n  name: pdb\nspec:\n  maxUnavailable: 1\n",
+		})
+	}
+	pdbVersions := map[string]interface{}{OpenShiftVersionConfigString: "openshift-4.7,openshift-4.12"}
+
+	autoscalingValues := map[string]interface{}{"autoscaling": map[string]interface{}{"enabled": true}}
+	hpa := `HorizontalPodAutoscaler "test-release-chart" in chart/templates/hpa.yaml uses autoscaling/v2beta1`
+
+	testCases := []testCase{
+		{
+			description: "chart uses supported API versions",
+			ok:          true,
+			reason:      APIVersionsAreSupported + " (OpenShift 4.7 - 4.20)",
+		},
+		{
+			description: "chart uses an API version removed from a version of its kubeVersion",
+			values:      autoscalingValues,
+			ok:          false,
+			reason: APIVersionsNotServed + " (OpenShift 4.7 - 4.20)\n" +
+				hpa + ", removed in Kubernetes 1.25 (OpenShift 4.12), use autoscaling/v2 instead",
+		},
+		{
+			description: "chart uses an API version deprecated in the configured versions",
+			values:      autoscalingValues,
+			config:      map[string]interface{}{OpenShiftVersionConfigString: "openshift-4.11,openshift-4.10"},
+			ok:          true,
+			reason: APIVersionsAreSupported + " (OpenShift 4.10 - 4.11)\n" + APIVersionsDeprecated + ": " +
+				hpa + ", deprecated in Kubernetes 1.22 (OpenShift 4.9) and removed in Kubernetes 1.25 (OpenShift 4.12), use autoscaling/v2 instead",
+		},
+		{
+			description: "chart uses an API version introduced after a configured version",
+			uri:         pdbChart(t, "policy/v1"),
+			config:      pdbVersions,
+			ok:          false,
+			reason: APIVersionsNotServed + " (OpenShift 4.7, 4.12)\n" +
+				`PodDisruptionBudget "pdb" in pdb/templates/pdb.yaml uses policy/v1, introduced in Kubernetes 1.21 (OpenShift 4.8)`,
+		},
+		{
+			description: "chart selects the API version served by each configured version",
+			uri:         pdbChart(t, `{{ if .Capabilities.APIVersions.Has "policy/v1/PodDisruptionBudget" }}policy/v1{{ else }}policy/v1beta1{{ end }}`),
+			config:      pdbVersions,
+			ok:          true,
+			reason:      APIVersionsAreSupported + " (OpenShift 4.7, 4.12)",
+		},
+		{
+			description: "configured version is not in the catalog",
+			config:      map[string]interface{}{OpenShiftVersionConfigString: "openshift-3.11"},
+			ok:          false,
+			reason:      APIVersionsNotEvaluated + `: OpenShift version "3.11" is not in the API catalog v1.0`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			uri := tc.uri
+			if uri == "" {
+				uri = "chart-0.1.0-v3.valid.tgz"
+			}
+			r, err := APIVersionsSupported(&CheckOptions{URI: uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package checks
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// RequestsConfigString lists the resources containers must request, cpu
+	// and memory by default.
+	RequestsConfigString string = "requests"
+	// LimitsConfigString lists the resources containers must limit, cpu and
+	// memory by default.
+	LimitsConfigString string = "limits"
+	// MaxLimitsConfigString maps resources to the highest limit containers
+	// may set, e.g. memory: 8Gi.
+	MaxLimitsConfigString string = "maxLimits"
+	// ProbesConfigString lists the probes long running containers must have,
+	// liveness and readiness by default.
+	ProbesConfigString string = "probes"
+	// LatestTagConfigString reports the containers using the latest tag when
+	// true, the default.
+	LatestTagConfigString string = "latestTag"
+	// ImagePullPolicyConfigString reports the containers using a mutable tag
+	// without the Always pull policy when true, the default.
+	ImagePullPolicyConfigString string = "imagePullPolicy"
+)
+
+var (
+	defaultRequiredResources = []string{"cpu", "memory"}
+	defaultRequiredProbes    = []string{"liveness", "readiness"}
+)
+
+// bestPracticesRules are the rules containers are checked against.
+type bestPracticesRules struct {
+	Requests        []string
+	Limits          []string
+	MaxLimits       map[string]resource.Quantity
+	Probes          []string
+	LatestTag       bool
+	ImagePullPolicy bool
+}
+
+// ContainersFollowBestPractices renders the chart and checks that its
+// containers request and limit resources, have probes, don't use the latest
+// tag, and pull mutable tags on every start, as configured.
+func ContainersFollowBestPractices(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	rules, err := getBestPracticesRules(opts)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", BestPracticesNotEvaluated, err)), nil
+	}
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", BestPracticesNotEvaluated, err)), nil
+	}
+
+	r := NewResult(true, BestPracticesFollowed)
+	for _, obj := range objects {
+		spec := podSpec(obj.Unstructured)
+		if spec == nil {
+			continue
+		}
+		// Jobs and pods, such as the ones of chart tests, run to completion
+		// and don't need probes.
+		longRunning := !slices.Contains([]string{"Pod", "Job", "CronJob"}, obj.GetKind())
+		for _, container := range nestedMaps(spec, "initContainers") {
+			if findings := containerFindings(container, rules, false); len(findings) > 0 {
+				addReason(&r, BestPracticesNotFollowed, containerFindingsDescription(obj, container, findings))
+			}
+		}
+		for _, container := range nestedMaps(spec, "containers") {
+			if findings := containerFindings(container, rules, longRunning); len(findings) > 0 {
+				addReason(&r, BestPracticesNotFollowed, containerFindingsDescription(obj, container, findings))
+			}
+		}
+	}
+	return r, nil
+}
+
+// getBestPracticesRules returns the rules set in the check config, or the
+// default ones.
+func getBestPracticesRules(opts *CheckOptions) (bestPracticesRules, error) {
+	config := opts.ViperConfig
+	rules := bestPracticesRules{
+		Requests:        defaultRequiredResources,
+		Limits:          defaultRequiredResources,
+		Probes:          defaultRequiredProbes,
+		LatestTag:       true,
+		ImagePullPolicy: true,
+	}
+	if config.IsSet(RequestsConfigString) {
+		rules.Requests = config.GetStringSlice(RequestsConfigString)
+	}
+	if config.IsSet(LimitsConfigString) {
+		rules.Limits = config.GetStringSlice(LimitsConfigString)
+	}
+	if config.IsSet(ProbesConfigString) {
+		rules.Probes = config.GetStringSlice(ProbesConfigString)
+		for _, probe := range rules.Probes {
+			if !slices.Contains([]string{"liveness", "readiness", "startup"}, probe) {
+				return rules, fmt.Errorf("unknown probe %q", probe)
+			}
+		}
+	}
+	if config.IsSet(LatestTagConfigString) {
+		rules.LatestTag = config.GetBool(LatestTagConfigString)
+	}
+	if config.IsSet(ImagePullPolicyConfigString) {
+		rules.ImagePullPolicy = config.GetBool(ImagePullPolicyConfigString)
+	}
+	for name, value := range config.GetStringMapString(MaxLimitsConfigString) {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return rules, fmt.Errorf("error parsing the maximum %s limit %q: %w", name, value, err)
+		}
+		if rules.MaxLimits == nil {
+			rules.MaxLimits = map[string]resource.Quantity{}
+		}
+		rules.MaxLimits[name] = quantity
+	}
+	return rules, nil
+}
+
+// containerFindings returns the rules container doesn't follow. Probes are
+// only required from the long running containers.
+func containerFindings(container map[string]interface{}, rules bestPracticesRules, longRunning bool) []string {
+	var findings []string
+	for _, name := range rules.Requests {
+		if _, found, _ := unstructured.NestedFieldNoCopy(container, "resources", "requests", name); !found {
+			findings = append(findings, fmt.Sprintf("no %s request", name))
+		}
+	}
+	for _, name := range rules.Limits {
+		if _, found, _ := unstructured.NestedFieldNoCopy(container, "resources", "limits", name); !found {
+			findings = append(findings, fmt.Sprintf("no %s limit", name))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(rules.MaxLimits)) {
+		limit, found, _ := unstructured.NestedFieldNoCopy(container, "resources", "limits", name)
+		if !found {
+			continue
+		}
+		if number, ok := limit.(float64); ok {
+			limit = strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		quantity, err := resource.ParseQuantity(fmt.Sprint(limit))
+		if err != nil {
+			findings = append(findings, fmt.Sprintf("invalid %s limit %v", name, limit))
+			continue
+		}
+		if maxLimit := rules.MaxLimits[name]; quantity.Cmp(maxLimit) > 0 {
+			findings = append(findings, fmt.Sprintf("%s limit %s above %s", name, quantity.String(), maxLimit.String()))
+		}
+	}
+	if longRunning {
+		for _, probe := range rules.Probes {
+			if _, found := container[probe+"Probe"]; !found {
+				findings = append(findings, fmt.Sprintf("no %s probe", probe))
+			}
+		}
+	}
+
+	image, _, _ := unstructured.NestedString(container, "image")
+	tag, digest := imageTag(image)
+	if rules.LatestTag && tag == "latest" && !digest {
+		findings = append(findings, "latest tag")
+	}
+	if rules.ImagePullPolicy && !digest && (tag == "" || tag == "latest") {
+		if policy, _, _ := unstructured.NestedString(container, "imagePullPolicy"); policy != "" && policy != "Always" {
+			findings = append(findings, fmt.Sprintf("imagePullPolicy %s with a mutable tag", policy))
+		}
+	}
+	return findings
+}
+
+// containerFindingsDescription returns a description of the findings of a
+// container of obj.
+func containerFindingsDescription(obj renderedObject, container map[string]interface{}, findings []string) string {
+	name, _, _ := unstructured.NestedString(container, "name")
+	description := obj.String()
+	if location := obj.Location(); location != "" {
+		description += fmt.Sprintf(" in %s", location)
+	}
+	return fmt.Sprintf("%s: container %q: %s", description, name, strings.Join(findings, ", "))
+}
+
+// imageTag returns the tag of image, empty when it has none, and whether
+// image is pinned to a digest.
+func imageTag(image string) (string, bool) {
+	name, _, digest := strings.Cut(image, "@")
+	lastComponent := name[strings.LastIndex(name, "/")+1:]
+	_, tag, _ := strings.Cut(lastComponent, ":")
+	return tag, digest
+}
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestImageTag(t *testing.T) {
+	for image, expected := range map[string]struct {
+		tag    string
+		digest bool
+	}{
+		"nginx":                                {},
+		"nginx:latest":                         {tag: "latest"},
+		"registry.example.com:5000/nginx":      {},
+		"registry.example.com:5000/nginx:1.25": {tag: "1.25"},
+		"quay.io/org/app@sha256:0123":          {digest: true},
+		"quay.io/org/app:latest@sha256:0123":   {tag: "latest", digest: true},
+	} {
+		tag, digest := imageTag(image)
+		require.Equal(t, expected.tag, tag, image)
+		require.Equal(t, expected.digest, digest, image)
+	}
+}
+
+func TestContainerFindings(t *testing.T) {
+	rules := bestPracticesRules{
+		Requests:        defaultRequiredResources,
+		Limits:          []string{"memory"},
+		MaxLimits:       map[string]resource.Quantity{"memory": resource.MustParse("1Gi"), "cpu": resource.MustParse("2")},
+		Probes:          defaultRequiredProbes,
+		LatestTag:       true,
+		ImagePullPolicy: true,
+	}
+
+	container := map[string]interface{}{
+		"name":            "app",
+		"image":           "quay.io/org/app:latest",
+		"imagePullPolicy": "IfNotPresent",
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "100m"},
+			"limits":   map[string]interface{}{"memory": "2Gi", "cpu": float64(1)},
+		},
+		"readinessProbe": map[string]interface{}{},
+	}
+	require.Equal(t, []string{
+		"no memory request",
+		"memory limit 2Gi above 1Gi",
+		"no liveness probe",
+		"latest tag",
+		"imagePullPolicy IfNotPresent with a mutable tag",
+	}, containerFindings(container, rules, true))
+
+	require.Equal(t, []string{
+		"no memory request",
+		"memory limit 2Gi above 1Gi",
+		"latest tag",
+		"imagePullPolicy IfNotPresent with a mutable tag",
+	}, containerFindings(container, rules, false))
+
+	container["image"] = "quay.io/org/app@sha256:0123"
+	container["resources"] = map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+		"limits":   map[string]interface{}{"memory": "512Mi", "cpu": float64(4)},
+	}
+	container["livenessProbe"] = map[string]interface{}{}
+	require.Equal(t, []string{"cpu limit 4 above 2"}, containerFindings(container, rules, true))
+}
+
+func TestContainersFollowBestPractices(t *testing.T) {
+	type testCase struct {
+		description string
+		values      map[string]interface{}
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	deployment := `Deployment "test-release-chart" in chart/templates/deployment.yaml: container "chart": `
+	testPod := `Pod "test-release-chart-test-connection" in chart/templates/tests/test-connection.yaml: container `
+	var testPodFindings []string
+	for _, container := range []string{`"wget"`, `"web"`, `"driver"`, `"plugin"`} {
+		testPodFindings = append(testPodFindings, testPod+container+": no cpu request, no memory request, no cpu limit, no memory limit")
+	}
+	noResourcesConfig := map[string]interface{}{RequestsConfigString: []string{}, LimitsConfigString: []string{}}
+	latestValues := map[string]interface{}{"image": map[string]interface{}{"tag": "latest"}}
+
+	testCases := []testCase{
+		{
+			description: "containers without resources",
+			ok:          false,
+			reason: BestPracticesNotFollowed + "\n" + deployment + "no cpu request, no memory request, no cpu limit, no memory limit\n" +
+				strings.Join(testPodFindings, "\n"),
+		},
+		{
+			description: "containers follow the configured rules",
+			config:      noResourcesConfig,
+			ok:          true,
+			reason:      BestPracticesFollowed,
+		},
+		{
+			description: "container uses the latest tag",
+			values:      latestValues,
+			config:      noResourcesConfig,
+			ok:          false,
+			reason:      BestPracticesNotFollowed + "\n" + deployment + "latest tag, imagePullPolicy IfNotPresent with a mutable tag",
+		},
+		{
+			description: "container limit above the configured maximum",
+			values: map[string]interface{}{"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+				"limits":   map[string]interface{}{"cpu": "1", "memory": "16Gi"},
+			}},
+			config: map[string]interface{}{
+				RequestsConfigString:  []string{"memory"},
+				LimitsConfigString:    []string{},
+				MaxLimitsConfigString: map[string]interface{}{"memory": "8Gi"},
+				ProbesConfigString:    []string{"liveness", "readiness", "startup"},
+			},
+			ok: false,
+			reason: BestPracticesNotFollowed + "\n" + deployment + "memory limit 16Gi above 8Gi, no startup probe\n" +
+				testPod + `"wget": no memory request` + "\n" + testPod + `"web": no memory request` + "\n" +
+				testPod + `"driver": no memory request` + "\n" + testPod + `"plugin": no memory request`,
+		},
+		{
+			description: "unknown probe",
+			config:      map[string]interface{}{ProbesConfigString: []string{"health"}},
+			ok:          false,
+			reason:      BestPracticesNotEvaluated + `: unknown probe "health"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			r, err := ContainersFollowBestPractices(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
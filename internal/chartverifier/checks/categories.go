@@ -0,0 +1,162 @@
+package checks
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// CategoriesVersionConfigString selects the version of the embedded
+	// list of OpenShift catalog categories.
+	CategoriesVersionConfigString string = "version"
+	// CategoriesConfigString replaces the embedded list of OpenShift
+	// catalog categories.
+	CategoriesConfigString string = "categories"
+
+	defaultCategoriesVersion = "v1.0"
+)
+
+//go:embed categories/*
+var categoriesContent embed.FS
+
+// Category is an OpenShift catalog category and the chart keywords the
+// catalog files under it.
+type Category struct {
+	Name     string   `yaml:"name" mapstructure:"name"`
+	Keywords []string `yaml:"keywords" mapstructure:"keywords"`
+}
+
+// Categories is a versioned list of OpenShift catalog categories.
+type Categories struct {
+	Version    string     `yaml:"version"`
+	Categories []Category `yaml:"categories"`
+}
+
+// GetCategories returns the embedded list of OpenShift catalog categories of
+// version.
+func GetCategories(version string) (*Categories, error) {
+	data, err := categoriesContent.ReadFile(path.Join("categories", fmt.Sprintf("openshift-categories-%s.yaml", version)))
+	if err != nil {
+		return nil, fmt.Errorf("unknown OpenShift categories version %q", version)
+	}
+	categories := Categories{}
+	if err := yaml.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("error parsing OpenShift categories %s: %w", version, err)
+	}
+	return &categories, nil
+}
+
+// KeywordsAreOpenshiftCategories checks that at least one keyword of the chart
+// files it under an OpenShift catalog category, so that it isn't listed under
+// "Other", and reports the keywords that are no category along with the
+// closest category keyword.
+func KeywordsAreOpenshiftCategories(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	categories, err := getConfiguredCategories(opts)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", KeywordsCategoriesFailure, err)), nil
+	}
+
+	categoryOfKeyword := map[string]string{}
+	for _, category := range categories.Categories {
+		for _, keyword := range category.Keywords {
+			categoryOfKeyword[strings.ToLower(keyword)] = category.Name
+		}
+	}
+
+	var matched, unknown []string
+	for _, keyword := range c.Metadata.Keywords {
+		if category, ok := categoryOfKeyword[strings.ToLower(keyword)]; ok {
+			if !slices.Contains(matched, category) {
+				matched = append(matched, category)
+			}
+			continue
+		}
+		if suggestion := closestKeyword(keyword, categoryOfKeyword); suggestion != "" {
+			unknown = append(unknown, fmt.Sprintf("%s (did you mean %s?)", keyword, suggestion))
+		} else {
+			unknown = append(unknown, keyword)
+		}
+	}
+
+	var r Result
+	if len(matched) == 0 {
+		r = NewResult(false, fmt.Sprintf("%s (%s)", KeywordsAreNotOpenShiftCategories, categories.Version))
+	} else {
+		r = NewResult(true, fmt.Sprintf("%s (%s): %s", KeywordsAreOpenShiftCategories, categories.Version, strings.Join(matched, ", ")))
+	}
+	if len(unknown) > 0 {
+		r.AddResult(true, fmt.Sprintf("%s: %s", KeywordsAreNotCategories, strings.Join(unknown, ", ")))
+	}
+	return r, nil
+}
+
+// getConfiguredCategories returns the categories set in the check config, or
+// the embedded categories of the configured version.
+func getConfiguredCategories(opts *CheckOptions) (*Categories, error) {
+	if opts.ViperConfig.IsSet(CategoriesConfigString) {
+		categories := Categories{Version: "configured"}
+		if err := opts.ViperConfig.UnmarshalKey(CategoriesConfigString, &categories.Categories); err != nil {
+			return nil, fmt.Errorf("error parsing configured categories: %w", err)
+		}
+		return &categories, nil
+	}
+	version := defaultCategoriesVersion
+	if configVersion := opts.ViperConfig.GetString(CategoriesVersionConfigString); configVersion != "" {
+		version = configVersion
+	}
+	return GetCategories(version)
+}
+
+// closestKeyword returns the category keyword closest to keyword, when
+// it is likely a misspelling of it.
+func closestKeyword(keyword string, categoryOfKeyword map[string]string) string {
+	keyword = strings.ToLower(keyword)
+	candidates := make([]string, 0, len(categoryOfKeyword))
+	for candidate := range categoryOfKeyword {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+
+	// Allow about one edit every three characters, none for keywords
+	// shorter than four characters, which are likely abbreviations.
+	closest := ""
+	closestDistance := (len(keyword) + 2) / 3
+	for _, candidate := range candidates {
+		if distance := levenshteinDistance(keyword, candidate); distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+	return closest
+}
+
+func levenshteinDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,89 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestGetCategories(t *testing.T) {
+	categories, err := GetCategories(defaultCategoriesVersion)
+	require.NoError(t, err)
+	require.Equal(t, defaultCategoriesVersion, categories.Version)
+	require.NotEmpty(t, categories.Categories)
+	for _, category := range categories.Categories {
+		require.NotEmpty(t, category.Name)
+		require.NotEmpty(t, category.Keywords, category.Name)
+	}
+
+	_, err = GetCategories("v0.1")
+	require.ErrorContains(t, err, `unknown OpenShift categories version "v0.1"`)
+}
+
+func TestKeywordsAreOpenshiftCategories(t *testing.T) {
+	chartWithKeywords := func(t *testing.T, keywords ...string) string {
+		chartYaml := fmt.Sprintf("apiVersion: v2\nname: keywords\nversion: 0.1.0\nkeywords: [%s]\n", strings.Join(keywords, ", "))
+		return writeTestChart(t, "keywords", map[string]string{"Chart.yaml": chartYaml})
+	}
+
+	type testCase struct {
+		description string
+		keywords    []string
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "keywords match categories",
+			keywords:    []string{"PostgreSQL", "database", "psql"},
+			ok:          true,
+			reason:      KeywordsAreOpenShiftCategories + " (v1.0): Databases\n" + KeywordsAreNotCategories + ": psql",
+		},
+		{
+			description: "no keywords",
+			ok:          false,
+			reason:      KeywordsAreNotOpenShiftCategories + " (v1.0)",
+		},
+		{
+			description: "misspelled keywords",
+			keywords:    []string{"databse", "jenkin", "api"},
+			ok:          false,
+			reason:      KeywordsAreNotOpenShiftCategories + " (v1.0)\n" + KeywordsAreNotCategories + ": databse (did you mean database?), jenkin (did you mean jenkins?), api",
+		},
+		{
+			description: "configured categories",
+			keywords:    []string{"widgets", "database"},
+			config: map[string]interface{}{
+				CategoriesConfigString: []map[string]interface{}{{"name": "Widgets", "keywords": []string{"widgets"}}},
+			},
+			ok:     true,
+			reason: KeywordsAreOpenShiftCategories + " (configured): Widgets\n" + KeywordsAreNotCategories + ": database",
+		},
+		{
+			description: "unknown categories version",
+			keywords:    []string{"database"},
+			config:      map[string]interface{}{CategoriesVersionConfigString: "v0.1"},
+			ok:          false,
+			reason:      KeywordsCategoriesFailure + `: unknown OpenShift categories version "v0.1"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			r, err := KeywordsAreOpenshiftCategories(&CheckOptions{URI: chartWithKeywords(t, tc.keywords...), ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
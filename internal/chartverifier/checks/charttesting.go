@@ -2,9 +2,14 @@ package checks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/Masterminds/semver"
@@ -14,42 +19,77 @@ import (
 	"github.com/opdev/getocprange"
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/registry"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
 	"github.com/redhat-certification/chart-verifier/internal/tool"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
 const (
-	ReleaseConfigString string = "release"
+	ReleaseConfigString            string = "release"
+	BestEffortConfigString         string = "bestEffort"
+	ParallelConfigString           string = "parallel"
+	SkipDiagnosticsConfigString    string = "skipDiagnostics"
+	DiagnosticsDirConfigString     string = "diagnosticsDir"
+	ServerSideApplyConfigString    string = "serverSideApply"
+	WaitStrategyConfigString       string = "waitStrategy"
+	ServerSideApplyAliasString     string = "server-side-apply"
+	WaitStrategyAliasString        string = "wait-strategy"
+	WaitForKindsConfigString       string = "waitFor.kinds"
+	WaitForReadyConfigString       string = "waitFor.readyConditions"
+	UpgradeFromChartConfigString   string = "upgradeFrom.chart"
+	UpgradeFromRepoConfigString    string = "upgradeFrom.repo"
+	UpgradeFromVersionConfigString string = "upgradeFrom.version"
 )
 
-// Versioner provides OpenShift version
-type Versioner func(envSettings *cli.EnvSettings) (string, error)
+// Versioner provides OpenShift version and describes the cluster it was
+// detected on, even when the version couldn't be detected.
+type Versioner func(envSettings *cli.EnvSettings) (string, apiReport.TestEnvironment, error)
+
+// clusterVersionTimeout bounds the time spent reading the ClusterVersion.
+var clusterVersionTimeout = 30 * time.Second
+
+func getVersion(envSettings *cli.EnvSettings) (string, apiReport.TestEnvironment, error) {
+	environment := apiReport.TestEnvironment{}
 
-func getVersion(envSettings *cli.EnvSettings) (string, error) {
 	kubeConfig := tool.GetClientConfig(envSettings)
 	kubectl, err := tool.NewKubectl(kubeConfig)
 	if err != nil {
-		return "", err
+		return "", environment, err
 	}
 
 	serverVersion, err := kubectl.GetServerVersion()
 	if err != nil {
-		return "", err
+		return "", environment, err
+	}
+	environment.KubernetesVersion = serverVersion.GitVersion
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterVersionTimeout)
+	defer cancel()
+	OCPVersion, err := kubectl.GetOpenShiftVersion(ctx)
+	if err == nil {
+		environment.OpenShift = true
+		environment.OpenShiftVersionDetection = apiReport.ClusterVersionDetection
+		return OCPVersion, environment, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		utils.LogWarning(fmt.Sprintf("Error getting the OpenShift ClusterVersion, falling back to the kubernetes version: %v", err))
 	}
 
-	// Relying on Kubernetes version can be replaced after fixing this issue:
-	// https://bugzilla.redhat.com/show_bug.cgi?id=1850656
 	kubeVersion := fmt.Sprintf("%s.%s", serverVersion.Major, serverVersion.Minor)
 
 	// We can safely assume that GetOCPRange is going to return a single version rather than a range,
 	// given that "kubeVersion" is itself a single version and not a range.
-	OCPVersion, err := getocprange.GetOCPRange(kubeVersion)
+	OCPVersion, err = getocprange.GetOCPRange(kubeVersion)
 	if err != nil {
-		return "", fmt.Errorf("Error translating kubeVersion %q to an OCP version: %v", kubeVersion, err)
+		return "", environment, fmt.Errorf("Error translating kubeVersion %q to an OCP version: %v", kubeVersion, err)
 	}
+	environment.OpenShiftVersionDetection = apiReport.KubeVersionMappingDetection
 
-	return OCPVersion, nil
+	return OCPVersion, environment, nil
 }
 
 type OpenShiftVersionErr string
@@ -116,6 +156,20 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 		return NewResult(false, err.Error()), nil
 	}
 
+	serverSideApply, waitStrategy, err := getApplyOptions(opts)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("End chart install and test check with getApplyOptions error: %v", err))
+		return NewResult(false, err.Error()), nil
+	}
+	helm.SetApplyOptions(serverSideApply, waitStrategy)
+	opts.AnnotationHolder.SetApplyOptions(serverSideApply, string(waitStrategy))
+
+	bestEffort, parallel, err := getValuesFilesOptions(opts)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("End chart install and test check with getValuesFilesOptions error: %v", err))
+		return NewResult(false, err.Error()), nil
+	}
+
 	kubeConfig := tool.GetClientConfig(opts.HelmEnvSettings)
 	kubectl, err := tool.NewKubectl(kubeConfig)
 	if err != nil {
@@ -123,6 +177,26 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 		return NewResult(false, err.Error()), nil
 	}
 
+	if opts.EphemeralCluster != "" {
+		setEphemeralTestEnvironment(opts.AnnotationHolder, opts.EphemeralCluster, kubectl)
+	}
+
+	// Report the permissions the install would fail on before attempting
+	// it, rather than leaving a partially installed release behind.
+	mode := installMode{
+		serverSideApply: serverSideApply,
+		upgrade:         cfg.Upgrade,
+		uninstall:       !opts.SkipCleanup || cfg.Namespace == "",
+		waitStrategy:    waitStrategy,
+		driver:          os.Getenv("HELM_DRIVER"),
+	}
+	if missing, err := missingChartTestingAccess(ctx, opts, kubectl, cfg.Namespace, mode); err != nil {
+		utils.LogWarning(fmt.Sprintf("Unable to evaluate the permissions needed to install the chart: %v", err))
+	} else if len(missing) > 0 {
+		utils.LogError("End chart install and test check with missing permissions")
+		return NewResult(false, missingInstallAccessReason(missing)), nil
+	}
+
 	_, path, err := LoadChartFromURI(opts)
 	if err != nil {
 		utils.LogError("End chart install and test check with LoadChartFromURI error")
@@ -140,15 +214,41 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 		utils.LogInfo(fmt.Sprintf("User specified release: %s", configRelease))
 	}
 
+	readinessEvaluators, err := tool.NewReadinessEvaluators(
+		opts.ViperConfig.GetStringSlice(WaitForKindsConfigString),
+		opts.ViperConfig.GetStringSlice(WaitForReadyConfigString))
+	if err != nil {
+		utils.LogError(fmt.Sprintf("End chart install and test check with NewReadinessEvaluators error: %v", err))
+		return NewResult(false, err.Error()), nil
+	}
+
+	testOpts := releaseTestOptions{
+		valuesOverrides:     opts.Values,
+		configRelease:       configRelease,
+		skipCleanup:         opts.SkipCleanup,
+		bestEffort:          bestEffort,
+		parallel:            parallel,
+		readinessEvaluators: readinessEvaluators,
+	}
+	if !opts.ViperConfig.GetBool(SkipDiagnosticsConfigString) {
+		testOpts.diagnosticsDir = opts.ViperConfig.GetString(DiagnosticsDirConfigString)
+		if testOpts.diagnosticsDir == "" {
+			testOpts.diagnosticsDir = filepath.Join(utils.OutputDirectory, "diagnostics")
+		}
+	}
+
+	var valuesFileResults []apiReport.ValuesFileResult
 	if cfg.Upgrade {
-		oldChrt, err := getChartPreviousVersion(chrt)
+		oldChrt, err := getChartPreviousVersion(opts, helm, chrt)
 		if err != nil {
-			utils.LogError("End chart install and test check with getChartPreviousVersion error")
+			utils.LogError(fmt.Sprintf("End chart install and test check with getChartPreviousVersion error: %v", err))
 			return NewResult(
 					false,
-					fmt.Sprintf("skipping upgrade test of '%s' because no previous chart is available", chrt.Yaml().Name)),
+					fmt.Sprintf("skipping upgrade test of '%s' because no previous chart is available: %v", chrt.Yaml().Name, err)),
 				nil
 		}
+		utils.LogInfo(fmt.Sprintf("Upgrade testing from previous chart: %s", oldChrt))
+		opts.AnnotationHolder.SetUpgradedFrom(oldChrt.Yaml().Name, oldChrt.Yaml().Version)
 		breakingChangeAllowed, err := util.BreakingChangeAllowed(oldChrt.Yaml().Version, chrt.Yaml().Version)
 		if !breakingChangeAllowed {
 			utils.LogError("End chart install and test check with BreakingChangeAllowed not allowed")
@@ -160,34 +260,97 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 			utils.LogError(fmt.Sprintf("End chart install and test check with BreakingChangeAllowed error: %v", err))
 			return NewResult(false, err.Error()), nil
 		}
-		result := upgradeAndTestChart(ctx, cfg, oldChrt, chrt, helm, kubectl, configRelease, opts.SkipCleanup)
-
+		var result chart.TestResult
+		result, valuesFileResults = upgradeAndTestChart(ctx, cfg, oldChrt, chrt, helm, kubectl, testOpts)
 		if result.Error != nil {
 			utils.LogError(fmt.Sprintf("End chart install and test check with upgradeAndTestChart error: %v", result.Error))
-			return NewResult(false, result.Error.Error()), nil
+			r := NewResult(false, result.Error.Error())
+			r.ValuesFileResults = valuesFileResults
+			return r, nil
 		}
 	} else {
-		result := installAndTestChartRelease(ctx, cfg, chrt, helm, kubectl, opts.Values, configRelease, opts.SkipCleanup)
+		var result chart.TestResult
+		result, valuesFileResults = installAndTestChartRelease(ctx, cfg, chrt, helm, kubectl, testOpts)
 		if result.Error != nil {
 			utils.LogError(fmt.Sprintf("End chart install and test check with installAndTestChartRelease error: %v", result.Error))
-			return NewResult(false, result.Error.Error()), nil
+			r := NewResult(false, result.Error.Error())
+			r.ValuesFileResults = valuesFileResults
+			return r, nil
 		}
 	}
 
-	if versionError := setOCVersion(opts.AnnotationHolder, opts.HelmEnvSettings, getVersion); versionError != nil {
+	if opts.EphemeralCluster != "" {
+		// Ephemeral clusters aren't OpenShift clusters, the kubernetes
+		// version must not be mapped to an OpenShift version.
+		opts.AnnotationHolder.SetCertifiedOpenShiftVersion("N/A")
+	} else if versionError := setOCVersion(opts.AnnotationHolder, opts.HelmEnvSettings, getVersion); versionError != nil {
 		if versionError != nil {
 			utils.LogWarning(fmt.Sprintf("End chart install and test check with version error: %v", versionError))
 		}
-		return NewResult(false, versionError.Error()), nil
+		r := NewResult(false, versionError.Error())
+		r.ValuesFileResults = valuesFileResults
+		return r, nil
 	}
 
 	utils.LogInfo("End chart install and test check")
-	return NewResult(true, ChartTestingSuccess), nil
+	r := NewResult(true, ChartTestingSuccess)
+	r.ValuesFileResults = valuesFileResults
+	return r, nil
+}
+
+// getApplyOptions returns whether releases are applied with server-side
+// apply and the wait strategy to use. The serverSideApply and waitStrategy
+// check settings, or their server-side-apply and wait-strategy aliases, take
+// precedence over the verifier options.
+func getApplyOptions(opts *CheckOptions) (bool, kube.WaitStrategy, error) {
+	serverSideApply := opts.ServerSideApply
+	for _, key := range []string{ServerSideApplyAliasString, ServerSideApplyConfigString} {
+		if opts.ViperConfig.IsSet(key) {
+			serverSideApply = opts.ViperConfig.GetBool(key)
+		}
+	}
+	strategy := opts.WaitStrategy
+	for _, key := range []string{WaitStrategyAliasString, WaitStrategyConfigString} {
+		if opts.ViperConfig.IsSet(key) {
+			strategy = opts.ViperConfig.GetString(key)
+		}
+	}
+	waitStrategy, err := tool.ParseWaitStrategy(strategy)
+	if err != nil {
+		return false, "", err
+	}
+	return serverSideApply, waitStrategy, nil
+}
+
+// getValuesFilesOptions returns whether every values file is tested and
+// whether they are tested in parallel. Parallel tests every values file, so
+// it is an error to combine it with bestEffort set to false.
+func getValuesFilesOptions(opts *CheckOptions) (bool, bool, error) {
+	parallel := opts.ViperConfig.GetBool(ParallelConfigString)
+	if !parallel {
+		return opts.ViperConfig.GetBool(BestEffortConfigString), false, nil
+	}
+	if opts.ViperConfig.IsSet(BestEffortConfigString) && !opts.ViperConfig.GetBool(BestEffortConfigString) {
+		return false, false, fmt.Errorf("%s tests every values file and cannot be combined with %s set to false", ParallelConfigString, BestEffortConfigString)
+	}
+	return true, true, nil
+}
+
+// setEphemeralTestEnvironment records that the chart is tested on a cluster
+// run by provider, which is not an OpenShift cluster.
+func setEphemeralTestEnvironment(holder AnnotationHolder, provider string, kubectl *tool.Kubectl) {
+	environment := apiReport.TestEnvironment{EphemeralClusterProvider: provider}
+	if serverVersion, err := kubectl.GetServerVersion(); err != nil {
+		utils.LogWarning(fmt.Sprintf("Error getting the %s cluster version: %v", provider, err))
+	} else {
+		environment.KubernetesVersion = serverVersion.GitVersion
+	}
+	holder.SetTestEnvironment(environment)
 }
 
 // generateInstallConfig extracts required information to install a
 // release and builds a clenup function to be used after tests are
-// executed.
+// executed. The cleanup function returns the release uninstall error.
 func generateInstallConfig(
 	cfg config.Configuration,
 	chrt *chart.Chart,
@@ -195,7 +358,7 @@ func generateInstallConfig(
 	kubectl *tool.Kubectl,
 	configRelease string,
 	skipCleanup bool,
-) (namespace, release, releaseSelector string, cleanup func()) {
+) (namespace, release, releaseSelector string, cleanup func() error) {
 	release = configRelease
 	if cfg.Namespace != "" {
 		namespace = cfg.Namespace
@@ -203,14 +366,12 @@ func generateInstallConfig(
 			release, _ = chrt.CreateInstallParams(cfg.BuildID)
 		}
 		releaseSelector = fmt.Sprintf("%s=%s", cfg.ReleaseLabel, release)
-		cleanup = func() {
-			//nolint:errcheck // TODO(komish) identify if this error needs to be
-			// handled nicely
+		cleanup = func() error {
 			if skipCleanup {
 				utils.LogInfo("Skipping resource cleanup")
-			} else {
-				helm.Uninstall(namespace, release)
+				return nil
 			}
+			return helm.Uninstall(namespace, release)
 		}
 	} else {
 		if len(release) == 0 {
@@ -218,102 +379,176 @@ func generateInstallConfig(
 		} else {
 			_, namespace = chrt.CreateInstallParams(cfg.BuildID)
 		}
-		cleanup = func() {
-			//nolint:errcheck // TODO(komish) identify if this error needs to be
-			// handled nicely
-			helm.Uninstall(namespace, release)
+		cleanup = func() error {
+			err := helm.Uninstall(namespace, release)
 			//nolint:errcheck // TODO(komish) identify if this error needs to be
 			// handled nicely
 			kubectl.DeleteNamespace(context.TODO(), namespace)
+			return err
 		}
 	}
 	return namespace, release, releaseSelector, cleanup
 }
 
-// testRelease tests a release.
-// nolint:unparam //TODO(komish) identify the value of cleanupHelmTests historically.
-func testRelease(
-	ctx context.Context,
-	helm *tool.Helm,
-	kubectl *tool.Kubectl,
-	release, namespace, releaseSelector string,
-	cleanupHelmTests bool,
-) error {
-	if err := kubectl.WaitForWorkloadResources(ctx, namespace, releaseSelector); err != nil {
-		return err
+// getChartPreviousVersion retrieves the chart the upgrade test starts from,
+// as configured through the upgradeFrom settings:
+//
+//   - upgradeFrom.chart: a local path, an OCI reference, or the chart name
+//     in upgradeFrom.repo (defaults to the name of the given chart).
+//   - upgradeFrom.repo: the URL of the Helm repository hosting the chart.
+//   - upgradeFrom.version: an exact version or a semver constraint; when
+//     not set for remote charts, the highest version lower than the given
+//     chart's version is used.
+//
+// The returned chart is guaranteed to have a lower version than chrt.
+func getChartPreviousVersion(opts *CheckOptions, helm *tool.Helm, chrt *chart.Chart) (*chart.Chart, error) {
+	ref := opts.ViperConfig.GetString(UpgradeFromChartConfigString)
+	repoURL := opts.ViperConfig.GetString(UpgradeFromRepoConfigString)
+	version := opts.ViperConfig.GetString(UpgradeFromVersionConfigString)
+
+	if ref == "" && repoURL == "" {
+		return nil, errors.New("no previous chart configured")
 	}
-	if err := helm.Test(ctx, namespace, release); err != nil {
-		return err
+	if ref == "" {
+		ref = chrt.Yaml().Name
+	}
+	if version == "" && (repoURL != "" || registry.IsOCI(ref)) {
+		version = "< " + chrt.Yaml().Version
+	}
+
+	chartPath, err := helm.LocateChart(ref, repoURL, version)
+	if err != nil {
+		return nil, fmt.Errorf("locating previous chart %q: %w", ref, err)
 	}
-	return nil
-}
 
-// getChartPreviousVersion attempts to retrieve the previous version
-// of the given chart.
-func getChartPreviousVersion(chrt *chart.Chart) (*chart.Chart, error) {
-	// TODO: decide which sources do we consider when searching for a
-	//       previous version's candidate
-	return chrt, nil
+	// Extract packaged charts through the chart cache, chart-testing
+	// expects a chart directory.
+	_, chartPath, err = LoadChartFromURI(&CheckOptions{HelmEnvSettings: opts.HelmEnvSettings, URI: chartPath})
+	if err != nil {
+		return nil, fmt.Errorf("loading previous chart %q: %w", ref, err)
+	}
+
+	oldChrt, err := chart.NewChart(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading previous chart %q: %w", ref, err)
+	}
+
+	oldVersion, err := semver.NewVersion(oldChrt.Yaml().Version)
+	if err != nil {
+		return nil, fmt.Errorf("previous chart version %q: %w", oldChrt.Yaml().Version, err)
+	}
+	newVersion, err := semver.NewVersion(chrt.Yaml().Version)
+	if err != nil {
+		return nil, fmt.Errorf("chart version %q: %w", chrt.Yaml().Version, err)
+	}
+	if !oldVersion.LessThan(newVersion) {
+		return nil, fmt.Errorf("previous chart version %s is not lower than chart version %s", oldVersion, newVersion)
+	}
+
+	return oldChrt, nil
 }
 
-// upgradeAndTestChart performs the installation of the given oldChrt,
-// and attempts to perform an upgrade from that state.
+// upgradeAndTestChart installs and tests the given oldChrt, then upgrades
+// the release to chrt and tests it again, for each values file in the 'ci'
+// folder of oldChrt, or with the default values if there are none. The
+// values files are tested as by installAndTestChartRelease, and the per
+// values file outcomes are returned regardless of the mode.
 func upgradeAndTestChart(
 	ctx context.Context,
 	cfg config.Configuration,
 	oldChrt, chrt *chart.Chart,
 	helm *tool.Helm,
 	kubectl *tool.Kubectl,
-	configRelease string,
-	skipCleanup bool,
-) chart.TestResult {
-	// result contains the test result; please notice that each values
-	// file in the chart's 'ci' folder will be installed and tested
-	// and the first failure makes the test fail.
-	result := chart.TestResult{Chart: chrt}
-
-	valuesFiles := oldChrt.ValuesFilePathsForCI()
-	if len(valuesFiles) == 0 {
+	testOpts releaseTestOptions,
+) (chart.TestResult, []apiReport.ValuesFileResult) {
+	var valuesFiles []string
+	for _, valuesFile := range oldChrt.ValuesFilePathsForCI() {
+		if cfg.SkipMissingValues && !chrt.HasCIValuesFile(valuesFile) {
+			// TODO: do not assume STDOUT here; instead a writer
+			//       should be given to be written to.
+			utils.LogWarning(fmt.Sprintf("Upgrade testing for values file '%s' skipped because a corresponding values file was not found in %s/ci", valuesFile, chrt.Path()))
+			continue
+		}
+		valuesFiles = append(valuesFiles, valuesFile)
+	}
+	if len(oldChrt.ValuesFilePathsForCI()) == 0 {
 		valuesFiles = append(valuesFiles, "")
 	}
-	for _, valuesFile := range valuesFiles {
-		if valuesFile != "" {
-			if cfg.SkipMissingValues && !chrt.HasCIValuesFile(valuesFile) {
-				// TODO: do not assume STDOUT here; instead a writer
-				//       should be given to be written to.
-				utils.LogWarning(fmt.Sprintf("Upgrade testing for values file '%s' skipped because a corresponding values file was not found in %s/ci", valuesFile, chrt.Path()))
-				continue
-			}
-		}
-
-		// Use anonymous function. Otherwise deferred calls would pile up
-		// and be executed in reverse order after the loop.
-		fun := func() error {
-			namespace, release, releaseSelector, cleanup := generateInstallConfig(cfg, oldChrt, helm, kubectl, configRelease, skipCleanup)
-			defer cleanup()
-
-			// Install previous version of chart. If installation fails, ignore this release.
-			if err := helm.Install(ctx, namespace, oldChrt.Path(), release, valuesFile); err != nil {
-				return fmt.Errorf("upgrade testing for release '%s' skipped because of previous revision installation error: %w", release, err)
-			}
-			if err := testRelease(ctx, helm, kubectl, release, namespace, releaseSelector, true); err != nil {
-				return fmt.Errorf("upgrade testing for release '%s' skipped because of previous revision testing error", release)
-			}
-
-			if err := helm.Upgrade(ctx, namespace, oldChrt.Path(), release); err != nil {
-				return err
-			}
 
-			return testRelease(ctx, helm, kubectl, release, namespace, releaseSelector, false)
-		}
-
-		if err := fun(); err != nil {
-			result.Error = err
-			break
-		}
+	test := func(ctx context.Context, cfg config.Configuration, helm *tool.Helm, valuesFile string, testOpts releaseTestOptions) (apiReport.ValuesFileResult, error) {
+		return upgradeAndTestValuesFile(ctx, cfg, oldChrt, chrt, helm, kubectl, valuesFile, testOpts)
 	}
+	valuesFileResults, err := testValuesFiles(ctx, cfg, oldChrt, helm, kubectl, valuesFiles, testOpts, test)
+	return chart.TestResult{Chart: chrt, Error: err}, valuesFileResults
+}
 
-	return result
+// upgradeAndTestValuesFile installs and tests a release of oldChrt with the
+// given values file, then upgrades it to chrt and tests it again, recording
+// the outcome and duration of each step.
+func upgradeAndTestValuesFile(
+	ctx context.Context,
+	cfg config.Configuration,
+	oldChrt, chrt *chart.Chart,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	valuesFile string,
+	testOpts releaseTestOptions,
+) (valuesFileResult apiReport.ValuesFileResult, err error) {
+	valuesFileResult = apiReport.ValuesFileResult{
+		ValuesFile: valuesFileName(oldChrt, valuesFile),
+		Outcome:    apiReport.FailOutcomeType,
+	}
+	defer func() {
+		switch {
+		case err == nil:
+			valuesFileResult.Outcome = apiReport.PassOutcomeType
+		case valuesFile != "":
+			err = fmt.Errorf("%s: %w", valuesFileResult.ValuesFile, err)
+		}
+	}()
+
+	namespace, release, releaseSelector, releaseCleanup := generateInstallConfig(cfg, oldChrt, helm, kubectl, testOpts.configRelease, testOpts.skipCleanup)
+	previousFailure := fmt.Sprintf("upgrade testing for release '%s' skipped because of previous revision", release)
+
+	steps := []releaseStep{
+		{
+			name: "install-previous",
+			run:  func() error { return helm.Install(ctx, namespace, oldChrt.Path(), release, valuesFile) },
+			wrap: previousFailure + " installation error",
+		},
+		{
+			name: "wait-previous",
+			run: func() error {
+				return kubectl.WaitForWorkloadResources(ctx, namespace, releaseSelector, testOpts.readinessEvaluators...)
+			},
+			wrap: previousFailure + " testing error",
+		},
+		{
+			name: "test-previous",
+			run:  func() error { return helm.Test(ctx, namespace, release) },
+			wrap: previousFailure + " testing error",
+		},
+		{
+			name: "upgrade",
+			run:  func() error { return helm.Upgrade(ctx, namespace, chrt.Path(), release) },
+			wrap: "chart Upgrade failure",
+		},
+		{
+			name: "wait",
+			run: func() error {
+				return kubectl.WaitForWorkloadResources(ctx, namespace, releaseSelector, testOpts.readinessEvaluators...)
+			},
+			wrap: "chart test failure",
+		},
+		{
+			name: "test",
+			run:  func() error { return helm.Test(ctx, namespace, release) },
+			wrap: "chart test failure",
+		},
+	}
+
+	err = runReleaseSteps(&valuesFileResult, steps, kubectl, testOpts, namespace, releaseSelector, release, releaseCleanup)
+	return valuesFileResult, err
 }
 
 // readObjectFromYamlFile unmarshals the given filename and returns an object with its contents.
@@ -393,17 +628,41 @@ func newTempValuesFileWithOverrides(filename string, valuesOverrides map[string]
 	return newValuesFile, clean, nil
 }
 
-// installAndTestChartRelease installs and tests a chart release.
+// releaseTestOptions holds the settings shared by all the releases installed
+// and tested by the chart-testing check.
+type releaseTestOptions struct {
+	// valuesOverrides are the values informed through --chart-set and
+	// related options.
+	valuesOverrides map[string]interface{}
+	// configRelease is the release name set by the user, if any.
+	configRelease string
+	skipCleanup   bool
+	// bestEffort tests every values file instead of stopping at the first
+	// failure.
+	bestEffort bool
+	// parallel tests the values files concurrently in dedicated namespaces.
+	// Every values file is tested, as with bestEffort.
+	parallel bool
+	// diagnosticsDir is where diagnostics archives are written when a
+	// release fails; empty disables diagnostics collection.
+	diagnosticsDir string
+	// readinessEvaluators determine which resources must be ready before
+	// the release is tested.
+	readinessEvaluators []tool.ReadinessEvaluator
+}
+
+// installAndTestChartRelease installs and tests a chart release for each
+// values file in the chart's 'ci' folder, or with the default values if
+// there are none, as described by testValuesFiles. The per values file
+// outcomes are returned regardless of the mode.
 func installAndTestChartRelease(
 	ctx context.Context,
 	cfg config.Configuration,
 	chrt *chart.Chart,
 	helm *tool.Helm,
 	kubectl *tool.Kubectl,
-	valuesOverrides map[string]interface{},
-	configRelease string,
-	skipCleanup bool,
-) chart.TestResult {
+	testOpts releaseTestOptions,
+) (chart.TestResult, []apiReport.ValuesFileResult) {
 	// valuesFiles contains all the configurations that should be
 	// executed; in other words, it performs a test matrix between
 	// values files and tests.
@@ -414,50 +673,290 @@ func installAndTestChartRelease(
 		valuesFiles = append(valuesFiles, "")
 	}
 
-	result := chart.TestResult{Chart: chrt}
+	test := func(ctx context.Context, cfg config.Configuration, helm *tool.Helm, valuesFile string, testOpts releaseTestOptions) (apiReport.ValuesFileResult, error) {
+		return installAndTestValuesFile(ctx, cfg, chrt, helm, kubectl, valuesFile, testOpts)
+	}
+	valuesFileResults, err := testValuesFiles(ctx, cfg, chrt, helm, kubectl, valuesFiles, testOpts, test)
+	return chart.TestResult{Chart: chrt, Error: err}, valuesFileResults
+}
 
-	for _, valuesFile := range valuesFiles {
-		// Use anonymous function. Otherwise deferred calls would pile up
-		// and be executed in reverse order after the loop.
-		fun := func() error {
-			tmpValuesFile, tmpValuesFileCleanup, err := newTempValuesFileWithOverrides(valuesFile, valuesOverrides)
-			if err != nil {
-				// it is required this operation to succeed, otherwise there are no guarantees the values informed using
-				// `--chart-set` are propagated to the installation process, so the process breaks here.
-				return fmt.Errorf("creating temporary values file: %w", err)
-			}
-			defer tmpValuesFileCleanup()
+// valuesFileTest installs and tests a release with the given values file.
+type valuesFileTest func(
+	ctx context.Context,
+	cfg config.Configuration,
+	helm *tool.Helm,
+	valuesFile string,
+	testOpts releaseTestOptions,
+) (apiReport.ValuesFileResult, error)
+
+// testValuesFiles runs test for each of valuesFiles of chrt. The first
+// failure stops the process unless bestEffort is set, in which case every
+// values file is tested and the failures are joined in the returned error.
+// With parallel set, values files are tested concurrently, each in its own
+// namespace.
+func testValuesFiles(
+	ctx context.Context,
+	cfg config.Configuration,
+	chrt *chart.Chart,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	valuesFiles []string,
+	testOpts releaseTestOptions,
+	test valuesFileTest,
+) ([]apiReport.ValuesFileResult, error) {
+	valuesFileResults := make([]apiReport.ValuesFileResult, len(valuesFiles))
+	errs := make([]error, len(valuesFiles))
+
+	if testOpts.parallel && len(valuesFiles) > 1 {
+		var wg sync.WaitGroup
+		for i, valuesFile := range valuesFiles {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				valuesFileResults[i], errs[i] = testValuesFileInNamespace(ctx, cfg, chrt, helm, kubectl, valuesFile, i, testOpts, test)
+			}()
+		}
+		wg.Wait()
+		return valuesFileResults, errors.Join(errs...)
+	}
 
-			namespace, release, releaseSelector, releaseCleanup := generateInstallConfig(cfg, chrt, helm, kubectl, configRelease, skipCleanup)
-			defer releaseCleanup()
+	for i, valuesFile := range valuesFiles {
+		valuesFileResults[i], errs[i] = test(ctx, cfg, helm, valuesFile, testOpts)
+		if errs[i] != nil && !testOpts.bestEffort {
+			// fail fast approach.
+			valuesFileResults = valuesFileResults[:i+1]
+			break
+		}
+	}
 
-			if err := helm.Install(ctx, namespace, chrt.Path(), release, tmpValuesFile); err != nil {
-				return fmt.Errorf("chart Install failure: %v", err)
-			}
-			if err = testRelease(ctx, helm, kubectl, release, namespace, releaseSelector, false); err != nil {
-				return fmt.Errorf("chart test failure: %v", err)
-			}
-			return nil
+	return valuesFileResults, errors.Join(errs...)
+}
+
+// testValuesFileInNamespace runs test in a namespace dedicated to the
+// index-th values file of chrt, using its own Helm configuration so that it
+// can run concurrently with other values files.
+func testValuesFileInNamespace(
+	ctx context.Context,
+	cfg config.Configuration,
+	chrt *chart.Chart,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	valuesFile string,
+	index int,
+	testOpts releaseTestOptions,
+	test valuesFileTest,
+) (apiReport.ValuesFileResult, error) {
+	valuesFileResult := apiReport.ValuesFileResult{
+		ValuesFile: valuesFileName(chrt, valuesFile),
+		Outcome:    apiReport.FailOutcomeType,
+	}
+
+	// The release records are kept in the namespace of the release, so
+	// that deleting the namespace deletes them as well.
+	cfg.Namespace = fmt.Sprintf("%s-%d", cfg.Namespace, index+1)
+	helm, err := helm.Clone(cfg.Namespace)
+	if err != nil {
+		return valuesFileResult, fmt.Errorf("%s: %w", valuesFileResult.ValuesFile, err)
+	}
+
+	// Release names must be unique as well, so that the releases of the
+	// values files can be told apart.
+	if len(testOpts.configRelease) == 0 {
+		testOpts.configRelease, _ = chrt.CreateInstallParams(cfg.BuildID)
+	}
+	testOpts.configRelease = fmt.Sprintf("%s-%d", testOpts.configRelease, index+1)
+
+	err = runStep(&valuesFileResult, "create-namespace", func() error {
+		return kubectl.CreateNamespace(ctx, cfg.Namespace)
+	})
+	if err != nil {
+		return valuesFileResult, fmt.Errorf("%s: creating namespace %q: %w", valuesFileResult.ValuesFile, cfg.Namespace, err)
+	}
+	if !testOpts.skipCleanup {
+		defer func() {
+			//nolint:errcheck // the namespace is a disposable sandbox
+			kubectl.DeleteNamespace(context.TODO(), cfg.Namespace)
+		}()
+	}
+
+	result, err := test(ctx, cfg, helm, valuesFile, testOpts)
+	result.Steps = append(valuesFileResult.Steps, result.Steps...)
+	return result, err
+}
+
+// installAndTestValuesFile installs and tests a chart release with the
+// given values file, recording the outcome and duration of each step.
+func installAndTestValuesFile(
+	ctx context.Context,
+	cfg config.Configuration,
+	chrt *chart.Chart,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	valuesFile string,
+	testOpts releaseTestOptions,
+) (valuesFileResult apiReport.ValuesFileResult, err error) {
+	valuesFileResult = apiReport.ValuesFileResult{
+		ValuesFile: valuesFileName(chrt, valuesFile),
+		Outcome:    apiReport.FailOutcomeType,
+	}
+	defer func() {
+		switch {
+		case err == nil:
+			valuesFileResult.Outcome = apiReport.PassOutcomeType
+		case valuesFile != "":
+			err = fmt.Errorf("%s: %w", valuesFileResult.ValuesFile, err)
 		}
+	}()
+
+	tmpValuesFile, tmpValuesFileCleanup, err := newTempValuesFileWithOverrides(valuesFile, testOpts.valuesOverrides)
+	if err != nil {
+		// it is required this operation to succeed, otherwise there are no guarantees the values informed using
+		// `--chart-set` are propagated to the installation process, so the process breaks here.
+		return valuesFileResult, fmt.Errorf("creating temporary values file: %w", err)
+	}
+	defer tmpValuesFileCleanup()
+
+	namespace, release, releaseSelector, releaseCleanup := generateInstallConfig(cfg, chrt, helm, kubectl, testOpts.configRelease, testOpts.skipCleanup)
+
+	steps := []releaseStep{
+		{
+			name: "install",
+			run:  func() error { return helm.Install(ctx, namespace, chrt.Path(), release, tmpValuesFile) },
+			wrap: "chart Install failure",
+		},
+		{
+			name: "wait",
+			run: func() error {
+				return kubectl.WaitForWorkloadResources(ctx, namespace, releaseSelector, testOpts.readinessEvaluators...)
+			},
+			wrap: "chart test failure",
+		},
+		{
+			name: "test",
+			run:  func() error { return helm.Test(ctx, namespace, release) },
+			wrap: "chart test failure",
+		},
+	}
+
+	err = runReleaseSteps(&valuesFileResult, steps, kubectl, testOpts, namespace, releaseSelector, release, releaseCleanup)
+	return valuesFileResult, err
+}
 
-		if err := fun(); err != nil {
-			// fail fast approach; could be changed to best effort.
-			result.Error = err
+// releaseStep is a step of the test of a release; the error of run is
+// wrapped with wrap.
+type releaseStep struct {
+	name string
+	run  func() error
+	wrap string
+}
+
+// runReleaseSteps runs steps in order, recording them in valuesFileResult,
+// until one fails. The diagnostics of the release are then collected, and
+// the remaining steps are recorded as skipped. The release is uninstalled
+// with cleanup unless skipCleanup is set.
+func runReleaseSteps(
+	valuesFileResult *apiReport.ValuesFileResult,
+	steps []releaseStep,
+	kubectl *tool.Kubectl,
+	testOpts releaseTestOptions,
+	namespace, releaseSelector, release string,
+	cleanup func() error,
+) (err error) {
+	for i, step := range steps {
+		if stepErr := runStep(valuesFileResult, step.name, step.run); stepErr != nil {
+			err = fmt.Errorf("%s: %v", step.wrap, stepErr)
+			// The state of the release is gone once it is uninstalled, so
+			// diagnostics are collected right away.
+			if archive := collectDiagnostics(kubectl, testOpts.diagnosticsDir, namespace, releaseSelector, release, valuesFileResult.ValuesFile); archive != "" {
+				err = fmt.Errorf("%w (diagnostics: %s)", err, archive)
+			}
+			for _, skipped := range steps[i+1:] {
+				valuesFileResult.Steps = append(valuesFileResult.Steps, apiReport.StepResult{Name: skipped.name, Outcome: apiReport.SkippedOutcomeType})
+			}
 			break
 		}
 	}
 
-	return result
+	if testOpts.skipCleanup {
+		utils.LogInfo("Skipping resource cleanup")
+		valuesFileResult.Steps = append(valuesFileResult.Steps, apiReport.StepResult{Name: "uninstall", Outcome: apiReport.SkippedOutcomeType})
+	} else {
+		// uninstall failures are reported but don't fail the values file,
+		// matching the previous behavior of ignoring them.
+		//nolint:errcheck // recorded in the step result
+		runStep(valuesFileResult, "uninstall", cleanup)
+	}
+
+	return err
+}
+
+// diagnosticsTimeout bounds the time spent collecting diagnostics, which
+// happens after the check timeout may have expired.
+const diagnosticsTimeout = time.Minute
+
+// collectDiagnostics writes the diagnostics of release to an archive in dir
+// and returns its path, or an empty string if dir is empty or the archive
+// couldn't be written.
+func collectDiagnostics(kubectl *tool.Kubectl, dir, namespace, releaseSelector, release, valuesFile string) string {
+	if dir == "" {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+
+	name := fmt.Sprintf("%s-%s-%s.tar.gz",
+		release,
+		strings.NewReplacer("/", "-", ".yaml", "").Replace(valuesFile),
+		time.Now().Format("20060102-150405"))
+	archive := filepath.Join(dir, name)
+
+	utils.LogInfo(fmt.Sprintf("Collecting diagnostics of release %s in namespace %s", release, namespace))
+	if err := kubectl.CollectDiagnostics(ctx, namespace, releaseSelector, release).WriteArchive(archive); err != nil {
+		utils.LogWarning(fmt.Sprintf("Error writing diagnostics archive %s: %v", archive, err))
+		return ""
+	}
+	return archive
+}
+
+// runStep runs fn, appending its outcome and duration to valuesFileResult.
+func runStep(valuesFileResult *apiReport.ValuesFileResult, name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	step := apiReport.StepResult{
+		Name:     name,
+		Outcome:  apiReport.PassOutcomeType,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		step.Outcome = apiReport.FailOutcomeType
+		step.Message = err.Error()
+	}
+	valuesFileResult.Steps = append(valuesFileResult.Steps, step)
+	return err
+}
+
+// valuesFileName returns the path of valuesFile relative to the chart, or
+// values.yaml when the chart's default values are used.
+func valuesFileName(chrt *chart.Chart, valuesFile string) string {
+	if valuesFile == "" {
+		return "values.yaml"
+	}
+	if rel, err := filepath.Rel(chrt.Path(), valuesFile); err == nil {
+		return rel
+	}
+	return valuesFile
 }
 
 func setOCVersion(holder AnnotationHolder, envSettings *cli.EnvSettings, versioner Versioner) error {
-	// kubectl.GetVersion() returns an error both in case the kubectl command can't be executed and
-	// the value for the OpenShift version key not present.
-	osVersion, getVersionErr := versioner(envSettings)
+	// versioner returns an error both in case the cluster can't be reached and
+	// the OpenShift version can't be determined.
+	osVersion, environment, getVersionErr := versioner(envSettings)
 
 	// From this point on, an error is set and osVersion is empty.
 	if getVersionErr != nil && holder.GetCertifiedOpenShiftVersionFlag() != "" {
 		osVersion = holder.GetCertifiedOpenShiftVersionFlag()
+		environment.OpenShiftVersionDetection = apiReport.FlagDetection
 	}
 
 	// osVersion is empty only if an error happened and a default value
@@ -475,6 +974,7 @@ func setOCVersion(holder AnnotationHolder, envSettings *cli.EnvSettings, version
 	}
 
 	holder.SetCertifiedOpenShiftVersion(osVersion)
+	holder.SetTestEnvironment(environment)
 
 	return nil
 }
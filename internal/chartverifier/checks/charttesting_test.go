@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,9 +10,15 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/helm/chart-testing/v3/pkg/chart"
+	"github.com/helm/chart-testing/v3/pkg/config"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+
+	"github.com/redhat-certification/chart-verifier/internal/tool"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
 // absPathFromSourceFileLocation returns the absolute path of a file or directory under the current source file's
@@ -117,17 +124,22 @@ func TestChartTesting(t *testing.T) {
 	}
 }
 
-func getVersionError(settings *cli.EnvSettings) (string, error) {
-	return "", errors.New("error")
+func getVersionError(settings *cli.EnvSettings) (string, apiReport.TestEnvironment, error) {
+	return "", apiReport.TestEnvironment{KubernetesVersion: "v1.29.7"}, errors.New("error")
 }
 
-func getVersionGood(settings *cli.EnvSettings) (string, error) {
-	return "4.7.9", nil
+func getVersionGood(settings *cli.EnvSettings) (string, apiReport.TestEnvironment, error) {
+	return "4.7.9", apiReport.TestEnvironment{
+		KubernetesVersion:         "v1.20.0",
+		OpenShift:                 true,
+		OpenShiftVersionDetection: apiReport.ClusterVersionDetection,
+	}, nil
 }
 
 type testAnnotationHolder struct {
 	OpenShiftVersion              string
 	CertifiedOpenShiftVersionFlag string
+	TestEnvironment               apiReport.TestEnvironment
 }
 
 func (holder *testAnnotationHolder) SetCertifiedOpenShiftVersion(version string) {
@@ -140,12 +152,21 @@ func (holder *testAnnotationHolder) GetCertifiedOpenShiftVersionFlag() string {
 
 func (holder *testAnnotationHolder) SetSupportedOpenShiftVersions(version string) {}
 
+func (holder *testAnnotationHolder) SetUpgradedFrom(chart, version string) {}
+
+func (holder *testAnnotationHolder) SetApplyOptions(serverSideApply bool, waitStrategy string) {}
+
+func (holder *testAnnotationHolder) SetTestEnvironment(environment apiReport.TestEnvironment) {
+	holder.TestEnvironment = environment
+}
+
 func TestVersionSetting(t *testing.T) {
 	type testCase struct {
 		description string
 		opts        *CheckOptions
 		versioner   Versioner
 		version     string
+		detection   apiReport.OpenShiftVersionDetection
 		error       string
 	}
 
@@ -155,12 +176,14 @@ func TestVersionSetting(t *testing.T) {
 			opts:        &CheckOptions{AnnotationHolder: &testAnnotationHolder{}},
 			versioner:   getVersionGood,
 			version:     "4.7.9",
+			detection:   apiReport.ClusterVersionDetection,
 		},
 		{
 			description: "oc.Version returns error, flag set to 4.7.8",
 			opts:        &CheckOptions{AnnotationHolder: &testAnnotationHolder{CertifiedOpenShiftVersionFlag: "4.7.8"}},
 			versioner:   getVersionError,
 			version:     "4.7.8",
+			detection:   apiReport.FlagDetection,
 		},
 		{
 			description: "oc.Version returns semantic error, flag set to fourseveneight",
@@ -186,7 +209,229 @@ func TestVersionSetting(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 				require.Equal(t, tc.version, tc.opts.AnnotationHolder.(*testAnnotationHolder).OpenShiftVersion)
+				require.Equal(t, tc.detection, tc.opts.AnnotationHolder.(*testAnnotationHolder).TestEnvironment.OpenShiftVersionDetection)
+			}
+		})
+	}
+}
+
+func TestGetChartPreviousVersion(t *testing.T) {
+	psqlChartPath, err := absPathFromSourceFileLocation("psql-service-0.1.7")
+	require.NoError(t, err)
+	validChartPath, err := absPathFromSourceFileLocation("chart-0.1.0-v3.valid.tgz")
+	require.NoError(t, err)
+	chartDirPath, err := absPathFromSourceFileLocation("chart")
+	require.NoError(t, err)
+
+	psqlChart, err := chart.NewChart(psqlChartPath)
+	require.NoError(t, err)
+	chartDir, err := chart.NewChart(chartDirPath)
+	require.NoError(t, err)
+
+	settings := cli.New()
+	helm, err := tool.NewHelm(settings, nil, 0)
+	require.NoError(t, err)
+
+	type testCase struct {
+		description string
+		chrt        *chart.Chart
+		config      map[string]interface{}
+		version     string
+		error       string
+	}
+
+	testCases := []testCase{
+		{
+			description: "no previous chart configured",
+			chrt:        psqlChart,
+			error:       "no previous chart configured",
+		},
+		{
+			description: "previous chart from a local package",
+			chrt:        psqlChart,
+			config:      map[string]interface{}{UpgradeFromChartConfigString: validChartPath},
+			version:     "0.1.0-v3.valid",
+		},
+		{
+			description: "previous chart with a version not lower than the chart",
+			chrt:        chartDir,
+			config:      map[string]interface{}{UpgradeFromChartConfigString: psqlChartPath},
+			error:       "previous chart version 0.1.7 is not lower than chart version 0.1.0-v3.valid",
+		},
+		{
+			description: "previous chart not found",
+			chrt:        psqlChart,
+			config:      map[string]interface{}{UpgradeFromChartConfigString: "./does-not-exist"},
+			error:       "locating previous chart",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for k, v := range tc.config {
+				config.Set(k, v)
+			}
+			oldChrt, err := getChartPreviousVersion(&CheckOptions{ViperConfig: config, HelmEnvSettings: settings}, helm, tc.chrt)
+			if len(tc.error) > 0 {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.version, oldChrt.Yaml().Version)
+		})
+	}
+}
+
+func TestRunStep(t *testing.T) {
+	valuesFileResult := apiReport.ValuesFileResult{}
+
+	require.NoError(t, runStep(&valuesFileResult, "install", func() error { return nil }))
+	require.Error(t, runStep(&valuesFileResult, "test", func() error { return errors.New("timed out") }))
+
+	require.Len(t, valuesFileResult.Steps, 2)
+	require.Equal(t, "install", valuesFileResult.Steps[0].Name)
+	require.Equal(t, apiReport.PassOutcomeType, valuesFileResult.Steps[0].Outcome)
+	require.NotEmpty(t, valuesFileResult.Steps[0].Duration)
+	require.Equal(t, apiReport.FailOutcomeType, valuesFileResult.Steps[1].Outcome)
+	require.Equal(t, "timed out", valuesFileResult.Steps[1].Message)
+}
+
+func TestValuesFileName(t *testing.T) {
+	chartPath, err := absPathFromSourceFileLocation("psql-service-0.1.7")
+	require.NoError(t, err)
+	chrt, err := chart.NewChart(chartPath)
+	require.NoError(t, err)
+
+	require.Equal(t, "values.yaml", valuesFileName(chrt, ""))
+	require.Equal(t, filepath.Join("ci", "small-values.yaml"), valuesFileName(chrt, filepath.Join(chartPath, "ci", "small-values.yaml")))
+}
+
+func TestGetApplyOptions(t *testing.T) {
+	type testCase struct {
+		description     string
+		opts            CheckOptions
+		config          map[string]interface{}
+		serverSideApply bool
+		waitStrategy    kube.WaitStrategy
+		err             string
+	}
+
+	for _, tc := range []testCase{
+		{
+			description:  "defaults to helm/v3 behavior",
+			waitStrategy: kube.LegacyStrategy,
+		},
+		{
+			description:     "verifier options",
+			opts:            CheckOptions{ServerSideApply: true, WaitStrategy: "watcher"},
+			serverSideApply: true,
+			waitStrategy:    kube.StatusWatcherStrategy,
+		},
+		{
+			description:     "check settings take precedence",
+			opts:            CheckOptions{ServerSideApply: true, WaitStrategy: "watcher"},
+			config:          map[string]interface{}{ServerSideApplyConfigString: false, WaitStrategyConfigString: "hookOnly"},
+			serverSideApply: false,
+			waitStrategy:    kube.HookOnlyStrategy,
+		},
+		{
+			description:     "check setting aliases",
+			opts:            CheckOptions{WaitStrategy: "watcher"},
+			config:          map[string]interface{}{ServerSideApplyAliasString: true, WaitStrategyAliasString: "hookOnly"},
+			serverSideApply: true,
+			waitStrategy:    kube.HookOnlyStrategy,
+		},
+		{
+			description: "invalid wait strategy",
+			config:      map[string]interface{}{WaitStrategyConfigString: "eventually"},
+			err:         `invalid wait strategy "eventually"`,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.opts.ViperConfig = viper.New()
+			for key, value := range tc.config {
+				tc.opts.ViperConfig.Set(key, value)
+			}
+
+			serverSideApply, waitStrategy, err := getApplyOptions(&tc.opts)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.serverSideApply, serverSideApply)
+			require.Equal(t, tc.waitStrategy, waitStrategy)
+		})
+	}
+}
+
+func TestGetValuesFilesOptions(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		config      map[string]interface{}
+		bestEffort  bool
+		parallel    bool
+		err         string
+	}{
+		{
+			description: "defaults to fail fast",
+		},
+		{
+			description: "best effort",
+			config:      map[string]interface{}{BestEffortConfigString: true},
+			bestEffort:  true,
+		},
+		{
+			description: "parallel implies best effort",
+			config:      map[string]interface{}{ParallelConfigString: true},
+			bestEffort:  true,
+			parallel:    true,
+		},
+		{
+			description: "parallel without best effort",
+			config:      map[string]interface{}{ParallelConfigString: true, BestEffortConfigString: false},
+			err:         "parallel tests every values file and cannot be combined with bestEffort set to false",
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			opts := CheckOptions{ViperConfig: viper.New()}
+			for key, value := range tc.config {
+				opts.ViperConfig.Set(key, value)
 			}
+
+			bestEffort, parallel, err := getValuesFilesOptions(&opts)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.bestEffort, bestEffort)
+			require.Equal(t, tc.parallel, parallel)
 		})
 	}
 }
+
+func TestTestValuesFiles(t *testing.T) {
+	valuesFiles := []string{"ci/a-values.yaml", "ci/b-values.yaml", "ci/c-values.yaml"}
+	test := func(_ context.Context, _ config.Configuration, _ *tool.Helm, valuesFile string, _ releaseTestOptions) (apiReport.ValuesFileResult, error) {
+		if valuesFile == "ci/b-values.yaml" {
+			return apiReport.ValuesFileResult{ValuesFile: valuesFile, Outcome: apiReport.FailOutcomeType}, errors.New("b failed")
+		}
+		return apiReport.ValuesFileResult{ValuesFile: valuesFile, Outcome: apiReport.PassOutcomeType}, nil
+	}
+
+	t.Run("fail fast", func(t *testing.T) {
+		results, err := testValuesFiles(context.Background(), config.Configuration{}, nil, nil, nil, valuesFiles, releaseTestOptions{}, test)
+		require.EqualError(t, err, "b failed")
+		require.Len(t, results, 2)
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		results, err := testValuesFiles(context.Background(), config.Configuration{}, nil, nil, nil, valuesFiles, releaseTestOptions{bestEffort: true}, test)
+		require.EqualError(t, err, "b failed")
+		require.Len(t, results, 3)
+		require.Equal(t, apiReport.PassOutcomeType, results[2].Outcome)
+	})
+}
@@ -17,6 +17,8 @@
 package checks
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -24,17 +26,23 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/opdev/getocprange"
 	"helm.sh/helm/v4/pkg/action"
 
+	"helm.sh/helm/v4/pkg/chart/common"
 	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/lint"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/cache"
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/pyxis"
 	"github.com/redhat-certification/chart-verifier/internal/tool"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
 const (
@@ -61,6 +69,7 @@ const (
 	HelmLintHasFailedPrefix      = "Helm lint has failed: "
 	CSIObjectsExist              = "CSI objects exist"
 	CSIObjectsDoesNotExist       = "CSI objects do not exist"
+	ChartObjectsNotEvaluated     = "Unable to evaluate the chart objects"
 	NoImagesToCertify            = "No images to certify"
 	ImageCertifyFailed           = "Failed to certify images"
 	ImageCertified               = "Image is Red Hat certified"
@@ -77,9 +86,107 @@ const (
 	SignatureNoKey               = "Signature verification skipped, a public key was not specified"
 	ImageCertifySkipped          = "Image certification skipped"
 	RedHatRegistry               = "registry.redhat.io/"
+
+	InstallPermissionsGranted      = "All permissions needed to install the chart are granted"
+	InstallPermissionsMissing      = "Missing permissions needed to install the chart"
+	InstallPermissionsNotEvaluated = "Permissions needed to install the chart not evaluated"
+
+	KeywordsAreOpenShiftCategories    = "Keywords match OpenShift categories"
+	KeywordsAreNotOpenShiftCategories = "No keyword matches an OpenShift category, the chart is listed under Other in categories"
+	KeywordsAreNotCategories          = "Keywords that are not OpenShift categories"
+	KeywordsCategoriesFailure         = "Unable to check keywords against OpenShift categories"
+
+	NoManualPreRequisites           = "Chart objects only reference objects created by the chart or provided by the platform"
+	ManualPreRequisites             = "Chart objects reference objects that must be created before installing the chart"
+	ManualPreRequisitesNotEvaluated = "References to objects not created by the chart not evaluated"
+
+	InfraObjectsDoNotExist   = "Chart does not contain infrastructure plugins or drivers"
+	InfraObjectsExist        = "Chart contains infrastructure plugins or drivers"
+	InfraObjectsNotEvaluated = "Infrastructure plugins and drivers not evaluated"
+
+	APIVersionsAreSupported = "API versions of the chart objects are supported"
+	APIVersionsNotServed    = "Chart objects use API versions not served by the tested versions"
+	APIVersionsDeprecated   = "Warning: deprecated API version"
+	APIVersionsNotEvaluated = "API versions of the chart objects not evaluated"
+
+	ValuesSchemaFileNotValid   = "Values schema file is not valid"
+	ValuesMatchSchema          = "Values schema file exist and values match it"
+	ValuesDoNotMatchSchema     = "Values do not match the values schema"
+	ValuesNotDescribedBySchema = "Warning: values not described by the values schema"
+	ValuesSchemaNotEvaluated   = "Values not validated against the values schema"
+
+	WorkloadsRunWithSCC        = "Workloads can run with the SCC"
+	WorkloadsNeedPrivilegedSCC = "Workloads need a more privileged SCC than"
+	PodSecurityNotRestricted   = "Warning: workload doesn't meet the restricted Pod Security Standard"
+	PodSecurityNotEvaluated    = "Pod security of the workloads not evaluated"
+
+	BestPracticesFollowed     = "Containers follow the resources, probes and image best practices"
+	BestPracticesNotFollowed  = "Containers don't follow the resources, probes and image best practices"
+	BestPracticesNotEvaluated = "Resources, probes and image best practices not evaluated"
+
+	DependenciesAudited      = "Dependencies are vendored, locked and pinned, and subcharts pass the static checks"
+	DependenciesNotAudited   = "Dependencies or subcharts failed the audit"
+	DependenciesNotEvaluated = "Dependencies not audited"
+
+	MetadataValid    = "Chart metadata is complete and well formed"
+	MetadataNotValid = "Chart metadata is incomplete or not well formed"
+
+	ReadmeComplete     = "Chart has a README with the required sections and values"
+	ReadmeIncomplete   = "Chart README is incomplete"
+	ReadmeNotEvaluated = "Chart README not evaluated"
+
+	ChartTestHooksExist      = "Chart test hooks exist"
+	ChartTestHooksDoNotExist = "Chart does not render a Pod or Job with the helm.sh/hook: test annotation"
+	ChartTestHooksNotValid   = "Chart test hooks are not valid"
+	ChartTestTemplateNotHook = "Warning: test template does not render a test hook"
+	ChartTestsNotEvaluated   = "Chart test hooks not evaluated"
+
+	HelmLintNotEvaluated = "Helm lint not evaluated"
+)
+
+// Codes of the findings added to the results.
+const (
+	ImagesNotRenderedCode         = "images-not-rendered"
+	ImageEmptyCode                = "image-empty"
+	ImageNotCertifiedCode         = "image-not-certified"
+	ImageCertifyFailedCode        = "image-certification-failed"
+	ImageCertifySkippedCode       = "image-certification-skipped"
+	LintMessageCode               = "lint-message"
+	CRDTemplateCode               = "crd-template"
+	CRDFileCode                   = "crd-file"
+	ChartNotSignedCode            = "chart-not-signed"
+	SignatureNoKeyCode            = "signature-no-public-key"
+	SignatureNotVerifiedCode      = "signature-not-verified"
+	SignatureVerificationFailCode = "signature-verification-failed"
+)
+
+const (
+	imageRemediation     = "Use an image certified by Red Hat, see https://connect.redhat.com/partner-with-us/red-hat-container-certification"
+	crdRemediation       = "Remove the CRD from the chart and have an operator define it"
+	signatureRemediation = "Sign the chart with helm package --sign and check the public key matches the signing key"
+)
+
+// RequiredAnnotationsConfigString lists the annotations the chart must have,
+// charts.openshift.io/name by default.
+const RequiredAnnotationsConfigString string = "annotations"
+
+var requiredAnnotations = []string{"charts.openshift.io/name"}
+
+const (
+	// LintStrictConfigString fails helm-lint on warnings too when true.
+	LintStrictConfigString string = "strict"
+	// LintNamespaceConfigString is the namespace the chart is linted in,
+	// default by default.
+	LintNamespaceConfigString string = "namespace"
+	// LintCIValuesConfigString also lints the chart with each of its
+	// ci/*-values.yaml files when true.
+	LintCIValuesConfigString string = "ciValues"
+	// LintKubeVersionConfigString is the Kubernetes version the chart is
+	// linted against.
+	LintKubeVersionConfigString string = "kubeVersion"
 )
 
-var requiredAnnotations = [...]string{"charts.openshift.io/name"}
+const defaultLintNamespace = "default"
 
 func notImplemented() (Result, error) {
 	return Result{Ok: false}, errors.New("not implemented")
@@ -181,10 +288,6 @@ func ContainsValuesSchema(opts *CheckOptions) (Result, error) {
 	return r, nil
 }
 
-func KeywordsAreOpenshiftCategories(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
-
 func IsCommercialChart(opts *CheckOptions) (Result, error) {
 	return notImplemented()
 }
@@ -234,132 +337,148 @@ func NotContainCRDs(opts *CheckOptions) (Result, error) {
 		return NewResult(false, err.Error()), err
 	}
 
-	r := NewResult(true, ChartDoesNotContainCRDs)
-
-	// Check standard CRD directory in main chart and dependencies
-	if hasCRDObjects(c) {
-		r.Ok = false
-		r.SetResult(false, ChartContainCRDs)
-		return r, nil
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ChartObjectsNotEvaluated, err)), nil
 	}
 
-	// Check for CRDs in templates (main chart and dependencies)
-	if hasCRDInTemplates(c) {
-		r.Ok = false
-		r.SetResult(false, ChartContainCRDs)
-		return r, nil
+	r := NewResult(true, ChartDoesNotContainCRDs)
+	for _, obj := range objects {
+		if isGroupKind(obj.Unstructured, crdGroupKind) {
+			addReason(&r, ChartContainCRDs, fmt.Sprintf("%s in %s", obj, obj.Location()))
+			r.AddFinding(obj.finding(apiReport.ErrorSeverity, CRDTemplateCode, "CRD rendered from a template", crdRemediation))
+		}
 	}
 
-	// Check for CRDs in files (root directory of main chart and dependencies)
-	if hasCRDInFiles(c) {
-		r.Ok = false
-		r.SetResult(false, ChartContainCRDs)
-		return r, nil
+	// Files other than templates aren't installed, but CRDs shipped as
+	// files are meant to be created by hand before installing the chart.
+	for _, crd := range crdFiles(c) {
+		addReason(&r, ChartContainCRDs, crd)
+		r.AddFinding(apiReport.Finding{
+			Severity:    apiReport.ErrorSeverity,
+			Code:        CRDFileCode,
+			Message:     "CRD shipped as a file of the chart",
+			Path:        crd,
+			Remediation: crdRemediation,
+		})
 	}
 
 	return r, nil
 }
 
-func hasCRDObjects(c *chartv2.Chart) bool {
-	// Check main chart CRDs directory
-	if len(c.CRDObjects()) > 0 {
-		return true
-	}
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
 
-	// Recursively check dependencies' CRDs directories
-	for _, dep := range c.Dependencies() {
-		if hasCRDObjects(dep) {
-			return true
-		}
+// isGroupKind returns whether obj is of the gk kind. Objects without an
+// apiVersion, which can't be installed but may be fixed to be, are matched on
+// their kind only.
+func isGroupKind(obj unstructured.Unstructured, gk schema.GroupKind) bool {
+	if obj.GetAPIVersion() == "" {
+		return obj.GetKind() == gk.Kind
 	}
-
-	return false
+	return obj.GroupVersionKind().GroupKind() == gk
 }
 
-func hasCRDInTemplates(c *chartv2.Chart) bool {
-	// Check main chart templates
-	for _, f := range c.Templates {
-		if !strings.HasSuffix(f.Name, ".yaml") && !strings.HasSuffix(f.Name, ".yml") {
-			continue
-		}
-		if isCRDFile(f.Data) {
-			return true
-		}
+// addReason fails r with reason, on the first finding, and adds finding to
+// its reason.
+func addReason(r *Result, reason, finding string) {
+	if r.Ok {
+		r.SetResult(false, reason)
 	}
-
-	// Check dependency/subchart templates
-	for _, dep := range c.Dependencies() {
-		if hasCRDInTemplates(dep) {
-			return true
-		}
-	}
-
-	return false
+	r.AddResult(false, finding)
 }
 
-func isCRDFile(data []byte) bool {
-	// Split on YAML document separator for multi-doc files
-	docs := strings.Split(string(data), "\n---")
-	for _, doc := range docs {
-		for _, line := range strings.Split(doc, "\n") {
-			trimmed := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmed, "kind:") {
-				kind := strings.TrimSpace(strings.TrimPrefix(trimmed, "kind:"))
-				// Remove surrounding quotes if present (both single and double)
-				kind = strings.Trim(kind, "\"'")
-				if kind == "CustomResourceDefinition" {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
-
-func hasCRDInFiles(c *chartv2.Chart) bool {
-	// Check this chart's files (root directory)
+// crdFiles returns the CRDs of the files of c and its dependencies, except
+// the crds/ directories, installed by helm and found in the rendered
+// manifests.
+func crdFiles(c *chartv2.Chart) []string {
+	var crds []string
 	for _, f := range c.Files {
-		if !strings.HasSuffix(f.Name, ".yaml") && !strings.HasSuffix(f.Name, ".yml") {
+		if strings.HasPrefix(f.Name, "crds/") || !slices.Contains([]string{".yaml", ".yml", ".json"}, path.Ext(f.Name)) {
 			continue
 		}
-		if isCRDFile(f.Data) {
-			return true
+		// Files that aren't manifests are ignored.
+		objects, _ := decodeManifests(string(f.Data))
+		for _, obj := range objects {
+			if isGroupKind(obj, crdGroupKind) {
+				crds = append(crds, fmt.Sprintf("%s %q in %s", obj.GetKind(), obj.GetName(), path.Join(c.ChartFullPath(), f.Name)))
+			}
 		}
 	}
 
-	// Recursively check dependencies
 	for _, dep := range c.Dependencies() {
-		if hasCRDInFiles(dep) {
-			return true
-		}
+		crds = append(crds, crdFiles(dep)...)
 	}
 
-	return false
+	return crds
 }
 
 func HelmLint(opts *CheckOptions) (Result, error) {
-	_, p, err := LoadChartFromURI(opts)
+	c, p, err := LoadChartFromURI(opts)
 	if err != nil {
 		return NewResult(false, err.Error()), err
 	}
 
+	config := opts.ViperConfig
+	namespace := defaultLintNamespace
+	if configNamespace := config.GetString(LintNamespaceConfigString); configNamespace != "" {
+		namespace = configNamespace
+	}
+	var linterOptions []lint.LinterOption
+	if kubeVersion := config.GetString(LintKubeVersionConfigString); kubeVersion != "" {
+		parsedKubeVersion, err := common.ParseKubeVersion(kubeVersion)
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s: %v", HelmLintNotEvaluated, err)), nil
+		}
+		linterOptions = append(linterOptions, lint.WithKubeVersion(parsedKubeVersion))
+	}
+	valuesSets := []valuesSet{{values: opts.Values}}
+	if config.GetBool(LintCIValuesConfigString) {
+		if valuesSets, err = ciValuesSets(opts, c); err != nil {
+			return NewResult(false, fmt.Sprintf("%s: %v", HelmLintNotEvaluated, err)), nil
+		}
+	}
+	failSeverity := support.ErrorSev
+	if config.GetBool(LintStrictConfigString) {
+		failSeverity = support.WarningSev
+	}
+
 	r := NewResult(true, HelmLintSuccessful)
 	// TODO: When chart/v3 is released, consider whether it makes sense to use
 	// actions.Lint related APIs vs. chart/v3/lint (given we use chart/v2/lint
 	// here, but will need to support both.)
-	linter := lint.RunAll(p, opts.Values, "default")
-	if linter.HighestSeverity > support.WarningSev {
-		reason := ""
+	failed := false
+	reason := ""
+	for _, set := range valuesSets {
+		linter := lint.RunAll(p, set.values, namespace, linterOptions...)
+		failed = failed || linter.HighestSeverity >= failSeverity
 		for _, m := range linter.Messages {
+			r.AddFinding(lintFinding(m, set.file))
+			if set.file != "" {
+				reason += set.file + ": "
+			}
 			reason = reason + m.Error() + "\n"
 		}
+	}
+	if failed {
 		r.SetResult(false, fmt.Sprintf("%s %s", HelmLintHasFailedPrefix, reason))
 	}
 	return r, nil
 }
 
-func NotContainsInfraPluginsAndDrivers(opts *CheckOptions) (Result, error) {
-	return notImplemented()
+// lintFinding returns the finding of m, linted with valuesFile.
+func lintFinding(m support.Message, valuesFile string) apiReport.Finding {
+	severity := apiReport.InfoSeverity
+	switch m.Severity {
+	case support.WarningSev:
+		severity = apiReport.WarningSeverity
+	case support.ErrorSev:
+		severity = apiReport.ErrorSeverity
+	}
+	message := ""
+	if m.Err != nil {
+		message = m.Err.Error()
+	}
+	return apiReport.Finding{Severity: severity, Code: LintMessageCode, Message: message, Path: m.Path, ValuesFile: valuesFile}
 }
 
 func NotContainCSIObjects(opts *CheckOptions) (Result, error) {
@@ -367,30 +486,23 @@ func NotContainCSIObjects(opts *CheckOptions) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ChartObjectsNotEvaluated, err)), nil
+	}
+
 	r := NewResult(true, CSIObjectsDoesNotExist)
-	for _, f := range c.Templates {
-		if !strings.HasSuffix(f.Name, ".yaml") {
-			continue
-		}
-		for _, v := range strings.Split(string(f.Data), "\n") {
-			if strings.HasPrefix(v, "kind") {
-				if strings.TrimSpace(strings.Split(v, ":")[1]) == "CSIDriver" {
-					r.SetResult(false, CSIObjectsExist)
-				}
-			}
+	for _, obj := range objects {
+		if isGroupKind(obj.Unstructured, csiDriverGroupKind) {
+			addReason(&r, CSIObjectsExist, fmt.Sprintf("%s in %s", obj, obj.Location()))
 		}
 	}
 
 	return r, nil
 }
 
-func CanBeInstalledWithoutManualPreRequisites(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
-
-func CanBeInstalledWithoutClusterAdminPrivileges(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
+var csiDriverGroupKind = schema.GroupKind{Group: "storage.k8s.io", Kind: "CSIDriver"}
 
 func ImagesAreCertified(opts *CheckOptions) (Result, error) {
 	r := NewResult(true, "")
@@ -419,8 +531,13 @@ func RequiredAnnotationsPresent(opts *CheckOptions) (Result, error) {
 		return NewResult(false, MetadataFailure), nil
 	}
 
+	annotations := requiredAnnotations
+	if opts.ViperConfig.IsSet(RequiredAnnotationsConfigString) {
+		annotations = opts.ViperConfig.GetStringSlice(RequiredAnnotationsConfigString)
+	}
+
 	missingAnnotations := make([]string, 0)
-	for _, annotation := range requiredAnnotations {
+	for _, annotation := range annotations {
 		if _, ok := c.Metadata.Annotations[annotation]; !ok {
 			missingAnnotations = append(missingAnnotations, annotation)
 		}
@@ -439,7 +556,7 @@ func SignatureIsValid(opts *CheckOptions) (Result, error) {
 
 	chartURL, err := url.Parse(chartPath)
 	if err != nil {
-		return NewResult(false, fmt.Sprintf("Failed to parse chart location: %s", chartPath)), nil
+		return signatureResult(NewResult(false, fmt.Sprintf("Failed to parse chart location: %s", chartPath)), SignatureVerificationFailCode), nil
 	}
 	var provFile string
 	switch chartURL.Scheme {
@@ -449,77 +566,115 @@ func SignatureIsValid(opts *CheckOptions) (Result, error) {
 		} else if strings.HasSuffix(chartPath, ".tgz?raw=true") {
 			provFile = strings.Replace(chartPath, ".tgz?", ".tgz.prov?", 1)
 		} else {
-			return NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), nil
+			return signatureResult(NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), ChartNotSignedCode), nil
 		}
 		provFileURL, err := url.Parse(provFile)
 		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : Failed to parse prov file location: %s", SignatureFailure, provFile)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : Failed to parse prov file location: %s", SignatureFailure, provFile)), SignatureVerificationFailCode), nil
 		}
 		// #nosec G107
 		resp, err := http.Get(provFile)
 		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : get error was %v", SignatureFailure, err)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : get error was %v", SignatureFailure, err)), SignatureVerificationFailCode), nil
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode == 404 {
-			return NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), nil
+			return signatureResult(NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), ChartNotSignedCode), nil
 		} else if resp.StatusCode != 200 {
-			return NewResult(false, fmt.Sprintf("%s. get prov file response code was %d", SignatureFailure, resp.StatusCode)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s. get prov file response code was %d", SignatureFailure, resp.StatusCode)), SignatureVerificationFailCode), nil
 		}
 
-		downloadDir, err := os.UserCacheDir()
-		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : %s : error getting cache dir:  %v", ChartSigned, SignatureFailure, err)), nil
+		userCacheDir := getCacheDir(opts)
+		if userCacheDir == "" {
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : %s : error getting cache dir", ChartSigned, SignatureFailure)), SignatureVerificationFailCode), nil
+		}
+		downloadDir := cache.New(userCacheDir).EntryDir(cache.ProvEntry, provCacheKey(chartURL))
+		// #nosec G301
+		if err := os.MkdirAll(downloadDir, 0o755); err != nil {
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : %s : error creating cache dir:  %v", ChartSigned, SignatureFailure, err)), SignatureVerificationFailCode), nil
 		}
 
 		chartPath, err = downloadFile(chartURL, downloadDir)
 		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : %s. error downloading %s:  %v", ChartSigned, SignatureIsNotPresentSuccess, chartURL.String(), err)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : %s. error downloading %s:  %v", ChartSigned, SignatureIsNotPresentSuccess, chartURL.String(), err)), SignatureVerificationFailCode), nil
 		}
 		_, err = downloadFile(provFileURL, downloadDir)
 		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : %s. error downloading %s:  %v", ChartSigned, SignatureIsNotPresentSuccess, provFileURL.String(), err)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : %s. error downloading %s:  %v", ChartSigned, SignatureIsNotPresentSuccess, provFileURL.String(), err)), SignatureVerificationFailCode), nil
 		}
 	case "file", "":
 		if strings.HasSuffix(chartPath, ".tgz") {
 			provFile = chartPath + ".prov"
 			if _, err := os.Stat(provFile); err != nil {
-				return NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), nil
+				return signatureResult(NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), ChartNotSignedCode), nil
 			}
 		} else {
-			return NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), nil
+			return signatureResult(NewSkippedResult(fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess)), ChartNotSignedCode), nil
 		}
 	default:
-		return NewResult(false, fmt.Sprintf("%s: scheme %q not supported", SignatureFailure, chartURL.Scheme)), nil
+		return signatureResult(NewResult(false, fmt.Sprintf("%s: scheme %q not supported", SignatureFailure, chartURL.Scheme)), SignatureVerificationFailCode), nil
 	}
 
 	verify := action.NewVerify()
 	var keyringFilename string
 	if len(opts.PublicKeys) > 0 && len(opts.PublicKeys[0]) > 0 {
-		keryingDir := path.Join(getCacheDir(opts), "pgp")
+		keryingDir := cache.New(getCacheDir(opts)).EntryDir(cache.KeyringEntry, keyringCacheKey(opts.PublicKeys))
 		keyringFilename, err = tool.GetKeyRing(keryingDir, opts.PublicKeys)
 		if err != nil {
-			return NewResult(false, fmt.Sprintf("%s : %s : failed to create keyring : %v", ChartSigned, SignatureFailure, err)), nil
+			return signatureResult(NewResult(false, fmt.Sprintf("%s : %s : failed to create keyring : %v", ChartSigned, SignatureFailure, err)), SignatureVerificationFailCode), nil
 		}
 	} else {
-		return NewSkippedResult(fmt.Sprintf("%s : %s", ChartSigned, SignatureNoKey)), nil
+		return signatureResult(NewSkippedResult(fmt.Sprintf("%s : %s", ChartSigned, SignatureNoKey)), SignatureNoKeyCode), nil
 	}
 
 	if _, err := os.Stat(keyringFilename); err != nil {
-		return NewResult(false, fmt.Sprintf("%s : %s : failed to create keyring.", ChartSigned, SignatureFailure)), nil
+		return signatureResult(NewResult(false, fmt.Sprintf("%s : %s : failed to create keyring.", ChartSigned, SignatureFailure)), SignatureVerificationFailCode), nil
 	}
 	verify.Keyring = keyringFilename
 
 	_, err = verify.Run(chartPath)
 	if err != nil {
 		failureMsg := fmt.Sprintf("%s : %s : %v", ChartSigned, SignatureFailure, err)
-		return NewResult(false, failureMsg), nil
+		return signatureResult(NewResult(false, failureMsg), SignatureNotVerifiedCode), nil
 	}
 
 	return NewResult(true, fmt.Sprintf("%s : %s", ChartSigned, SignatureIsValidSuccess)), nil
 }
 
+// signatureResult returns r with a finding of code, the reason of r.
+func signatureResult(r Result, code string) Result {
+	finding := apiReport.Finding{Severity: apiReport.ErrorSeverity, Code: code, Message: r.Reason, Remediation: signatureRemediation}
+	if r.Skipped {
+		finding.Severity, finding.Remediation = apiReport.InfoSeverity, ""
+	}
+	r.AddFinding(finding)
+	return r
+}
+
+// provCacheKey returns the cache entry name under which a remote chart package
+// and its provenance file are downloaded.
+func provCacheKey(chartURL *url.URL) string {
+	sum := sha256.Sum256([]byte(chartURL.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// keyringCacheKey returns the cache entry name of the keyring built from the
+// given public keys.
+func keyringCacheKey(publicKeys []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(publicKeys, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// imageFinding returns the finding of image.
+func imageFinding(severity apiReport.FindingSeverity, code, image, message string) apiReport.Finding {
+	finding := apiReport.Finding{Severity: severity, Code: code, Message: message, Object: image}
+	if severity != apiReport.InfoSeverity {
+		finding.Remediation = imageRemediation
+	}
+	return finding
+}
+
 func parseImageReference(image string) pyxis.ImageReference {
 	imageRef := pyxis.ImageReference{}
 	imageParts := strings.Split(image, "/")
@@ -609,9 +764,22 @@ func certifyImages(r Result, opts *CheckOptions, registry string) Result {
 	images, err := getImageReferences(opts.URI, opts.Values, kubeVersionString)
 	if err != nil {
 		r.SetResult(false, fmt.Sprintf("%s : Failed to get images, error running helm template : %v", ImageCertifyFailed, err))
+		r.AddFinding(apiReport.Finding{
+			Severity:    apiReport.ErrorSeverity,
+			Code:        ImagesNotRenderedCode,
+			Message:     fmt.Sprintf("error running helm template: %v", err),
+			Remediation: "Run helm template on the chart, setting the values it requires with the chart-set flags",
+		})
 		return r
 	}
 
+	return certifyImageReferences(r, images, registry)
+}
+
+// certifyImageReferences adds to r whether each of images is certified.
+// Images of registry not found in pyxis are skipped.
+func certifyImageReferences(r Result, images []string, registry string) Result {
+	var err error
 	if len(images) == 0 {
 		r.SetResult(true, NoImagesToCertify)
 	} else {
@@ -619,6 +787,7 @@ func certifyImages(r Result, opts *CheckOptions, registry string) Result {
 			// skip to evaluate next image, if current image is an empty string
 			if strings.Trim(image, " ") == "" {
 				r.AddResult(false, "ImageCertify() = empty image found")
+				r.AddFinding(imageFinding(apiReport.ErrorSeverity, ImageEmptyCode, image, "empty image found"))
 				continue
 			}
 
@@ -627,22 +796,27 @@ func certifyImages(r Result, opts *CheckOptions, registry string) Result {
 				imageRef.Registries, err = pyxis.GetImageRegistries(imageRef.Repository)
 				if err != nil {
 					r.AddResult(false, fmt.Sprintf("%s : %s : %v", ImageNotCertified, image, err))
+					r.AddFinding(imageFinding(apiReport.ErrorSeverity, ImageCertifyFailedCode, image, fmt.Sprintf("error getting the registries of the image: %v", err)))
 				}
 			}
 
 			if len(imageRef.Registries) == 0 {
 				r.AddResult(false, fmt.Sprintf("%s : %s", ImageNotCertified, image))
+				r.AddFinding(imageFinding(apiReport.ErrorSeverity, ImageNotCertifiedCode, image, "repository of the image not found in a Red Hat certified registry"))
 			} else {
 				certified, checkImageErr := pyxis.IsImageInRegistry(imageRef)
 				if !certified {
 					if strings.Contains(checkImageErr.Error(), "No images found for Registry/Repository") && registry != "" {
 						if strings.HasPrefix(image, registry) {
 							r.SetSkipped(fmt.Sprintf("%s : %s", ImageCertifySkipped, image))
+							r.AddFinding(imageFinding(apiReport.InfoSeverity, ImageCertifySkippedCode, image, fmt.Sprintf("image of %s not found, certification skipped", registry)))
 						} else {
 							r.AddResult(false, fmt.Sprintf("%s : %s", ImageNotCertified, image))
+							r.AddFinding(imageFinding(apiReport.ErrorSeverity, ImageNotCertifiedCode, image, "image not found in a Red Hat certified registry"))
 						}
 					} else {
 						r.AddResult(false, fmt.Sprintf("%s : %s : %v", ImageCertifyFailed, image, checkImageErr))
+						r.AddFinding(imageFinding(apiReport.ErrorSeverity, ImageCertifyFailedCode, image, checkImageErr.Error()))
 					}
 				} else {
 					r.AddResult(true, fmt.Sprintf("%s : %s", ImageCertified, image))
@@ -18,6 +18,9 @@ package checks
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -27,8 +30,20 @@ import (
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/pyxis"
 	"github.com/redhat-certification/chart-verifier/internal/tool"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
+// writeTestChart writes files, keyed by their path in the chart, to a chart
+// directory named name and returns its path.
+func writeTestChart(t *testing.T, name string, files map[string]string) string {
+	dir := filepath.Join(t.TempDir(), name)
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o600))
+	}
+	return dir
+}
+
 func TestIsHelmV3(t *testing.T) {
 	type testCase struct {
 		description string
@@ -311,13 +326,40 @@ func TestNotContainCRDs(t *testing.T) {
 		})
 	}
 
-	negativeTestCases := []testCase{
-		{description: "Contain CRDs", uri: "chart-0.1.0-v3.with-crd.tgz"},
-		{description: "Contain CRDs in /templates", uri: "chart-0.1.0-v3.with-crd-in-templates.tgz"},
-		{description: "Contain CRDs in root", uri: "chart-0.1.0-v3.with-crd-in-root.tgz"},
-		{description: "Contain CRDs in /charts", uri: "chart-0.1.0-v3.with-crd-in-charts.tgz"},
-		{description: "Contain CRDs in subchart /crds", uri: "chart-0.1.0-v3.with-crd-in-subchart-crds.tgz"},
-		{description: "Contain CRDs with quoted kind values", uri: "chart-0.1.0-v3.with-crd-quoted-kind.tgz"},
+	for _, tc := range []testCase{
+		{description: "CRD kind in a disabled block", uri: renderedKindsChart(t, "CustomResourceDefinition", false)},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := NotContainCRDs(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.True(t, r.Ok)
+			require.Equal(t, ChartDoesNotContainCRDs, r.Reason)
+		})
+	}
+
+	type negativeTestCase struct {
+		description string
+		uri         string
+		crds        []string
+	}
+
+	negativeTestCases := []negativeTestCase{
+		{description: "Contain CRDs", uri: "chart-0.1.0-v3.with-crd.tgz", crds: []string{`CustomResourceDefinition "backservs.service.example.com" in crds/backend.yaml`}},
+		{description: "Contain CRDs in /templates", uri: "chart-0.1.0-v3.with-crd-in-templates.tgz", crds: []string{`CustomResourceDefinition "backservs.service.example.com" in testchart/templates/backend.yaml`}},
+		{description: "Contain CRDs in root", uri: "chart-0.1.0-v3.with-crd-in-root.tgz", crds: []string{`CustomResourceDefinition "test.example.com" in testchart/backend.yaml`}},
+		{description: "Contain CRDs in /charts", uri: "chart-0.1.0-v3.with-crd-in-charts.tgz", crds: []string{`CustomResourceDefinition "backservs.service.example.com" in testchart/charts/crdchart/backend.yaml`}},
+		{description: "Contain CRDs in subchart /crds", uri: "chart-0.1.0-v3.with-crd-in-subchart-crds.tgz", crds: []string{`CustomResourceDefinition "test.example.com" in crds/mycrd.yaml`}},
+		{description: "Contain CRDs with quoted kind values", uri: "chart-0.1.0-v3.with-crd-quoted-kind.tgz", crds: []string{`CustomResourceDefinition "test.example.com" in testchart/charts/subchart/files/mycrd.yaml`}},
+		{
+			description: "Contain CRDs with templated, flow-style and JSON kinds",
+			uri:         renderedKindsChart(t, "CustomResourceDefinition", true),
+			crds: []string{
+				`CustomResourceDefinition "flow" in kinds/templates/flow.yaml`,
+				`CustomResourceDefinition "json" in kinds/templates/json.yaml`,
+				`CustomResourceDefinition "templated" in kinds/templates/templated.yaml`,
+				`CustomResourceDefinition "ci" in kinds/templates/ci.yaml (with ci/enabled-values.yaml)`,
+			},
+		},
 	}
 
 	for _, tc := range negativeTestCases {
@@ -327,11 +369,39 @@ func TestNotContainCRDs(t *testing.T) {
 			require.NoError(t, err)
 			require.NotNil(t, r)
 			require.False(t, r.Ok)
-			require.Equal(t, ChartContainCRDs, r.Reason)
+			require.Equal(t, strings.Join(append([]string{ChartContainCRDs}, tc.crds...), "\n"), r.Reason)
+			require.Len(t, r.Findings, len(tc.crds))
+			for _, finding := range r.Findings {
+				require.Equal(t, apiReport.ErrorSeverity, finding.Severity)
+				require.Contains(t, []string{CRDTemplateCode, CRDFileCode}, finding.Code)
+				require.NotEmpty(t, finding.Path)
+			}
 		})
 	}
 }
 
+// renderedKindsChart returns the path of a chart with objects of kind in
+// templates only rendering kind when it is templated, written in flow-style
+// YAML or in JSON, commented out or disabled, or enabled by a CI values file.
+// When enabled is false, only the commented out and disabled objects are
+// included.
+func renderedKindsChart(t *testing.T, kind string, enabled bool) string {
+	files := map[string]string{
+		"Chart.yaml":              "apiVersion: v2\nname: kinds\nversion: 0.1.0\n",
+		"values.yaml":             fmt.Sprintf("kind: %s\nenabled: false\n", kind),
+		"templates/comment.yaml":  fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: comment\n# kind: %s\n", kind),
+		"templates/disabled.yaml": fmt.Sprintf("{{- if .Values.enabled }}\napiVersion: v1\nkind: %s\nmetadata:\n  name: disabled\n{{- end }}\n", kind),
+	}
+	if enabled {
+		files["templates/templated.yaml"] = "apiVersion: apiextensions.k8s.io/v1\nkind: {{ .Values.kind }}\nmetadata:\n  name: templated\n"
+		files["templates/flow.yaml"] = fmt.Sprintf("{apiVersion: apiextensions.k8s.io/v1, kind: %s, metadata: {name: flow}}\n", kind)
+		files["templates/json.yaml"] = fmt.Sprintf(`{"apiVersion": "apiextensions.k8s.io/v1", "kind": %q, "metadata": {"name": "json"}}`, kind)
+		files["templates/ci.yaml"] = fmt.Sprintf("{{- if .Values.ci }}\napiVersion: apiextensions.k8s.io/v1\nkind: %s\nmetadata:\n  name: ci\n{{- end }}\n", kind)
+		files["ci/enabled-values.yaml"] = "ci: true\n"
+	}
+	return writeTestChart(t, "kinds", files)
+}
+
 func TestNotContainCSIObjects(t *testing.T) {
 	type testCase struct {
 		description string
@@ -353,6 +423,13 @@ func TestNotContainCSIObjects(t *testing.T) {
 		})
 	}
 
+	t.Run("CSIDriver kind in a disabled block", func(t *testing.T) {
+		r, err := NotContainCSIObjects(&CheckOptions{URI: renderedKindsChart(t, "CSIDriver", false), ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+		require.NoError(t, err)
+		require.True(t, r.Ok)
+		require.Equal(t, CSIObjectsDoesNotExist, r.Reason)
+	})
+
 	negativeTestCases := []testCase{
 		{description: "Contain CRDs", uri: "chart-0.1.0-v3.with-csi.tgz"},
 	}
@@ -364,7 +441,7 @@ func TestNotContainCSIObjects(t *testing.T) {
 			require.NoError(t, err)
 			require.NotNil(t, r)
 			require.False(t, r.Ok)
-			require.Equal(t, CSIObjectsExist, r.Reason)
+			require.Equal(t, CSIObjectsExist+"\n"+`CSIDriver "mycsidriver.example.com" in chart/templates/csidriver.yaml`, r.Reason)
 		})
 	}
 }
@@ -404,8 +481,63 @@ func TestHelmLint(t *testing.T) {
 			require.NotNil(t, r)
 			require.False(t, r.Ok)
 			require.Contains(t, r.Reason, HelmLintHasFailedPrefix)
+			require.True(t, slices.ContainsFunc(r.Findings, func(f apiReport.Finding) bool {
+				return f.Severity == apiReport.ErrorSeverity && f.Code == LintMessageCode
+			}))
+		})
+	}
+}
+
+func TestHelmLintConfig(t *testing.T) {
+	dir := writeTestChart(t, "demo", map[string]string{
+		"Chart.yaml":                "apiVersion: v2\nname: demo\nversion: 0.1.0\nicon: https://example.com/icon.png\n",
+		"values.yaml":               "name: demo\n",
+		"ci/empty-name-values.yaml": "name: \"\"\n",
+		"templates/configmap.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\ndata:\n  name: {{ .Values.name | default \"[\" }}\n",
+	})
+
+	type testCase struct {
+		description string
+		uri         string
+		config      map[string]interface{}
+		ok          bool
+	}
+
+	testCases := []testCase{
+		{description: "warnings pass", uri: "chart-0.1.0-v2.lint-warning.tgz", ok: true},
+		{description: "warnings fail in strict mode", uri: "chart-0.1.0-v2.lint-warning.tgz", config: map[string]interface{}{LintStrictConfigString: true}, ok: false},
+		{description: "CI values files are not linted by default", uri: dir, ok: true},
+		{description: "CI values files are linted", uri: dir, config: map[string]interface{}{LintCIValuesConfigString: true}, ok: false},
+		{description: "namespace and kube version", uri: dir, config: map[string]interface{}{LintNamespaceConfigString: "demo", LintKubeVersionConfigString: "v1.30.0"}, ok: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			r, err := HelmLint(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok, r.Reason)
 		})
 	}
+
+	config := viper.New()
+	config.Set(LintCIValuesConfigString, true)
+	r, err := HelmLint(&CheckOptions{URI: dir, ViperConfig: config, HelmEnvSettings: cli.New()})
+	require.NoError(t, err)
+	require.Contains(t, r.Reason, "ci/empty-name-values.yaml: [ERROR] templates/configmap.yaml:")
+	require.True(t, slices.ContainsFunc(r.Findings, func(f apiReport.Finding) bool {
+		return f.Severity == apiReport.ErrorSeverity && f.Path == "templates/configmap.yaml" && f.ValuesFile == "ci/empty-name-values.yaml" && f.Message != ""
+	}))
+
+	config = viper.New()
+	config.Set(LintKubeVersionConfigString, "not-a-version")
+	r, err = HelmLint(&CheckOptions{URI: dir, ViperConfig: config, HelmEnvSettings: cli.New()})
+	require.NoError(t, err)
+	require.False(t, r.Ok)
+	require.Contains(t, r.Reason, HelmLintNotEvaluated)
 }
 
 func TestImageCertifyKubeVersionConstraints(t *testing.T) {
@@ -626,6 +758,15 @@ func TestRequiredAnnotationsPresent(t *testing.T) {
 			require.Equal(t, message, r.Reason)
 		})
 	}
+
+	t.Run("chart with missing configured annotations", func(t *testing.T) {
+		config := viper.New()
+		config.Set(RequiredAnnotationsConfigString, []string{"charts.openshift.io/provider", "example.com/team"})
+		r, err := RequiredAnnotationsPresent(&CheckOptions{URI: "chart-0.1.0-v3.missing-annotations.tgz", ViperConfig: config, HelmEnvSettings: cli.New()})
+		require.NoError(t, err)
+		require.False(t, r.Ok)
+		require.Equal(t, RequiredAnnotationsFailure+": [example.com/team]", r.Reason)
+	})
 }
 
 func TestSignatureIsValid(t *testing.T) {
@@ -636,6 +777,7 @@ func TestSignatureIsValid(t *testing.T) {
 		reason      string
 		ok          bool
 		skipped     bool
+		code        string
 	}
 
 	testCases := []testCase{
@@ -644,6 +786,7 @@ func TestSignatureIsValid(t *testing.T) {
 			uri:         "chart-0.1.0-v3.no-missing-annotations.tgz",
 			keyFile:     "",
 			reason:      fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess),
+			code:        ChartNotSignedCode,
 			ok:          true, skipped: true,
 		},
 		{
@@ -651,6 +794,7 @@ func TestSignatureIsValid(t *testing.T) {
 			uri:         "chart-0.1.0-v3.no-missing-annotations.tgz",
 			keyFile:     "../../../tests/charts/psql-service/0.1.11/psql-service-0.1.11.tgz.key",
 			reason:      fmt.Sprintf("%s : %s", ChartNotSigned, SignatureIsNotPresentSuccess),
+			code:        ChartNotSignedCode,
 			ok:          true, skipped: true,
 		},
 
@@ -666,6 +810,7 @@ func TestSignatureIsValid(t *testing.T) {
 			uri:         "https://github.com/redhat-certification/chart-verifier/blob/main/tests/charts/psql-service/0.1.11/psql-service-0.1.11.tgz?raw=true",
 			keyFile:     "",
 			reason:      fmt.Sprintf("%s : %s", ChartSigned, SignatureNoKey),
+			code:        SignatureNoKeyCode,
 			ok:          true, skipped: true,
 		},
 		{
@@ -673,6 +818,7 @@ func TestSignatureIsValid(t *testing.T) {
 			uri:         "../../../tests/charts/psql-service/0.1.11/psql-service-0.1.11.tgz",
 			keyFile:     "../../../tests/charts/psql-service/0.1.11/psql-service-0.1.11.tgz.badkey",
 			reason:      fmt.Sprintf("%s : %s", ChartSigned, SignatureFailure),
+			code:        SignatureNotVerifiedCode,
 			ok:          false, skipped: false,
 		},
 	}
@@ -694,6 +840,13 @@ func TestSignatureIsValid(t *testing.T) {
 			require.Equal(t, r.Ok, tc.ok, fmt.Sprintf("%s : outcome mismatch", tc.description))
 			require.Equal(t, r.Skipped, tc.skipped, fmt.Sprintf("%s : skipped mismatch", tc.description))
 			require.Contains(t, r.Reason, tc.reason, fmt.Sprintf("%s : reason mismatch", tc.description))
+			if tc.code == "" {
+				require.Empty(t, r.Findings)
+			} else {
+				require.Len(t, r.Findings, 1)
+				require.Equal(t, tc.code, r.Findings[0].Code)
+				require.Equal(t, r.Reason, r.Findings[0].Message)
+			}
 		})
 	}
 }
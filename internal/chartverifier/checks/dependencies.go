@@ -0,0 +1,183 @@
+package checks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/spf13/viper"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+
+	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+)
+
+// AllowedRepositoriesConfigString lists the URL prefixes of the repositories
+// dependencies may be pulled from, any HTTPS or OCI repository when unset.
+const AllowedRepositoriesConfigString string = "allowedRepositories"
+
+// subchartCheckNames are the checks of the profile applied to the subcharts.
+// They only look at the files of a chart, the checks rendering it already
+// cover the subcharts through their parent.
+var subchartCheckNames = []apiChecks.CheckName{
+	apiChecks.IsHelmV3,
+	apiChecks.HasReadme,
+	apiChecks.ContainsValues,
+	apiChecks.ContainsValuesSchema,
+	apiChecks.HelmLint,
+}
+
+// DependenciesAreAudited checks that the dependencies of the chart are vendored
+// in charts/ and locked by Chart.lock, are pinned, and come from HTTPS or OCI
+// repositories of the allowlist, and applies the static checks of the
+// profile to the subcharts.
+func DependenciesAreAudited(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	r := NewResult(true, DependenciesAudited)
+	for _, finding := range lockFindings(c) {
+		addReason(&r, DependenciesNotAudited, finding)
+	}
+	allowed := opts.ViperConfig.GetStringSlice(AllowedRepositoriesConfigString)
+	for _, dep := range c.Metadata.Dependencies {
+		if !exactVersion(dep.Version) {
+			addReason(&r, DependenciesNotAudited, fmt.Sprintf("dependency %q: version %q is not pinned", dep.Name, dep.Version))
+		}
+		if finding := repositoryFinding(dep.Repository, allowed); finding != "" {
+			addReason(&r, DependenciesNotAudited, fmt.Sprintf("dependency %q: %s", dep.Name, finding))
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "subcharts-*")
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", DependenciesNotEvaluated, err)), nil
+	}
+	defer os.RemoveAll(dir)
+	if err := auditSubcharts(opts, c, c.Name(), dir, &r); err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", DependenciesNotEvaluated, err)), nil
+	}
+	return r, nil
+}
+
+// lockFindings returns the dependencies of c that aren't vendored in charts/
+// or don't match Chart.lock.
+func lockFindings(c *chartv2.Chart) []string {
+	deps := c.Metadata.Dependencies
+	if len(deps) == 0 {
+		return nil
+	}
+
+	var findings []string
+	vendored := map[string]*chartv2.Chart{}
+	for _, sub := range c.Dependencies() {
+		vendored[sub.Name()] = sub
+	}
+	if c.Lock == nil {
+		findings = append(findings, "Chart.lock not found")
+	} else if digest, err := lockDigest(deps, c.Lock.Dependencies); err != nil || digest != c.Lock.Digest {
+		findings = append(findings, fmt.Sprintf("Chart.lock digest %s doesn't match the Chart.yaml dependencies", c.Lock.Digest))
+	}
+	for _, dep := range deps {
+		sub, found := vendored[dep.Name]
+		if !found {
+			findings = append(findings, fmt.Sprintf("dependency %q not vendored in charts/", dep.Name))
+			continue
+		}
+		if c.Lock == nil {
+			continue
+		}
+		i := slices.IndexFunc(c.Lock.Dependencies, func(locked *chartv2.Dependency) bool { return locked.Name == dep.Name })
+		if i < 0 {
+			findings = append(findings, fmt.Sprintf("dependency %q not locked in Chart.lock", dep.Name))
+		} else if locked := c.Lock.Dependencies[i]; locked.Version != sub.Metadata.Version {
+			findings = append(findings, fmt.Sprintf("dependency %q: vendored version %s doesn't match the locked version %s", dep.Name, sub.Metadata.Version, locked.Version))
+		}
+	}
+	return findings
+}
+
+// lockDigest returns the digest helm computes for the Chart.lock of the deps
+// locked at the lock versions.
+func lockDigest(deps, lock []*chartv2.Dependency) (string, error) {
+	// Rendering the chart flags the enabled dependencies, which helm doesn't
+	// hash.
+	req := make([]*chartv2.Dependency, len(deps))
+	for i, dep := range deps {
+		d := *dep
+		d.Enabled = false
+		req[i] = &d
+	}
+	data, err := json.Marshal([2][]*chartv2.Dependency{req, lock})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// exactVersion returns whether version is a full semantic version. semver
+// parses partial versions such as 1.2, which helm resolves as ranges.
+func exactVersion(version string) bool {
+	v, err := semver.NewVersion(version)
+	return err == nil && strings.TrimPrefix(version, "v") == v.String()
+}
+
+// repositoryFinding returns why a dependency can't be pulled from repository,
+// empty when it can. Charts vendored without a repository or from a file://
+// path are always allowed.
+func repositoryFinding(repository string, allowed []string) string {
+	switch {
+	case repository == "" || strings.HasPrefix(repository, "file://"):
+		return ""
+	case strings.HasPrefix(repository, "@") || strings.HasPrefix(repository, "alias:"):
+		return fmt.Sprintf("repository %q referenced by name", repository)
+	case !strings.HasPrefix(repository, "https://") && !strings.HasPrefix(repository, "oci://"):
+		return fmt.Sprintf("repository %q is not HTTPS", repository)
+	case len(allowed) > 0 && !slices.ContainsFunc(allowed, func(prefix string) bool { return strings.HasPrefix(repository, prefix) }):
+		return fmt.Sprintf("repository %q is not allowed", repository)
+	}
+	return ""
+}
+
+// auditSubcharts applies the static checks of the profile to the subcharts
+// of c, recursively, saving them under dir. Findings name the subchart by
+// its path from the chart, e.g. chart/redis/common.
+func auditSubcharts(opts *CheckOptions, c *chartv2.Chart, path, dir string, r *Result) error {
+	for _, sub := range c.Dependencies() {
+		subPath := path + "/" + sub.Name()
+		subDir := filepath.Join(dir, subPath)
+		if err := chartutil.SaveDir(sub, filepath.Dir(subDir)); err != nil {
+			return fmt.Errorf("error saving subchart %s: %w", subPath, err)
+		}
+		for _, check := range opts.ProfileChecks {
+			if !slices.Contains(subchartCheckNames, check.CheckID.Name) || check.Func == nil {
+				continue
+			}
+			subResult, err := check.Func(&CheckOptions{
+				URI:             subDir,
+				ViperConfig:     viper.New(),
+				HelmEnvSettings: opts.HelmEnvSettings,
+			})
+			if err != nil {
+				return fmt.Errorf("error checking subchart %s: %w", subPath, err)
+			}
+			if !subResult.Ok {
+				reason := strings.ReplaceAll(strings.TrimSpace(subResult.Reason), "\n", "; ")
+				addReason(r, DependenciesNotAudited, fmt.Sprintf("subchart %s: %s: %s", subPath, check.CheckID.Name, reason))
+			}
+		}
+		if err := auditSubcharts(opts, sub, subPath, dir, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/cli"
+
+	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+)
+
+// redisLockDigest is the Chart.lock digest helm writes for the dependency of
+// the dependenciesChart charts.
+const redisLockDigest = "sha256:415140b8fe8afdf6bc03354ce52c69a9d3d2dda1b59465a412e1151196a600c5"
+
+// dependenciesChart writes a chart depending on redis 1.0.0 with files, and
+// returns its directory. Files with "-" as content are left out.
+func dependenciesChart(t *testing.T, files map[string]string) string {
+	chart := map[string]string{
+		"Chart.yaml":               "apiVersion: v2\nname: parent\nversion: 0.1.0\ndependencies:\n  - name: redis\n    version: 1.0.0\n    repository: https://charts.example.com\n",
+		"values.yaml":              "replicaCount: 1\n",
+		"charts/redis/Chart.yaml":  "apiVersion: v2\nname: redis\nversion: 1.0.0\n",
+		"charts/redis/values.yaml": "port: 6379\n",
+		"charts/redis/README.md":   "# redis\n",
+	}
+	for name, content := range files {
+		if content == "-" {
+			delete(chart, name)
+		} else {
+			chart[name] = content
+		}
+	}
+	return writeTestChart(t, "parent", chart)
+}
+
+func TestLockDigest(t *testing.T) {
+	deps := []*chartv2.Dependency{{Name: "redis", Version: "1.0.0", Repository: "https://charts.example.com", Enabled: true}}
+	lock := []*chartv2.Dependency{{Name: "redis", Version: "1.0.0", Repository: "https://charts.example.com"}}
+	digest, err := lockDigest(deps, lock)
+	require.NoError(t, err)
+	require.Equal(t, redisLockDigest, digest)
+	require.True(t, deps[0].Enabled)
+}
+
+func TestExactVersion(t *testing.T) {
+	for version, pinned := range map[string]bool{
+		"1.0.0":        true,
+		"v1.0.0":       true,
+		"1.0.0-rc.1":   true,
+		"1.0":          false,
+		"~1.0.0":       false,
+		">=1.0.0 <2.0": false,
+		"1.x":          false,
+		"":             false,
+	} {
+		require.Equal(t, pinned, exactVersion(version), version)
+	}
+}
+
+func TestRepositoryFinding(t *testing.T) {
+	allowed := []string{"https://charts.example.com/", "oci://quay.io/example/"}
+	for repository, expected := range map[string]string{
+		"":                                  "",
+		"file://../redis":                   "",
+		"https://charts.example.com/stable": "",
+		"oci://quay.io/example/charts":      "",
+		"http://charts.example.com/stable":  `repository "http://charts.example.com/stable" is not HTTPS`,
+		"@stable":                           `repository "@stable" referenced by name`,
+		"https://charts.bitnami.com":        `repository "https://charts.bitnami.com" is not allowed`,
+	} {
+		require.Equal(t, expected, repositoryFinding(repository, allowed), repository)
+	}
+	require.Equal(t, "", repositoryFinding("https://charts.bitnami.com", nil))
+}
+
+func TestDependenciesAreAudited(t *testing.T) {
+	type testCase struct {
+		description string
+		files       map[string]string
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	lock := "dependencies:\n- name: redis\n  repository: https://charts.example.com\n  version: 1.0.0\ndigest: " + redisLockDigest + "\ngenerated: \"2026-01-01T00:00:00Z\"\n"
+	profileChecks := []Check{
+		{CheckID: CheckID{Name: apiChecks.HasReadme, Version: "v1.0"}, Func: HasReadme},
+		{CheckID: CheckID{Name: apiChecks.ContainsValuesSchema, Version: "v1.0"}, Func: ContainsValuesSchema},
+		{CheckID: CheckID{Name: apiChecks.HasKubeVersion, Version: "v1.0"}, Func: HasKubeVersion},
+	}
+
+	testCases := []testCase{
+		{
+			description: "dependencies are vendored and locked",
+			files:       map[string]string{"Chart.lock": lock, "charts/redis/values.schema.json": "{}"},
+			ok:          true,
+			reason:      DependenciesAudited,
+		},
+		{
+			description: "dependency not vendored nor locked",
+			files:       map[string]string{"charts/redis/Chart.yaml": "-", "charts/redis/values.yaml": "-", "charts/redis/README.md": "-"},
+			ok:          false,
+			reason:      DependenciesNotAudited + "\nChart.lock not found\n" + `dependency "redis" not vendored in charts/`,
+		},
+		{
+			description: "vendored version doesn't match the lock",
+			files: map[string]string{
+				"Chart.lock":                      lock,
+				"charts/redis/Chart.yaml":         "apiVersion: v2\nname: redis\nversion: 1.1.0\n",
+				"charts/redis/values.schema.json": "{}",
+			},
+			ok:     false,
+			reason: DependenciesNotAudited + "\n" + `dependency "redis": vendored version 1.1.0 doesn't match the locked version 1.0.0`,
+		},
+		{
+			description: "unpinned dependency from an HTTP repository",
+			files: map[string]string{
+				"Chart.yaml":                      "apiVersion: v2\nname: parent\nversion: 0.1.0\ndependencies:\n  - name: redis\n    version: ~1.0.0\n    repository: http://charts.example.com\n",
+				"Chart.lock":                      lock,
+				"charts/redis/values.schema.json": "{}",
+			},
+			ok: false,
+			reason: DependenciesNotAudited +
+				"\nChart.lock digest " + redisLockDigest + " doesn't match the Chart.yaml dependencies" +
+				"\n" + `dependency "redis": version "~1.0.0" is not pinned` +
+				"\n" + `dependency "redis": repository "http://charts.example.com" is not HTTPS`,
+		},
+		{
+			description: "dependency from a repository not allowed",
+			files:       map[string]string{"Chart.lock": lock, "charts/redis/values.schema.json": "{}"},
+			config:      map[string]interface{}{AllowedRepositoriesConfigString: []string{"oci://quay.io/example/"}},
+			ok:          false,
+			reason:      DependenciesNotAudited + "\n" + `dependency "redis": repository "https://charts.example.com" is not allowed`,
+		},
+		{
+			description: "subcharts fail the static checks",
+			files: map[string]string{
+				"Chart.lock":                             lock,
+				"charts/redis/README.md":                 "-",
+				"charts/redis/charts/common/Chart.yaml":  "apiVersion: v2\nname: common\nversion: 2.0.0\n",
+				"charts/redis/charts/common/README.md":   "# common\n",
+				"charts/redis/charts/common/values.yaml": "{}\n",
+			},
+			ok: false,
+			reason: DependenciesNotAudited +
+				"\nsubchart parent/redis: has-readme: " + ReadmeDoesNotExist +
+				"\nsubchart parent/redis: contains-values-schema: " + ValuesSchemaFileDoesNotExist +
+				"\nsubchart parent/redis/common: contains-values-schema: " + ValuesSchemaFileDoesNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			uri := dependenciesChart(t, tc.files)
+			r, err := DependenciesAreAudited(&CheckOptions{URI: uri, ViperConfig: config, HelmEnvSettings: cli.New(), ProfileChecks: profileChecks})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
@@ -17,6 +17,10 @@
 package checks
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
@@ -28,6 +32,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 
 	"helm.sh/helm/v4/pkg/chart/common"
 	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
@@ -39,27 +44,62 @@ import (
 	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/cache"
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
 	"github.com/redhat-certification/chart-verifier/internal/helm/actions"
 )
 
-// loadChartFromRemote attempts to retrieve a Helm chart from the given remote url. Returns an error if the given url
-// doesn't contain the 'http' or 'https' schema, or any other error related to retrieving the contents of the chart.
-func loadChartFromRemote(url *url.URL) (*chartv2.Chart, error) {
+// remoteValidators are the HTTP cache validators returned along with a remote
+// chart package, used to check whether a cached copy is still fresh.
+type remoteValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// fetchChartPackage retrieves the chart package at the given remote url. If
+// the server reports that the package has not changed since validators were
+// issued, notModified is true and no package is returned. Returns an error if
+// the given url doesn't contain the 'http' or 'https' schema, or any other
+// error related to retrieving the contents of the chart.
+func fetchChartPackage(url *url.URL, validators remoteValidators) (pkg []byte, latest remoteValidators, notModified bool, err error) {
 	if url.Scheme != "http" && url.Scheme != "https" {
-		return nil, fmt.Errorf("only 'http' and 'https' schemes are supported, but got %q", url.Scheme)
+		return nil, latest, false, fmt.Errorf("only 'http' and 'https' schemes are supported, but got %q", url.Scheme)
 	}
 
-	resp, err := http.Get(url.String())
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, latest, false, err
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, latest, false, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, ChartNotFoundErr(url.String())
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, validators, true, nil
+	case http.StatusNotFound:
+		return nil, latest, false, ChartNotFoundErr(url.String())
+	}
+
+	pkg, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, latest, false, err
 	}
 
-	return loaderv2.LoadArchive(resp.Body)
+	latest = remoteValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return pkg, latest, false, nil
 }
 
 // loadChartFromAbsPath attempts to retrieve a local Helm chart by resolving the maybe relative path into an absolute
@@ -85,18 +125,60 @@ func loadChartFromAbsPath(path string) (*chartv2.Chart, error) {
 	return c, nil
 }
 
+// packageDigest returns the hex-encoded SHA256 digest of a chart package.
+func packageDigest(pkg []byte) string {
+	sum := sha256.Sum256(pkg)
+	return hex.EncodeToString(sum[:])
+}
+
+// localChartDigest returns the digest of the chart at the given path. Chart
+// archives are hashed as is; for chart directories, which have no package,
+// the digest is computed over the chart's files sorted by name.
+func localChartDigest(path string, chrt *chartv2.Chart) (string, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		// #nosec G304
+		pkg, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return packageDigest(pkg), nil
+	}
+
+	files := slices.Clone(chrt.Raw)
+	slices.SortFunc(files, func(a, b *common.File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	h := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00", f.Name, len(f.Data))
+		h.Write(f.Data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 type ChartCache interface {
-	MakeKey(uri string) string
-	Add(uri string, chrt *chartv2.Chart) (ChartCacheItem, error)
+	MakeKey(digest string) string
+	Add(opts *CheckOptions, item ChartCacheItem) (ChartCacheItem, error)
 	Get(uri string) (ChartCacheItem, bool, error)
 }
 
 type ChartCacheItem struct {
 	Chart *chartv2.Chart
 	Path  string
+	// Digest is the SHA256 digest of the chart package the item was loaded
+	// from, and the key of the item in the on-disk cache.
+	Digest string
+	// validators are set for charts loaded from remote URIs.
+	validators remoteValidators
+	// revalidate is set for items loaded by an earlier run, which must be
+	// checked for changes before being served again.
+	revalidate bool
 }
 
+// chartCache keeps the charts loaded during this run, indexed by URI, and
+// saves them to the on-disk cache, indexed by package digest.
 type chartCache struct {
+	mu       sync.Mutex
 	chartMap map[string]ChartCacheItem
 }
 
@@ -106,36 +188,106 @@ func newChartCache() *chartCache {
 	}
 }
 
-func (c *chartCache) MakeKey(uri string) string {
-	return regexp.MustCompile("[:/?.-]").ReplaceAllString(uri, "_")
+// MakeKey returns the on-disk cache key for a chart package digest.
+func (c *chartCache) MakeKey(digest string) string {
+	return "sha256-" + digest
 }
 
 func (c *chartCache) Get(uri string) (ChartCacheItem, bool, error) {
-	if item, ok := c.chartMap[c.MakeKey(uri)]; !ok {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.chartMap[uri]; !ok {
 		return ChartCacheItem{}, false, nil
 	} else {
 		return item, true, nil
 	}
 }
 
-func (c *chartCache) Add(opts *CheckOptions, chrt *chartv2.Chart) (ChartCacheItem, error) {
-	var (
-		err          error
-		userCacheDir string
-	)
-
-	userCacheDir = getCacheDir(opts)
+// Add records item as the chart for opts.URI, saving it to the on-disk cache
+// unless a chart with the same digest is already there, and evicts stale
+// entries afterwards.
+func (c *chartCache) Add(opts *CheckOptions, item ChartCacheItem) (ChartCacheItem, error) {
+	userCacheDir := getCacheDir(opts)
 	if userCacheDir == "" {
-		return ChartCacheItem{}, err
+		return ChartCacheItem{}, errors.New("unable to determine the chart cache directory")
+	}
+	store := cache.New(userCacheDir)
+
+	chartCacheDir := store.EntryDir(cache.ChartEntry, c.MakeKey(item.Digest))
+	item.Path = path.Join(chartCacheDir, item.Chart.Name())
+	item.revalidate = false
+	if _, err := os.Stat(item.Path); err != nil {
+		if err := utilv2.SaveDir(item.Chart, chartCacheDir); err != nil {
+			return ChartCacheItem{}, err
+		}
 	}
-	key := c.MakeKey(opts.URI)
-	chartCacheDir := path.Join(userCacheDir, key)
-	cacheItem := ChartCacheItem{Chart: chrt, Path: path.Join(chartCacheDir, chrt.Name())}
-	if err = utilv2.SaveDir(chrt, chartCacheDir); err != nil {
+	if err := store.Touch(chartCacheDir); err != nil {
 		return ChartCacheItem{}, err
 	}
-	c.chartMap[key] = cacheItem
-	return cacheItem, nil
+
+	c.mu.Lock()
+	c.chartMap[opts.URI] = item
+	inUse := c.paths()
+	c.mu.Unlock()
+
+	c.evict(store, inUse)
+
+	return item, nil
+}
+
+// revalidated records that the chart cached for uri is unchanged, with the
+// latest validators of remote charts.
+func (c *chartCache) revalidated(uri string, validators remoteValidators) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.chartMap[uri]; ok {
+		item.validators = validators
+		item.revalidate = false
+		c.chartMap[uri] = item
+	}
+}
+
+// expire marks all the cached charts to be revalidated on their next load.
+func (c *chartCache) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uri, item := range c.chartMap {
+		item.revalidate = true
+		c.chartMap[uri] = item
+	}
+}
+
+// paths returns the paths of the cached charts. c.mu must be held.
+func (c *chartCache) paths() []string {
+	paths := make([]string, 0, len(c.chartMap))
+	for _, item := range c.chartMap {
+		paths = append(paths, item.Path)
+	}
+	return paths
+}
+
+// evict enforces the cache limits, keeping the charts at inUse. Failing to
+// evict is not fatal to loading a chart, so problems are only logged.
+func (c *chartCache) evict(store *cache.Cache, inUse []string) {
+	limits, err := cache.DefaultLimits()
+	if err != nil {
+		utils.LogWarning(fmt.Sprintf("Using default cache limits: %v", err))
+	}
+	isInUse := func(e cache.Entry) bool {
+		for _, p := range inUse {
+			if strings.HasPrefix(p, e.Path+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+	removed, err := store.Prune(limits, isInUse)
+	if err != nil {
+		utils.LogWarning(fmt.Sprintf("Error pruning the chart cache: %v", err))
+	}
+	for _, e := range removed {
+		utils.LogInfo(fmt.Sprintf("Evicted %s cache entry %s", e.Kind, e.Name))
+	}
 }
 
 var defaultChartCache *chartCache
@@ -144,17 +296,25 @@ func init() {
 	defaultChartCache = newChartCache()
 }
 
+// RevalidateCachedCharts makes the next load of each chart loaded so far check
+// whether the chart changed. It is called at the start of every run, so that
+// charts are checked once per run rather than on every load.
+func RevalidateCachedCharts() {
+	defaultChartCache.expire()
+}
+
 // LoadChartFromURI attempts to retrieve a chart from the given uri string. It accepts "http", "https", "file" schemes,
 // and defaults to "file" if there isn't one.
 //
+// Loaded charts are cached by package digest. The first load of a chart in a
+// run revalidates remote charts against their ETag or Last-Modified headers
+// and re-reads local charts, so that a chart changed since it was cached is
+// never served stale; later loads are served from memory.
+//
 // TODO(komish): Identify path to support OCI distribution
 func LoadChartFromURI(opts *CheckOptions) (*chartv2.Chart, string, error) {
-	var (
-		chrt *chartv2.Chart
-		err  error
-	)
-
-	if cached, ok, _ := defaultChartCache.Get(opts.URI); ok {
+	cached, isCached, _ := defaultChartCache.Get(opts.URI)
+	if isCached && !cached.revalidate {
 		return cached.Chart, cached.Path, nil
 	}
 
@@ -163,20 +323,42 @@ func LoadChartFromURI(opts *CheckOptions) (*chartv2.Chart, string, error) {
 		return nil, "", err
 	}
 
+	item := ChartCacheItem{}
 	switch u.Scheme {
 	case "http", "https":
-		chrt, err = loadChartFromRemote(u)
+		pkg, validators, notModified, err := fetchChartPackage(u, cached.validators)
+		if err != nil {
+			return nil, "", err
+		}
+		if notModified && isCached {
+			defaultChartCache.revalidated(opts.URI, validators)
+			return cached.Chart, cached.Path, nil
+		}
+		item.Digest = packageDigest(pkg)
+		item.validators = validators
+		if isCached && cached.Digest == item.Digest {
+			defaultChartCache.revalidated(opts.URI, validators)
+			return cached.Chart, cached.Path, nil
+		}
+		if item.Chart, err = loaderv2.LoadArchive(bytes.NewReader(pkg)); err != nil {
+			return nil, "", err
+		}
 	case "file", "":
-		chrt, err = loadChartFromAbsPath(u.Path)
+		if item.Chart, err = loadChartFromAbsPath(u.Path); err != nil {
+			return nil, "", err
+		}
+		if item.Digest, err = localChartDigest(u.Path, item.Chart); err != nil {
+			return nil, "", err
+		}
+		if isCached && cached.Digest == item.Digest {
+			defaultChartCache.revalidated(opts.URI, cached.validators)
+			return cached.Chart, cached.Path, nil
+		}
 	default:
 		return nil, "", fmt.Errorf("scheme %q not supported", u.Scheme)
 	}
 
-	if err != nil {
-		return nil, "", err
-	}
-
-	if cached, err := defaultChartCache.Add(opts, chrt); err != nil {
+	if cached, err := defaultChartCache.Add(opts, item); err != nil {
 		return nil, "", err
 	} else {
 		return cached.Chart, cached.Path, nil
@@ -203,6 +385,17 @@ func IsChartNotFound(err error) bool {
 // semantic version corresponding to a kubeVersion within the chart's
 // constraints as defined in Chart.yaml.
 func getImageReferences(chartURI string, vals map[string]interface{}, serverKubeVersionString string) ([]string, error) {
+	txt, err := renderManifests(chartURI, vals, serverKubeVersionString)
+	if err != nil {
+		return nil, err
+	}
+
+	return getImagesFromContent(txt)
+}
+
+// renderManifests renders the templates, CRDs and hooks of chartURI using
+// vals, against a mocked cluster of version serverKubeVersionString.
+func renderManifests(chartURI string, vals map[string]interface{}, serverKubeVersionString string) (string, error) {
 	// We'll start with DefaultCapabilities, but we'll really only use the
 	// kubeVersion of this when rendering manifests because Helm replaces the
 	// action config's capabilities for client-only execution.
@@ -210,7 +403,7 @@ func getImageReferences(chartURI string, vals map[string]interface{}, serverKube
 
 	kubeVersion, err := common.ParseKubeVersion(serverKubeVersionString)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", "unable to render manifests and extract images due to invalid kubeVersion in server capabilities", err)
+		return "", fmt.Errorf("%s: %w", "unable to render manifests due to invalid kubeVersion in server capabilities", err)
 	}
 
 	caps.KubeVersion = *kubeVersion
@@ -225,12 +418,25 @@ func getImageReferences(chartURI string, vals map[string]interface{}, serverKube
 	mem.SetNamespace("TestNamespace")
 	actionConfig.Releases = storage.Init(mem)
 
-	txt, err := actions.RenderManifests("test-release", chartURI, vals, actionConfig)
-	if err != nil {
-		return nil, err
+	return actions.RenderManifests("test-release", chartURI, vals, actionConfig)
+}
+
+// renderManifestsWithCapabilities renders the templates, CRDs and hooks of
+// chartURI using vals, against a mocked cluster with the kubernetes version
+// and the API versions of caps, for templates checking
+// .Capabilities.APIVersions to select the objects of the cluster.
+func renderManifestsWithCapabilities(chartURI string, vals map[string]interface{}, caps *common.Capabilities) (string, error) {
+	actionConfig := &action.Configuration{
+		Releases:     nil,
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: caps,
 	}
 
-	return getImagesFromContent(txt)
+	mem := driver.NewMemory()
+	mem.SetNamespace("TestNamespace")
+	actionConfig.Releases = storage.Init(mem)
+
+	return actions.RenderManifestsWithCapabilities("test-release", chartURI, vals, actionConfig)
 }
 
 // getImagesFromContent evaluates generated templates from
@@ -254,13 +460,5 @@ func getImagesFromContent(content string) ([]string, error) {
 }
 
 func getCacheDir(opts *CheckOptions) string {
-	var err error
-	cacheDir := opts.HelmEnvSettings.RepositoryCache
-	if cacheDir == "" {
-		cacheDir, err = os.UserCacheDir()
-		if err != nil {
-			return ""
-		}
-	}
-	return path.Join(cacheDir, "chart-verifier")
+	return cache.Dir(opts.HelmEnvSettings)
 }
@@ -18,9 +18,16 @@ package checks
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
@@ -147,6 +154,127 @@ func TestLoadChartFromURI(t *testing.T) {
 	cancel()
 }
 
+func TestLoadChartFromURIRevalidation(t *testing.T) {
+	valid, err := os.ReadFile("chart-0.1.0-v3.valid.tgz")
+	require.NoError(t, err)
+	changed, err := os.ReadFile("chart-0.1.0-v3.without-readme.tgz")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		useETag         bool
+		useLastModified bool
+		wantNotModified bool
+	}{
+		{name: "etag", useETag: true, wantNotModified: true},
+		{name: "last modified", useLastModified: true, wantNotModified: true},
+		{name: "no validators"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu          sync.Mutex
+				pkg         = valid
+				version     = 1
+				requests    int
+				notModified int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests++
+				etag := fmt.Sprintf("%q", strconv.Itoa(version))
+				lastModified := time.Date(2026, 10, 19, version, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+				if (tc.useETag && r.Header.Get("If-None-Match") == etag) ||
+					(tc.useLastModified && r.Header.Get("If-Modified-Since") == lastModified) {
+					notModified++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				if tc.useETag {
+					w.Header().Set("ETag", etag)
+				}
+				if tc.useLastModified {
+					w.Header().Set("Last-Modified", lastModified)
+				}
+				_, _ = w.Write(pkg)
+			}))
+			defer server.Close()
+
+			settings := cli.New()
+			settings.RepositoryCache = t.TempDir()
+			opts := &CheckOptions{URI: server.URL + "/chart-0.1.0-v3.valid.tgz", HelmEnvSettings: settings}
+
+			_, firstPath, err := LoadChartFromURI(opts)
+			require.NoError(t, err)
+			require.Equal(t, 1, requests)
+			require.Contains(t, firstPath, defaultChartCache.MakeKey(packageDigest(valid)))
+
+			// Later loads in the same run are served from memory.
+			_, path, err := LoadChartFromURI(opts)
+			require.NoError(t, err)
+			require.Equal(t, firstPath, path)
+			require.Equal(t, 1, requests)
+
+			// The first load of the next run checks the unchanged chart.
+			RevalidateCachedCharts()
+			_, path, err = LoadChartFromURI(opts)
+			require.NoError(t, err)
+			require.Equal(t, firstPath, path)
+			require.Equal(t, 2, requests)
+			if tc.wantNotModified {
+				require.Equal(t, 1, notModified)
+			} else {
+				require.Zero(t, notModified)
+			}
+			_, path, err = LoadChartFromURI(opts)
+			require.NoError(t, err)
+			require.Equal(t, firstPath, path)
+			require.Equal(t, 2, requests)
+
+			// A changed chart is loaded and cached under its own digest.
+			mu.Lock()
+			pkg = changed
+			version++
+			mu.Unlock()
+			RevalidateCachedCharts()
+			_, path, err = LoadChartFromURI(opts)
+			require.NoError(t, err)
+			require.Equal(t, 3, requests)
+			require.Contains(t, path, defaultChartCache.MakeKey(packageDigest(changed)))
+		})
+	}
+}
+
+func TestLoadChartFromURILocalRevalidation(t *testing.T) {
+	valid, err := os.ReadFile("chart-0.1.0-v3.valid.tgz")
+	require.NoError(t, err)
+	changed, err := os.ReadFile("chart-0.1.0-v3.without-readme.tgz")
+	require.NoError(t, err)
+
+	chartPath := filepath.Join(t.TempDir(), "chart.tgz")
+	require.NoError(t, os.WriteFile(chartPath, valid, 0o644))
+	settings := cli.New()
+	settings.RepositoryCache = t.TempDir()
+	opts := &CheckOptions{URI: chartPath, HelmEnvSettings: settings}
+
+	_, firstPath, err := LoadChartFromURI(opts)
+	require.NoError(t, err)
+	require.Contains(t, firstPath, defaultChartCache.MakeKey(packageDigest(valid)))
+
+	// The chart is not re-read until the next run.
+	require.NoError(t, os.WriteFile(chartPath, changed, 0o644))
+	_, path, err := LoadChartFromURI(opts)
+	require.NoError(t, err)
+	require.Equal(t, firstPath, path)
+
+	RevalidateCachedCharts()
+	_, path, err = LoadChartFromURI(opts)
+	require.NoError(t, err)
+	require.Contains(t, path, defaultChartCache.MakeKey(packageDigest(changed)))
+}
+
 func TestTemplate(t *testing.T) {
 	type testCase struct {
 		description string
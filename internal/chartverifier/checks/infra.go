@@ -0,0 +1,195 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AllowedProvisionersConfigString lists the storage provisioners, in
+// addition to the ones of the OpenShift platforms, that StorageClasses of the
+// chart may use.
+const AllowedProvisionersConfigString string = "allowedProvisioners"
+
+// Categories of infrastructure objects.
+const (
+	CSIDriverCategory            = "CSI driver"
+	StorageProvisionerCategory   = "Storage provisioner"
+	PrivilegedHostAccessCategory = "Privileged host access"
+	DevicePluginCategory         = "Device plugin"
+	CNIConfigurationCategory     = "CNI configuration"
+	MutatingWebhookCategory      = "Mutating webhook"
+)
+
+// platformProvisioners are the storage provisioners of the OpenShift
+// platforms.
+var platformProvisioners = []string{
+	"kubernetes.io/no-provisioner",
+	"kubernetes.io/aws-ebs",
+	"kubernetes.io/azure-disk",
+	"kubernetes.io/azure-file",
+	"kubernetes.io/cinder",
+	"kubernetes.io/gce-pd",
+	"kubernetes.io/vsphere-volume",
+	"ebs.csi.aws.com",
+	"efs.csi.aws.com",
+	"disk.csi.azure.com",
+	"file.csi.azure.com",
+	"pd.csi.storage.gke.io",
+	"filestore.csi.storage.gke.io",
+	"cinder.csi.openstack.org",
+	"manila.csi.openstack.org",
+	"csi.vsphere.vmware.com",
+	"vpc.block.csi.ibm.io",
+	"diskplugin.csi.alibabacloud.com",
+	"csi.ovirt.org",
+	"topolvm.io",
+	"openshift-storage.rbd.csi.ceph.com",
+	"openshift-storage.cephfs.csi.ceph.com",
+	"openshift-storage.noobaa.io/obc",
+}
+
+var (
+	// devicePluginHostPath is the kubelet directory device plugins register
+	// their socket in.
+	devicePluginHostPath = "/var/lib/kubelet/device-plugins"
+	// cniHostPaths are the host directories holding CNI configurations and
+	// plugins.
+	cniHostPaths = []string{"/etc/cni", "/opt/cni", "/var/lib/cni", "/etc/kubernetes/cni", "/run/multus", "/var/run/multus"}
+	// sensitiveHostPaths are the host directories of devices, kernel
+	// interfaces, and of the kubelet and container runtime.
+	sensitiveHostPaths = []string{"/", "/dev", "/sys", "/proc", "/var/lib/kubelet", "/etc/kubernetes", "/run/containerd", "/var/run/crio", "/run/crio", "/var/lib/containers"}
+)
+
+// infraFinding is an infrastructure object of the chart.
+type infraFinding struct {
+	Category string
+	Object   renderedObject
+	Detail   string
+}
+
+func (f infraFinding) String() string {
+	description := fmt.Sprintf("%s: %s", f.Category, f.Object)
+	if location := f.Object.Location(); location != "" {
+		description += fmt.Sprintf(" in %s", location)
+	}
+	if f.Detail != "" {
+		description += fmt.Sprintf(" %s", f.Detail)
+	}
+	return description
+}
+
+// NotContainsInfraPluginsAndDrivers renders the chart, with its default
+// values and with each of its CI values files, and checks that it doesn't
+// contain objects extending the cluster infrastructure, such as
+// storage drivers, device plugins, network plugins or admission webhooks,
+// which are expected to be delivered by operators.
+func NotContainsInfraPluginsAndDrivers(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", InfraObjectsNotEvaluated, err)), nil
+	}
+
+	provisioners := slices.Concat(platformProvisioners, opts.ViperConfig.GetStringSlice(AllowedProvisionersConfigString))
+	findings := infraFindings(objects, provisioners)
+	if len(findings) == 0 {
+		return NewResult(true, InfraObjectsDoNotExist), nil
+	}
+
+	r := NewResult(false, InfraObjectsExist)
+	for _, finding := range findings {
+		r.AddResult(false, finding.String())
+	}
+	return r, nil
+}
+
+// infraFindings returns the infrastructure objects of objects, sorted by
+// category, each once per category and detail.
+func infraFindings(objects []renderedObject, provisioners []string) []infraFinding {
+	var findings []infraFinding
+	for _, obj := range objects {
+		switch obj.GetKind() {
+		case "CSIDriver", "CSINode":
+			findings = append(findings, infraFinding{Category: CSIDriverCategory, Object: obj})
+		case "StorageClass":
+			provisioner, _, _ := unstructured.NestedString(obj.Object, "provisioner")
+			if !slices.Contains(provisioners, provisioner) {
+				findings = append(findings, infraFinding{Category: StorageProvisionerCategory, Object: obj, Detail: fmt.Sprintf("uses provisioner %s", provisioner)})
+			}
+		case "MutatingWebhookConfiguration":
+			findings = append(findings, infraFinding{Category: MutatingWebhookCategory, Object: obj})
+		}
+		if spec := podSpec(obj.Unstructured); spec != nil {
+			findings = append(findings, hostPathFindings(obj, spec)...)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Category < findings[j].Category })
+	return slices.CompactFunc(findings, func(a, b infraFinding) bool { return a.String() == b.String() })
+}
+
+// hostPathFindings returns the device plugin and CNI directories obj mounts,
+// and for privileged DaemonSets, the sensitive host directories it mounts.
+func hostPathFindings(obj renderedObject, spec map[string]interface{}) []infraFinding {
+	hostPaths := map[string]string{}
+	for _, volume := range nestedMaps(spec, "volumes") {
+		name, _, _ := unstructured.NestedString(volume, "name")
+		hostPath, found, _ := unstructured.NestedString(volume, "hostPath", "path")
+		if found {
+			hostPaths[name] = path.Clean(hostPath)
+		}
+	}
+	if len(hostPaths) == 0 {
+		return nil
+	}
+
+	var findings []infraFinding
+	privileged := false
+	for _, container := range podContainers(spec) {
+		if p, _, _ := unstructured.NestedBool(container, "securityContext", "privileged"); p {
+			privileged = true
+		}
+		for _, mount := range nestedMaps(container, "volumeMounts") {
+			name, _, _ := unstructured.NestedString(mount, "name")
+			hostPath, ok := hostPaths[name]
+			if !ok {
+				continue
+			}
+			readOnly, _, _ := unstructured.NestedBool(mount, "readOnly")
+			switch {
+			case isHostPathUnder(hostPath, devicePluginHostPath):
+				findings = append(findings, infraFinding{Category: DevicePluginCategory, Object: obj, Detail: fmt.Sprintf("mounts %s", hostPath)})
+			case slices.ContainsFunc(cniHostPaths, func(p string) bool { return isHostPathUnder(hostPath, p) }) && !readOnly:
+				findings = append(findings, infraFinding{Category: CNIConfigurationCategory, Object: obj, Detail: fmt.Sprintf("writes to %s", hostPath)})
+			}
+		}
+	}
+
+	if privileged && obj.GetKind() == "DaemonSet" {
+		var sensitive []string
+		for _, hostPath := range hostPaths {
+			if slices.ContainsFunc(sensitiveHostPaths, func(p string) bool { return hostPath == p || (p != "/" && isHostPathUnder(hostPath, p)) }) {
+				sensitive = append(sensitive, hostPath)
+			}
+		}
+		if len(sensitive) > 0 {
+			sort.Strings(sensitive)
+			findings = append(findings, infraFinding{Category: PrivilegedHostAccessCategory, Object: obj, Detail: fmt.Sprintf("is privileged and mounts %s", strings.Join(sensitive, ", "))})
+		}
+	}
+	return findings
+}
+
+// isHostPathUnder returns whether hostPath is dir or one of its
+// subdirectories.
+func isHostPathUnder(hostPath, dir string) bool {
+	return hostPath == dir || strings.HasPrefix(hostPath, dir+"/")
+}
@@ -0,0 +1,160 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestInfraFindings(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/csi.yaml
+apiVersion: storage.k8s.io/v1
+kind: CSIDriver
+metadata:
+  name: csi.example.com
+---
+# Source: chart/templates/storageclass.yaml
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: fast
+provisioner: csi.example.com
+---
+# Source: chart/templates/storageclass.yaml
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: gp3
+provisioner: ebs.csi.aws.com
+---
+# Source: chart/templates/webhook.yaml
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: injector
+---
+# Source: chart/templates/node-agent.yaml
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-agent
+spec:
+  template:
+    spec:
+      containers:
+        - name: plugin
+          securityContext:
+            privileged: true
+          volumeMounts:
+            - name: device-plugins
+              mountPath: /var/lib/kubelet/device-plugins
+            - name: dev
+              mountPath: /dev
+        - name: cni
+          volumeMounts:
+            - name: cni-conf
+              mountPath: /host/etc/cni/net.d
+            - name: device-plugins
+              mountPath: /device-plugins
+      volumes:
+        - name: device-plugins
+          hostPath:
+            path: /var/lib/kubelet/device-plugins/
+        - name: dev
+          hostPath:
+            path: /dev
+        - name: cni-conf
+          hostPath:
+            path: /etc/cni/net.d
+---
+# Source: chart/templates/logs.yaml
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: logs
+spec:
+  template:
+    spec:
+      containers:
+        - name: logs
+          volumeMounts:
+            - name: logs
+              mountPath: /var/log
+              readOnly: true
+            - name: cni-conf
+              mountPath: /etc/cni
+              readOnly: true
+      volumes:
+        - name: logs
+          hostPath:
+            path: /var/log
+        - name: cni-conf
+          hostPath:
+            path: /etc/cni
+`
+
+	objects, err := decodeRenderedManifests(manifests)
+	require.NoError(t, err)
+
+	var findings []string
+	for _, finding := range infraFindings(objects, platformProvisioners) {
+		findings = append(findings, finding.String())
+	}
+	require.Equal(t, []string{
+		`CNI configuration: DaemonSet "node-agent" in chart/templates/node-agent.yaml writes to /etc/cni/net.d`,
+		`CSI driver: CSIDriver "csi.example.com" in chart/templates/csi.yaml`,
+		`Device plugin: DaemonSet "node-agent" in chart/templates/node-agent.yaml mounts /var/lib/kubelet/device-plugins`,
+		`Mutating webhook: MutatingWebhookConfiguration "injector" in chart/templates/webhook.yaml`,
+		`Privileged host access: DaemonSet "node-agent" in chart/templates/node-agent.yaml is privileged and mounts /dev, /var/lib/kubelet/device-plugins`,
+		`Storage provisioner: StorageClass "fast" in chart/templates/storageclass.yaml uses provisioner csi.example.com`,
+	}, findings)
+
+	require.Len(t, infraFindings(objects, append([]string{"csi.example.com"}, platformProvisioners...)), 5)
+}
+
+func TestNotContainsInfraPluginsAndDrivers(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		ok          bool
+		reason      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "chart without infrastructure objects",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			ok:          true,
+			reason:      InfraObjectsDoNotExist,
+		},
+		{
+			description: "chart with a CSI driver",
+			uri:         "chart-0.1.0-v3.with-csi.tgz",
+			ok:          false,
+			reason:      InfraObjectsExist + "\n" + CSIDriverCategory + `: CSIDriver "mycsidriver.example.com" in chart/templates/csidriver.yaml`,
+		},
+		{
+			description: "CI values file enables a mutating webhook",
+			uri: writeTestChart(t, "demo", map[string]string{
+				"Chart.yaml":             "apiVersion: v2\nname: demo\nversion: 0.1.0\n",
+				"values.yaml":            "webhook: false\n",
+				"ci/webhook-values.yaml": "webhook: true\n",
+				"templates/webhook.yaml": "{{- if .Values.webhook }}\napiVersion: admissionregistration.k8s.io/v1\nkind: MutatingWebhookConfiguration\nmetadata:\n  name: demo\n{{- end }}\n",
+			}),
+			ok:     false,
+			reason: InfraObjectsExist + "\n" + MutatingWebhookCategory + `: MutatingWebhookConfiguration "demo" in demo/templates/webhook.yaml (with ci/webhook-values.yaml)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := NotContainsInfraPluginsAndDrivers(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
+)
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+	sourceComment     = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+)
+
+// renderedObject is an object of the rendered manifests of a chart, along
+// with the template it was rendered from and, when it is only rendered with
+// one of the CI values files of the chart, that values file.
+type renderedObject struct {
+	unstructured.Unstructured
+	Source     string
+	ValuesFile string
+}
+
+// String returns the kind and name of the object, e.g. Deployment "app".
+func (o renderedObject) String() string {
+	return fmt.Sprintf("%s %q", o.GetKind(), o.GetName())
+}
+
+// Location returns the template the object was rendered from, along with the
+// CI values file it was rendered with, if any.
+func (o renderedObject) Location() string {
+	if o.ValuesFile != "" {
+		return fmt.Sprintf("%s (with %s)", o.Source, o.ValuesFile)
+	}
+	return o.Source
+}
+
+// finding returns a finding about the object.
+func (o renderedObject) finding(severity apiReport.FindingSeverity, code, message, remediation string) apiReport.Finding {
+	return apiReport.Finding{
+		Severity:    severity,
+		Code:        code,
+		Message:     message,
+		Object:      o.String(),
+		Path:        o.Source,
+		ValuesFile:  o.ValuesFile,
+		Remediation: remediation,
+	}
+}
+
+// decodeRenderedManifests returns the objects of manifests, as rendered by
+// renderManifests, with the template each object was rendered from.
+func decodeRenderedManifests(manifests string) ([]renderedObject, error) {
+	var objects []renderedObject
+	for _, document := range documentSeparator.Split(manifests, -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		source := ""
+		if match := sourceComment.FindStringSubmatch(document); match != nil {
+			source = strings.TrimSpace(match[1])
+		}
+		documentObjects, err := decodeManifests(document)
+		if err != nil {
+			if source != "" {
+				return nil, fmt.Errorf("%s: %w", source, err)
+			}
+			return nil, err
+		}
+		for _, obj := range documentObjects {
+			objects = append(objects, renderedObject{Unstructured: obj, Source: source})
+		}
+	}
+	return objects, nil
+}
+
+// valuesSet is a set of values to render a chart with, and the CI values file
+// it comes from, if any.
+type valuesSet struct {
+	file   string
+	values map[string]interface{}
+}
+
+// renderChartObjects returns the objects of c rendered with its default
+// values, and with each of its CI values files. opts.Values take precedence
+// over the CI values files. Objects rendered identically with several values
+// files are returned once.
+func renderChartObjects(opts *CheckOptions, c *chartv2.Chart) ([]renderedObject, error) {
+	kubeVersionString := defaultMockedKubeVersionString
+	if userKubeVersion := opts.ViperConfig.GetString("kube-version"); userKubeVersion != "" {
+		kubeVersionString = userKubeVersion
+	}
+
+	return renderValuesSets(opts, c, func(values map[string]interface{}) (string, error) {
+		return renderManifests(opts.URI, values, kubeVersionString)
+	})
+}
+
+// renderValuesSets returns the objects of c rendered by render, as
+// renderChartObjects does.
+func renderValuesSets(opts *CheckOptions, c *chartv2.Chart, render func(values map[string]interface{}) (string, error)) ([]renderedObject, error) {
+	valuesSets, err := ciValuesSets(opts, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []renderedObject
+	rendered := map[string]bool{}
+	for _, set := range valuesSets {
+		manifests, err := render(set.values)
+		if err != nil {
+			if set.file != "" {
+				return nil, fmt.Errorf("error rendering the chart with %s: %w", set.file, err)
+			}
+			return nil, fmt.Errorf("error rendering the chart: %w", err)
+		}
+		valuesSetObjects, err := decodeRenderedManifests(manifests)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range valuesSetObjects {
+			content, err := json.Marshal(obj.Object)
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprintf("%s/%s/%s", obj.GroupVersionKind(), obj, content)
+			if rendered[key] {
+				continue
+			}
+			rendered[key] = true
+			obj.ValuesFile = set.file
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// ciValuesSets returns opts.Values, along with the values of each CI values
+// file of c, the ci/*-values.yaml files chart-testing installs the chart
+// with, overridden by opts.Values.
+func ciValuesSets(opts *CheckOptions, c *chartv2.Chart) ([]valuesSet, error) {
+	valuesSets := []valuesSet{{values: opts.Values}}
+	for _, f := range c.Files {
+		if path.Dir(f.Name) != "ci" || !strings.HasSuffix(f.Name, "-values.yaml") {
+			continue
+		}
+		ciValues := map[string]interface{}{}
+		if err := yaml.Unmarshal(f.Data, &ciValues); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", f.Name, err)
+		}
+		valuesSets = append(valuesSets, valuesSet{file: f.Name, values: mergeValues(ciValues, opts.Values)})
+	}
+	return valuesSets, nil
+}
+
+// mergeValues returns the values of base overridden by the ones of
+// overrides, merging nested maps.
+func mergeValues(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeValues(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// podSpecPaths are the fields holding the pod template of the workload
+// kinds.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"DeploymentConfig":      {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// podSpec returns the pod spec of a workload object, or nil when the object
+// has none.
+func podSpec(obj unstructured.Unstructured) map[string]interface{} {
+	fields, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return nil
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, fields...)
+	return spec
+}
+
+// podContainers returns the init, ephemeral and regular containers of spec.
+func podContainers(spec map[string]interface{}) []map[string]interface{} {
+	var containers []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _, _ := unstructured.NestedSlice(spec, field)
+		for _, item := range list {
+			if container, ok := item.(map[string]interface{}); ok {
+				containers = append(containers, container)
+			}
+		}
+	}
+	return containers
+}
+
+// nestedMaps returns the maps of the list at fields of obj.
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	list, _, _ := unstructured.NestedSlice(obj, fields...)
+	maps := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// nestedInt returns the integer at fields of obj. Unlike
+// unstructured.NestedInt64, it accepts the float64 numbers of decoded JSON.
+func nestedInt(obj map[string]interface{}, fields ...string) (int64, bool) {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	switch number := value.(type) {
+	case int64:
+		return number, found
+	case int:
+		return int64(number), found
+	case float64:
+		return int64(number), found
+	}
+	return 0, false
+}
@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const (
+	// RequireAppVersionConfigString requires the appVersion when true, the
+	// default.
+	RequireAppVersionConfigString string = "requireAppVersion"
+	// RequireMaintainersConfigString requires maintainers when true, the
+	// default.
+	RequireMaintainersConfigString string = "requireMaintainers"
+	// RequireIconConfigString requires an icon when true, false by default.
+	RequireIconConfigString string = "requireIcon"
+	// DescriptionMinLengthConfigString is the minimum length of the
+	// description, 10 by default.
+	DescriptionMinLengthConfigString string = "descriptionMinLength"
+	// DescriptionMaxLengthConfigString is the maximum length of the
+	// description, 500 by default, 0 for no maximum.
+	DescriptionMaxLengthConfigString string = "descriptionMaxLength"
+	// ArchsConfigString lists the architectures charts.openshift.io/archs
+	// may list.
+	ArchsConfigString string = "archs"
+)
+
+const (
+	defaultDescriptionMinLength = 10
+	defaultDescriptionMaxLength = 500
+)
+
+var defaultArchs = []string{"x86_64", "amd64", "aarch64", "arm64", "ppc64le", "s390x"}
+
+// metadataRules are the rules the chart metadata is checked against.
+type metadataRules struct {
+	RequireAppVersion    bool
+	RequireMaintainers   bool
+	RequireIcon          bool
+	DescriptionMinLength int
+	DescriptionMaxLength int
+	Archs                []string
+}
+
+// MetadataIsValid checks that the version of the chart is a full semantic
+// version, and that its appVersion, maintainers, home, sources, icon,
+// description and charts.openshift.io annotations are set and well formed,
+// as configured.
+func MetadataIsValid(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	r := NewResult(true, MetadataValid)
+	for _, finding := range metadataFindings(c.Metadata, getMetadataRules(opts)) {
+		addReason(&r, MetadataNotValid, finding)
+	}
+	return r, nil
+}
+
+// getMetadataRules returns the rules set in the check config, or the default
+// ones.
+func getMetadataRules(opts *CheckOptions) metadataRules {
+	config := opts.ViperConfig
+	rules := metadataRules{
+		RequireAppVersion:    true,
+		RequireMaintainers:   true,
+		DescriptionMinLength: defaultDescriptionMinLength,
+		DescriptionMaxLength: defaultDescriptionMaxLength,
+		Archs:                defaultArchs,
+	}
+	if config.IsSet(RequireAppVersionConfigString) {
+		rules.RequireAppVersion = config.GetBool(RequireAppVersionConfigString)
+	}
+	if config.IsSet(RequireMaintainersConfigString) {
+		rules.RequireMaintainers = config.GetBool(RequireMaintainersConfigString)
+	}
+	rules.RequireIcon = config.GetBool(RequireIconConfigString)
+	if config.IsSet(DescriptionMinLengthConfigString) {
+		rules.DescriptionMinLength = config.GetInt(DescriptionMinLengthConfigString)
+	}
+	if config.IsSet(DescriptionMaxLengthConfigString) {
+		rules.DescriptionMaxLength = config.GetInt(DescriptionMaxLengthConfigString)
+	}
+	if config.IsSet(ArchsConfigString) {
+		rules.Archs = config.GetStringSlice(ArchsConfigString)
+	}
+	return rules
+}
+
+// metadataFindings returns the fields of md that are missing or not well
+// formed.
+func metadataFindings(md *chartv2.Metadata, rules metadataRules) []string {
+	var findings []string
+	if !exactVersion(md.Version) {
+		findings = append(findings, fmt.Sprintf("version %q is not a full semantic version", md.Version))
+	}
+	if md.AppVersion == "" && rules.RequireAppVersion {
+		findings = append(findings, "no appVersion")
+	}
+
+	if len(md.Maintainers) == 0 && rules.RequireMaintainers {
+		findings = append(findings, "no maintainers")
+	}
+	for _, maintainer := range md.Maintainers {
+		switch {
+		case maintainer.Email == "" && maintainer.URL == "":
+			findings = append(findings, fmt.Sprintf("maintainer %q has no email or URL", maintainer.Name))
+		case maintainer.Email != "" && !validEmail(maintainer.Email):
+			findings = append(findings, fmt.Sprintf("maintainer %q: email %q is not valid", maintainer.Name, maintainer.Email))
+		case maintainer.URL != "" && !absoluteURL(maintainer.URL, "http", "https"):
+			findings = append(findings, fmt.Sprintf("maintainer %q: URL %q is not an absolute http or https URL", maintainer.Name, maintainer.URL))
+		}
+	}
+
+	if md.Home != "" && !absoluteURL(md.Home, "http", "https") {
+		findings = append(findings, fmt.Sprintf("home %q is not an absolute http or https URL", md.Home))
+	}
+	for _, source := range md.Sources {
+		if !absoluteURL(source, "http", "https") {
+			findings = append(findings, fmt.Sprintf("source %q is not an absolute http or https URL", source))
+		}
+	}
+
+	switch {
+	case md.Icon == "" && rules.RequireIcon:
+		findings = append(findings, "no icon")
+	case md.Icon != "" && !absoluteURL(md.Icon, "https") && !strings.HasPrefix(md.Icon, "data:image/"):
+		findings = append(findings, fmt.Sprintf("icon %q is not an HTTPS URL or an image data URI", md.Icon))
+	}
+
+	length := len([]rune(strings.TrimSpace(md.Description)))
+	if length < rules.DescriptionMinLength {
+		findings = append(findings, fmt.Sprintf("description shorter than %d characters", rules.DescriptionMinLength))
+	}
+	if rules.DescriptionMaxLength > 0 && length > rules.DescriptionMaxLength {
+		findings = append(findings, fmt.Sprintf("description longer than %d characters", rules.DescriptionMaxLength))
+	}
+
+	return append(findings, annotationFindings(md.Annotations, rules.Archs)...)
+}
+
+// annotationFindings returns the charts.openshift.io annotations of
+// annotations whose value is not well formed.
+func annotationFindings(annotations map[string]string, archs []string) []string {
+	var findings []string
+	for _, name := range []string{"charts.openshift.io/name", "charts.openshift.io/provider"} {
+		if value, found := annotations[name]; found && strings.TrimSpace(value) == "" {
+			findings = append(findings, fmt.Sprintf("annotation %s is empty", name))
+		}
+	}
+	if value, found := annotations["charts.openshift.io/supportURL"]; found && !absoluteURL(value, "http", "https") {
+		findings = append(findings, fmt.Sprintf("annotation charts.openshift.io/supportURL: %q is not an absolute http or https URL", value))
+	}
+	if value, found := annotations["charts.openshift.io/archs"]; found {
+		for _, arch := range strings.Split(value, ",") {
+			if arch = strings.TrimSpace(arch); !slices.Contains(archs, arch) {
+				findings = append(findings, fmt.Sprintf("annotation charts.openshift.io/archs: unknown architecture %q", arch))
+			}
+		}
+	}
+	return findings
+}
+
+// absoluteURL returns whether raw is an absolute URL with a host and one of
+// schemes.
+func absoluteURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Host != "" && slices.Contains(schemes, u.Scheme)
+}
+
+// validEmail returns whether email is a bare email address.
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
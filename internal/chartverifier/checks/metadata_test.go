@@ -0,0 +1,113 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestMetadataFindings(t *testing.T) {
+	rules := getMetadataRules(&CheckOptions{ViperConfig: viper.New()})
+
+	md := &chartv2.Metadata{
+		Version:     "1.2.3",
+		AppVersion:  "4.5",
+		Description: "A Helm chart for a demo application",
+		Home:        "https://example.com",
+		Sources:     []string{"https://github.com/example/chart"},
+		Icon:        "data:image/png;base64,iVBORw0KGgo=",
+		Maintainers: []*chartv2.Maintainer{
+			{Name: "team", Email: "team@example.com"},
+			{Name: "org", URL: "https://example.com/org"},
+		},
+		Annotations: map[string]string{
+			"charts.openshift.io/name":       "Demo",
+			"charts.openshift.io/supportURL": "https://example.com/support",
+			"charts.openshift.io/archs":      "x86_64, s390x",
+		},
+	}
+	require.Empty(t, metadataFindings(md, rules))
+
+	md = &chartv2.Metadata{
+		Version:     "1.2",
+		Description: "Demo",
+		Home:        "example.com",
+		Sources:     []string{"https://github.com/example/chart", "/src"},
+		Icon:        "http://example.com/icon.png",
+		Maintainers: []*chartv2.Maintainer{
+			{Name: "team"},
+			{Name: "dev", Email: "Dev <dev@example.com>"},
+			{Name: "org", URL: "example.com/org"},
+		},
+		Annotations: map[string]string{
+			"charts.openshift.io/provider":   " ",
+			"charts.openshift.io/supportURL": "support",
+			"charts.openshift.io/archs":      "x86_64,riscv64",
+		},
+	}
+	require.Equal(t, []string{
+		`version "1.2" is not a full semantic version`,
+		"no appVersion",
+		`maintainer "team" has no email or URL`,
+		`maintainer "dev": email "Dev <dev@example.com>" is not valid`,
+		`maintainer "org": URL "example.com/org" is not an absolute http or https URL`,
+		`home "example.com" is not an absolute http or https URL`,
+		`source "/src" is not an absolute http or https URL`,
+		`icon "http://example.com/icon.png" is not an HTTPS URL or an image data URI`,
+		"description shorter than 10 characters",
+		"annotation charts.openshift.io/provider is empty",
+		`annotation charts.openshift.io/supportURL: "support" is not an absolute http or https URL`,
+		`annotation charts.openshift.io/archs: unknown architecture "riscv64"`,
+	}, metadataFindings(md, rules))
+
+	rules.RequireIcon = true
+	rules.DescriptionMaxLength = 20
+	require.Equal(t, []string{"no maintainers", "no icon", "description longer than 20 characters"},
+		metadataFindings(&chartv2.Metadata{Version: "1.0.0", AppVersion: "1.0", Description: strings.Repeat("a", 21)}, rules))
+}
+
+func TestMetadataIsValid(t *testing.T) {
+	type testCase struct {
+		description string
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "chart without maintainers",
+			ok:          false,
+			reason:      MetadataNotValid + "\nno maintainers",
+		},
+		{
+			description: "description shorter than the configured minimum",
+			config:      map[string]interface{}{RequireMaintainersConfigString: false, DescriptionMinLengthConfigString: 30},
+			ok:          false,
+			reason:      MetadataNotValid + "\ndescription shorter than 30 characters",
+		},
+		{
+			description: "metadata follows the configured rules",
+			config:      map[string]interface{}{RequireMaintainersConfigString: false},
+			ok:          true,
+			reason:      MetadataValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			r, err := MetadataIsValid(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
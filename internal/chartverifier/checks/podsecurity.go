@@ -0,0 +1,397 @@
+package checks
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SCCConfigString is the OpenShift SCC the workloads of the chart must be
+// able to run with, restricted-v2 by default.
+const SCCConfigString string = "scc"
+
+const defaultSCC = "restricted-v2"
+
+// Pod Security Standards levels.
+const (
+	PrivilegedLevel = "privileged"
+	BaselineLevel   = "baseline"
+	RestrictedLevel = "restricted"
+)
+
+// runAsUser strategies of SCCs.
+const (
+	mustRunAsRange   = "MustRunAsRange"
+	mustRunAsNonRoot = "MustRunAsNonRoot"
+	runAsAny         = "RunAsAny"
+)
+
+var (
+	// restrictedVolumeTypes are the volume types allowed by the restricted
+	// Pod Security Standard and the restricted SCCs.
+	restrictedVolumeTypes = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+	// baselineCapabilities are the capabilities the baseline Pod Security
+	// Standard allows to add.
+	baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	// baselineSELinuxTypes are the SELinux types the baseline Pod Security
+	// Standard allows.
+	baselineSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+	// safeSysctls are the sysctls the baseline Pod Security Standard allows.
+	safeSysctls = []string{"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start", "net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports", "net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl", "net.ipv4.tcp_keepalive_probes"}
+)
+
+// securityContextConstraints are the settings of an OpenShift SCC that
+// decide whether a pod is admitted.
+type securityContextConstraints struct {
+	Name                     string
+	AllowPrivileged          bool
+	AllowHostNetwork         bool
+	AllowHostPorts           bool
+	AllowHostPID             bool
+	AllowHostIPC             bool
+	AllowPrivilegeEscalation bool
+	AllowUnconfinedSeccomp   bool
+	AllowSELinuxOptions      bool
+	AllowFSGroup             bool
+	RunAsUser                string
+	AllowedCapabilities      []string
+	Volumes                  []string
+}
+
+// defaultSCCs are the default SCCs of OpenShift, from the least to the most
+// privileged.
+var defaultSCCs = []securityContextConstraints{
+	{
+		Name:                "restricted-v2",
+		RunAsUser:           mustRunAsRange,
+		AllowedCapabilities: []string{"NET_BIND_SERVICE"},
+		Volumes:             restrictedVolumeTypes,
+	},
+	{
+		Name:                "nonroot-v2",
+		RunAsUser:           mustRunAsNonRoot,
+		AllowFSGroup:        true,
+		AllowedCapabilities: []string{"NET_BIND_SERVICE"},
+		Volumes:             restrictedVolumeTypes,
+	},
+	{
+		Name:                "hostnetwork-v2",
+		AllowHostNetwork:    true,
+		AllowHostPorts:      true,
+		RunAsUser:           mustRunAsRange,
+		AllowedCapabilities: []string{"NET_BIND_SERVICE"},
+		Volumes:             restrictedVolumeTypes,
+	},
+	{
+		Name:                     "anyuid",
+		AllowPrivilegeEscalation: true,
+		AllowUnconfinedSeccomp:   true,
+		AllowFSGroup:             true,
+		RunAsUser:                runAsAny,
+		Volumes:                  restrictedVolumeTypes,
+	},
+	{
+		Name:                     "hostmount-anyuid",
+		AllowPrivilegeEscalation: true,
+		AllowUnconfinedSeccomp:   true,
+		AllowFSGroup:             true,
+		RunAsUser:                runAsAny,
+		Volumes:                  append(slices.Clone(restrictedVolumeTypes), "hostPath", "nfs"),
+	},
+	{
+		Name:                     "hostaccess",
+		AllowHostNetwork:         true,
+		AllowHostPorts:           true,
+		AllowHostPID:             true,
+		AllowHostIPC:             true,
+		AllowPrivilegeEscalation: true,
+		AllowUnconfinedSeccomp:   true,
+		RunAsUser:                mustRunAsRange,
+		Volumes:                  append(slices.Clone(restrictedVolumeTypes), "hostPath"),
+	},
+	{
+		Name:                     "privileged",
+		AllowPrivileged:          true,
+		AllowHostNetwork:         true,
+		AllowHostPorts:           true,
+		AllowHostPID:             true,
+		AllowHostIPC:             true,
+		AllowPrivilegeEscalation: true,
+		AllowUnconfinedSeccomp:   true,
+		AllowSELinuxOptions:      true,
+		AllowFSGroup:             true,
+		RunAsUser:                runAsAny,
+		AllowedCapabilities:      []string{"*"},
+		Volumes:                  []string{"*"},
+	},
+}
+
+// PodSecurityCompatible renders the chart and checks that its workloads can
+// run with the configured OpenShift SCC, restricted-v2 by default, reporting
+// the least privileged default SCC each other workload needs, and warns about
+// the workloads that don't meet the restricted Pod Security Standard.
+func PodSecurityCompatible(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	sccName := defaultSCC
+	if configSCC := opts.ViperConfig.GetString(SCCConfigString); configSCC != "" {
+		sccName = configSCC
+	}
+	allowed := slices.IndexFunc(defaultSCCs, func(scc securityContextConstraints) bool { return scc.Name == sccName })
+	if allowed < 0 {
+		return NewResult(false, fmt.Sprintf("%s: unknown SCC %q", PodSecurityNotEvaluated, sccName)), nil
+	}
+
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", PodSecurityNotEvaluated, err)), nil
+	}
+
+	r := NewResult(true, fmt.Sprintf("%s: %s", WorkloadsRunWithSCC, sccName))
+	var warnings []string
+	for _, obj := range objects {
+		spec := podSpec(obj.Unstructured)
+		if spec == nil {
+			continue
+		}
+		workload := obj.String()
+		if location := obj.Location(); location != "" {
+			workload += fmt.Sprintf(" in %s", location)
+		}
+		if needed := minimalSCC(spec); needed > allowed {
+			addReason(&r, fmt.Sprintf("%s: %s", WorkloadsNeedPrivilegedSCC, sccName), fmt.Sprintf("%s needs the %s SCC: %s",
+				workload, defaultSCCs[needed].Name, strings.Join(sccViolations(spec, defaultSCCs[allowed]), ", ")))
+		}
+		if level, violations := podSecurityLevel(spec); level != RestrictedLevel {
+			warnings = append(warnings, fmt.Sprintf("%s: %s is %s: %s", PodSecurityNotRestricted, workload, level, strings.Join(violations, ", ")))
+		}
+	}
+	for _, warning := range warnings {
+		r.AddResult(true, warning)
+	}
+	return r, nil
+}
+
+// minimalSCC returns the index in defaultSCCs of the least privileged SCC
+// admitting spec.
+func minimalSCC(spec map[string]interface{}) int {
+	for i, scc := range defaultSCCs {
+		if len(sccViolations(spec, scc)) == 0 {
+			return i
+		}
+	}
+	return len(defaultSCCs) - 1
+}
+
+// containerSecurity is a container of a pod spec along with the security
+// context settings it inherits from the pod.
+type containerSecurity struct {
+	name    string
+	context map[string]interface{}
+	ports   []map[string]interface{}
+}
+
+// podContainerSecurity returns the containers of spec with their security
+// context merged over the one of the pod, for the settings containers
+// inherit.
+func podContainerSecurity(spec map[string]interface{}) []containerSecurity {
+	podContext, _, _ := unstructured.NestedMap(spec, "securityContext")
+	var containers []containerSecurity
+	for _, container := range podContainers(spec) {
+		name, _, _ := unstructured.NestedString(container, "name")
+		context, _, _ := unstructured.NestedMap(container, "securityContext")
+		merged := map[string]interface{}{}
+		for _, field := range []string{"runAsUser", "runAsNonRoot", "seLinuxOptions", "seccompProfile"} {
+			if value, ok := podContext[field]; ok {
+				merged[field] = value
+			}
+		}
+		for field, value := range context {
+			merged[field] = value
+		}
+		containers = append(containers, containerSecurity{name: name, context: merged, ports: nestedMaps(container, "ports")})
+	}
+	return containers
+}
+
+// hostViolations returns the host namespaces, and the host ports of
+// containers, spec uses.
+func hostViolations(spec map[string]interface{}, allowNetwork, allowPorts, allowPID, allowIPC bool) []string {
+	var violations []string
+	for field, allowed := range map[string]bool{"hostNetwork": allowNetwork, "hostPID": allowPID, "hostIPC": allowIPC} {
+		if enabled, _, _ := unstructured.NestedBool(spec, field); enabled && !allowed {
+			violations = append(violations, field)
+		}
+	}
+	slices.Sort(violations)
+	if allowPorts {
+		return violations
+	}
+	for _, container := range podContainerSecurity(spec) {
+		for _, port := range container.ports {
+			if hostPort, found := nestedInt(port, "hostPort"); found && hostPort != 0 {
+				violations = append(violations, fmt.Sprintf("container %q: hostPort %d", container.name, hostPort))
+			}
+		}
+	}
+	return violations
+}
+
+// volumeViolations returns the volumes of spec whose type isn't in types.
+func volumeViolations(spec map[string]interface{}, types []string) []string {
+	if slices.Contains(types, "*") {
+		return nil
+	}
+	var violations []string
+	for _, volume := range nestedMaps(spec, "volumes") {
+		name, _, _ := unstructured.NestedString(volume, "name")
+		for volumeType := range volume {
+			if volumeType != "name" && !slices.Contains(types, volumeType) {
+				violations = append(violations, fmt.Sprintf("volume %q of type %s", name, volumeType))
+			}
+		}
+	}
+	return violations
+}
+
+// addedCapabilities returns the capabilities context adds that aren't in
+// allowed.
+func addedCapabilities(context map[string]interface{}, allowed []string) []string {
+	if slices.Contains(allowed, "*") {
+		return nil
+	}
+	added, _, _ := unstructured.NestedStringSlice(context, "capabilities", "add")
+	var disallowed []string
+	for _, capability := range added {
+		if !slices.Contains(allowed, strings.TrimPrefix(capability, "CAP_")) {
+			disallowed = append(disallowed, capability)
+		}
+	}
+	return disallowed
+}
+
+// sccViolations returns the settings of spec scc doesn't admit.
+func sccViolations(spec map[string]interface{}, scc securityContextConstraints) []string {
+	violations := hostViolations(spec, scc.AllowHostNetwork, scc.AllowHostPorts, scc.AllowHostPID, scc.AllowHostIPC)
+	if fsGroup, found := nestedInt(spec, "securityContext", "fsGroup"); found && !scc.AllowFSGroup {
+		violations = append(violations, fmt.Sprintf("fsGroup %d", fsGroup))
+	}
+	for _, container := range podContainerSecurity(spec) {
+		var containerViolations []string
+		if privileged, _, _ := unstructured.NestedBool(container.context, "privileged"); privileged && !scc.AllowPrivileged {
+			containerViolations = append(containerViolations, "privileged")
+		}
+		if escalation, _, _ := unstructured.NestedBool(container.context, "allowPrivilegeEscalation"); escalation && !scc.AllowPrivilegeEscalation {
+			containerViolations = append(containerViolations, "allowPrivilegeEscalation")
+		}
+		if runAsUser, found := nestedInt(container.context, "runAsUser"); found {
+			if scc.RunAsUser == mustRunAsRange || (scc.RunAsUser == mustRunAsNonRoot && runAsUser == 0) {
+				containerViolations = append(containerViolations, fmt.Sprintf("runAsUser %d", runAsUser))
+			}
+		}
+		if _, found, _ := unstructured.NestedMap(container.context, "seLinuxOptions"); found && !scc.AllowSELinuxOptions {
+			containerViolations = append(containerViolations, "seLinuxOptions")
+		}
+		if seccomp, _, _ := unstructured.NestedString(container.context, "seccompProfile", "type"); seccomp == "Unconfined" && !scc.AllowUnconfinedSeccomp {
+			containerViolations = append(containerViolations, "seccompProfile Unconfined")
+		}
+		if capabilities := addedCapabilities(container.context, scc.AllowedCapabilities); len(capabilities) > 0 {
+			containerViolations = append(containerViolations, fmt.Sprintf("capabilities %s", strings.Join(capabilities, ", ")))
+		}
+		for _, violation := range containerViolations {
+			violations = append(violations, fmt.Sprintf("container %q: %s", container.name, violation))
+		}
+	}
+	return append(violations, volumeViolations(spec, scc.Volumes)...)
+}
+
+// podSecurityLevel returns the most restrictive Pod Security Standards level
+// spec meets, along with the settings of spec violating the next level.
+func podSecurityLevel(spec map[string]interface{}) (string, []string) {
+	if violations := baselineViolations(spec); len(violations) > 0 {
+		return PrivilegedLevel, violations
+	}
+	if violations := restrictedViolations(spec); len(violations) > 0 {
+		return BaselineLevel, violations
+	}
+	return RestrictedLevel, nil
+}
+
+// baselineViolations returns the settings of spec the baseline Pod Security
+// Standard doesn't allow.
+func baselineViolations(spec map[string]interface{}) []string {
+	violations := hostViolations(spec, false, false, false, false)
+	for _, sysctl := range nestedMaps(spec, "securityContext", "sysctls") {
+		if name, _, _ := unstructured.NestedString(sysctl, "name"); !slices.Contains(safeSysctls, name) {
+			violations = append(violations, fmt.Sprintf("sysctl %s", name))
+		}
+	}
+	for _, container := range podContainerSecurity(spec) {
+		var containerViolations []string
+		if privileged, _, _ := unstructured.NestedBool(container.context, "privileged"); privileged {
+			containerViolations = append(containerViolations, "privileged")
+		}
+		if capabilities := addedCapabilities(container.context, baselineCapabilities); len(capabilities) > 0 {
+			containerViolations = append(containerViolations, fmt.Sprintf("capabilities %s", strings.Join(capabilities, ", ")))
+		}
+		seLinuxType, _, _ := unstructured.NestedString(container.context, "seLinuxOptions", "type")
+		seLinuxUser, _, _ := unstructured.NestedString(container.context, "seLinuxOptions", "user")
+		seLinuxRole, _, _ := unstructured.NestedString(container.context, "seLinuxOptions", "role")
+		if !slices.Contains(baselineSELinuxTypes, seLinuxType) || seLinuxUser != "" || seLinuxRole != "" {
+			containerViolations = append(containerViolations, "seLinuxOptions")
+		}
+		if procMount, _, _ := unstructured.NestedString(container.context, "procMount"); procMount != "" && procMount != "Default" {
+			containerViolations = append(containerViolations, fmt.Sprintf("procMount %s", procMount))
+		}
+		if seccomp, _, _ := unstructured.NestedString(container.context, "seccompProfile", "type"); seccomp == "Unconfined" {
+			containerViolations = append(containerViolations, "seccompProfile Unconfined")
+		}
+		for _, violation := range containerViolations {
+			violations = append(violations, fmt.Sprintf("container %q: %s", container.name, violation))
+		}
+	}
+	for _, volume := range nestedMaps(spec, "volumes") {
+		if _, found := volume["hostPath"]; found {
+			name, _, _ := unstructured.NestedString(volume, "name")
+			violations = append(violations, fmt.Sprintf("volume %q of type hostPath", name))
+		}
+	}
+	return violations
+}
+
+// restrictedViolations returns the settings of spec the restricted Pod
+// Security Standard doesn't allow, in addition to the baseline ones.
+func restrictedViolations(spec map[string]interface{}) []string {
+	violations := volumeViolations(spec, restrictedVolumeTypes)
+	for _, container := range podContainerSecurity(spec) {
+		var containerViolations []string
+		if escalation, found, _ := unstructured.NestedBool(container.context, "allowPrivilegeEscalation"); !found || escalation {
+			containerViolations = append(containerViolations, "allowPrivilegeEscalation not false")
+		}
+		if nonRoot, _, _ := unstructured.NestedBool(container.context, "runAsNonRoot"); !nonRoot {
+			containerViolations = append(containerViolations, "runAsNonRoot not true")
+		}
+		if runAsUser, found := nestedInt(container.context, "runAsUser"); found && runAsUser == 0 {
+			containerViolations = append(containerViolations, "runAsUser 0")
+		}
+		if seccomp, _, _ := unstructured.NestedString(container.context, "seccompProfile", "type"); seccomp != "RuntimeDefault" && seccomp != "Localhost" {
+			containerViolations = append(containerViolations, "seccompProfile not RuntimeDefault or Localhost")
+		}
+		dropped, _, _ := unstructured.NestedStringSlice(container.context, "capabilities", "drop")
+		if !slices.Contains(dropped, "ALL") {
+			containerViolations = append(containerViolations, "capabilities not dropping ALL")
+		}
+		if capabilities := addedCapabilities(container.context, []string{"NET_BIND_SERVICE"}); len(capabilities) > 0 {
+			containerViolations = append(containerViolations, fmt.Sprintf("capabilities %s", strings.Join(capabilities, ", ")))
+		}
+		for _, violation := range containerViolations {
+			violations = append(violations, fmt.Sprintf("container %q: %s", container.name, violation))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,229 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestPodSecurity(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/restricted.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: restricted
+spec:
+  securityContext:
+    runAsNonRoot: true
+    seccompProfile:
+      type: RuntimeDefault
+  containers:
+    - name: app
+      securityContext:
+        allowPrivilegeEscalation: false
+        capabilities:
+          drop: [ALL]
+          add: [NET_BIND_SERVICE]
+      ports:
+        - containerPort: 8080
+  volumes:
+    - name: config
+      configMap:
+        name: config
+---
+# Source: chart/templates/baseline.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: baseline
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+---
+# Source: chart/templates/nonroot.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: nonroot
+spec:
+  template:
+    spec:
+      securityContext:
+        fsGroup: 1000
+        runAsUser: 1000
+      containers:
+        - name: app
+---
+# Source: chart/templates/anyuid.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: anyuid
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          securityContext:
+            runAsUser: 0
+            allowPrivilegeEscalation: true
+---
+# Source: chart/templates/hostnetwork.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hostnetwork
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: app
+          ports:
+            - containerPort: 9100
+              hostPort: 9100
+---
+# Source: chart/templates/privileged.yaml
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: privileged
+spec:
+  template:
+    spec:
+      hostPID: true
+      containers:
+        - name: agent
+          securityContext:
+            privileged: true
+            capabilities:
+              add: [SYS_ADMIN]
+      volumes:
+        - name: root
+          hostPath:
+            path: /
+`
+
+	objects, err := decodeRenderedManifests(manifests)
+	require.NoError(t, err)
+
+	type expectation struct {
+		scc        string
+		level      string
+		violations []string
+	}
+	expected := map[string]expectation{
+		"restricted": {scc: "restricted-v2", level: RestrictedLevel},
+		"baseline": {scc: "restricted-v2", level: BaselineLevel, violations: []string{
+			`container "app": allowPrivilegeEscalation not false`,
+			`container "app": runAsNonRoot not true`,
+			`container "app": seccompProfile not RuntimeDefault or Localhost`,
+			`container "app": capabilities not dropping ALL`,
+		}},
+		"nonroot": {scc: "nonroot-v2", level: BaselineLevel},
+		"anyuid":  {scc: "anyuid", level: BaselineLevel},
+		"hostnetwork": {scc: "hostnetwork-v2", level: PrivilegedLevel, violations: []string{
+			"hostNetwork",
+			`container "app": hostPort 9100`,
+		}},
+		"privileged": {scc: "privileged", level: PrivilegedLevel, violations: []string{
+			"hostPID",
+			`container "agent": privileged`,
+			`container "agent": capabilities SYS_ADMIN`,
+			`volume "root" of type hostPath`,
+		}},
+	}
+
+	for _, obj := range objects {
+		spec := podSpec(obj.Unstructured)
+		require.NotNil(t, spec, obj.GetName())
+		require.Equal(t, expected[obj.GetName()].scc, defaultSCCs[minimalSCC(spec)].Name, obj.GetName())
+		level, violations := podSecurityLevel(spec)
+		require.Equal(t, expected[obj.GetName()].level, level, obj.GetName())
+		if expected[obj.GetName()].violations != nil {
+			require.Equal(t, expected[obj.GetName()].violations, violations, obj.GetName())
+		}
+	}
+
+	require.Equal(t, []string{"fsGroup 1000", `container "app": runAsUser 1000`}, sccViolations(podSpec(objects[2].Unstructured), defaultSCCs[0]))
+	require.Equal(t, []string{`container "app": allowPrivilegeEscalation`, `container "app": runAsUser 0`}, sccViolations(podSpec(objects[3].Unstructured), defaultSCCs[1]))
+}
+
+func TestPodSecurityCompatible(t *testing.T) {
+	type testCase struct {
+		description string
+		values      map[string]interface{}
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	restrictedValues := map[string]interface{}{
+		"podSecurityContext": map[string]interface{}{"runAsNonRoot": true, "seccompProfile": map[string]interface{}{"type": "RuntimeDefault"}},
+		"securityContext":    map[string]interface{}{"allowPrivilegeEscalation": false, "capabilities": map[string]interface{}{"drop": []interface{}{"ALL"}}},
+	}
+	rootValues := map[string]interface{}{
+		"podSecurityContext": map[string]interface{}{"runAsNonRoot": true, "seccompProfile": map[string]interface{}{"type": "RuntimeDefault"}},
+		"securityContext":    map[string]interface{}{"allowPrivilegeEscalation": false, "capabilities": map[string]interface{}{"drop": []interface{}{"ALL"}}, "runAsUser": 0},
+	}
+	deployment := `Deployment "test-release-chart" in chart/templates/deployment.yaml`
+	var testPodViolations []string
+	for _, container := range []string{"wget", "web", "driver", "plugin"} {
+		for _, violation := range []string{"allowPrivilegeEscalation not false", "runAsNonRoot not true", "seccompProfile not RuntimeDefault or Localhost", "capabilities not dropping ALL"} {
+			testPodViolations = append(testPodViolations, fmt.Sprintf("container %q: %s", container, violation))
+		}
+	}
+	testPod := PodSecurityNotRestricted + `: Pod "test-release-chart-test-connection" in chart/templates/tests/test-connection.yaml is baseline: ` +
+		strings.Join(testPodViolations, ", ")
+
+	testCases := []testCase{
+		{
+			description: "workloads run with restricted-v2",
+			values:      restrictedValues,
+			ok:          true,
+			reason:      WorkloadsRunWithSCC + ": restricted-v2\n" + testPod,
+		},
+		{
+			description: "workload runs as root",
+			values:      rootValues,
+			ok:          false,
+			reason: WorkloadsNeedPrivilegedSCC + ": restricted-v2\n" +
+				deployment + ` needs the anyuid SCC: container "chart": runAsUser 0` + "\n" +
+				PodSecurityNotRestricted + ": " + deployment + ` is baseline: container "chart": runAsUser 0` + "\n" + testPod,
+		},
+		{
+			description: "workload runs with the configured SCC",
+			values:      rootValues,
+			config:      map[string]interface{}{SCCConfigString: "anyuid"},
+			ok:          true,
+			reason: WorkloadsRunWithSCC + ": anyuid\n" +
+				PodSecurityNotRestricted + ": " + deployment + ` is baseline: container "chart": runAsUser 0` + "\n" + testPod,
+		},
+		{
+			description: "unknown SCC",
+			config:      map[string]interface{}{SCCConfigString: "restricted-v3"},
+			ok:          false,
+			reason:      PodSecurityNotEvaluated + `: unknown SCC "restricted-v3"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			r, err := PodSecurityCompatible(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package checks
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PlatformObjectsConfigString lists, in the Kind/name form, the objects the
+// platform provides in addition to the OpenShift defaults, e.g.
+// StorageClass/gp3-csi or CustomResourceDefinition/certificates.cert-manager.io.
+const PlatformObjectsConfigString string = "platformObjects"
+
+// defaultPlatformObjects are the objects OpenShift provides to every
+// namespace or cluster.
+var defaultPlatformObjects = []string{
+	"ServiceAccount/default",
+	"ServiceAccount/builder",
+	"ServiceAccount/deployer",
+	"ConfigMap/kube-root-ca.crt",
+	"ConfigMap/openshift-service-ca.crt",
+	"PriorityClass/system-cluster-critical",
+	"PriorityClass/system-node-critical",
+	"PriorityClass/openshift-user-critical",
+	"ClusterRole/admin",
+	"ClusterRole/edit",
+	"ClusterRole/view",
+	"ClusterRole/cluster-admin",
+	"IngressClass/openshift-default",
+}
+
+// platformGroups are the API groups served by OpenShift without installing
+// any operator.
+var platformGroups = map[string]bool{
+	"":                             true,
+	"admissionregistration.k8s.io": true,
+	"apiextensions.k8s.io":         true,
+	"apiregistration.k8s.io":       true,
+	"apps":                         true,
+	"apps.openshift.io":            true,
+	"authorization.openshift.io":   true,
+	"autoscaling":                  true,
+	"batch":                        true,
+	"build.openshift.io":           true,
+	"certificates.k8s.io":          true,
+	"config.openshift.io":          true,
+	"console.openshift.io":         true,
+	"coordination.k8s.io":          true,
+	"discovery.k8s.io":             true,
+	"events.k8s.io":                true,
+	"flowcontrol.apiserver.k8s.io": true,
+	"image.openshift.io":           true,
+	"monitoring.coreos.com":        true,
+	"network.openshift.io":         true,
+	"networking.k8s.io":            true,
+	"node.k8s.io":                  true,
+	"operator.openshift.io":        true,
+	"policy":                       true,
+	"project.openshift.io":         true,
+	"quota.openshift.io":           true,
+	"rbac.authorization.k8s.io":    true,
+	"route.openshift.io":           true,
+	"scheduling.k8s.io":            true,
+	"security.openshift.io":        true,
+	"storage.k8s.io":               true,
+	"template.openshift.io":        true,
+	"user.openshift.io":            true,
+}
+
+// servingCertSecretAnnotation requests the service CA operator to create a
+// secret holding a serving certificate for a service.
+const servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+
+// objectReference is a reference from a rendered object to another object.
+type objectReference struct {
+	Kind string
+	Name string
+}
+
+func (r objectReference) String() string {
+	return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+}
+
+// CanBeInstalledWithoutManualPreRequisites renders the chart, with its
+// default values and with each of its CI values files, and checks that every
+// object its objects reference is created by the chart or provided by the
+// platform, so that the chart can be installed without creating objects
+// beforehand.
+func CanBeInstalledWithoutManualPreRequisites(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ManualPreRequisitesNotEvaluated, err)), nil
+	}
+
+	platformObjects := slices.Concat(defaultPlatformObjects, opts.ViperConfig.GetStringSlice(PlatformObjectsConfigString))
+	unresolved := unresolvedReferences(objects, platformObjects)
+	if len(unresolved) == 0 {
+		return NewResult(true, NoManualPreRequisites), nil
+	}
+
+	r := NewResult(false, ManualPreRequisites)
+	for _, reference := range unresolved {
+		r.AddResult(false, reference)
+	}
+	return r, nil
+}
+
+// unresolvedReferences returns a description of each reference of objects to
+// an object that neither objects nor platformObjects contain.
+func unresolvedReferences(objects []renderedObject, platformObjects []string) []string {
+	provided := map[objectReference]bool{}
+	for _, platformObject := range platformObjects {
+		kind, name, found := strings.Cut(platformObject, "/")
+		if found {
+			provided[objectReference{Kind: kind, Name: name}] = true
+		}
+	}
+	for _, obj := range objects {
+		provided[objectReference{Kind: obj.GetKind(), Name: obj.GetName()}] = true
+		if obj.GetKind() == "CustomResourceDefinition" {
+			for _, name := range crdNames(obj.Unstructured) {
+				provided[objectReference{Kind: "CustomResourceDefinition", Name: name}] = true
+			}
+		}
+		if obj.GetKind() == "Service" {
+			if secret := obj.GetAnnotations()[servingCertSecretAnnotation]; secret != "" {
+				provided[objectReference{Kind: "Secret", Name: secret}] = true
+			}
+		}
+	}
+
+	var unresolved []string
+	seen := map[string]bool{}
+	for _, obj := range objects {
+		for _, reference := range objectReferences(obj.Unstructured) {
+			if provided[reference] || isPlatformClusterRole(reference) {
+				continue
+			}
+			description := fmt.Sprintf("%s referenced by %s", reference, obj)
+			if location := obj.Location(); location != "" {
+				description += fmt.Sprintf(" in %s", location)
+			}
+			if !seen[description] {
+				seen[description] = true
+				unresolved = append(unresolved, description)
+			}
+		}
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
+
+// isPlatformClusterRole returns whether reference is to one of the many
+// system ClusterRoles of the platform.
+func isPlatformClusterRole(reference objectReference) bool {
+	return reference.Kind == "ClusterRole" && strings.HasPrefix(reference.Name, "system:")
+}
+
+// crdNames returns the name of the CRD, along with the <plural>.<group> name
+// it must have, in case the chart named it otherwise, and the name guessed
+// from its kind by objectReferences, in case the plural is irregular.
+func crdNames(crd unstructured.Unstructured) []string {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	guessed, _ := meta.UnsafeGuessKindToResource(schema.GroupVersionKind{Group: group, Kind: kind})
+	return []string{crd.GetName(), fmt.Sprintf("%s.%s", plural, group), guessed.GroupResource().String()}
+}
+
+// objectReferences returns the objects obj references.
+func objectReferences(obj unstructured.Unstructured) []objectReference {
+	var references []objectReference
+	add := func(kind, name string) {
+		if name != "" {
+			references = append(references, objectReference{Kind: kind, Name: name})
+		}
+	}
+
+	gvk := obj.GroupVersionKind()
+	if !platformGroups[gvk.Group] {
+		resource, _ := meta.UnsafeGuessKindToResource(gvk)
+		add("CustomResourceDefinition", resource.GroupResource().String())
+	}
+
+	if spec := podSpec(obj); spec != nil {
+		references = append(references, podSpecReferences(spec)...)
+	}
+
+	switch obj.GetKind() {
+	case "PersistentVolumeClaim":
+		storageClass, _, _ := unstructured.NestedString(obj.Object, "spec", "storageClassName")
+		add("StorageClass", storageClass)
+	case "StatefulSet":
+		for _, template := range nestedMaps(obj.Object, "spec", "volumeClaimTemplates") {
+			storageClass, _, _ := unstructured.NestedString(template, "spec", "storageClassName")
+			add("StorageClass", storageClass)
+		}
+	case "Ingress":
+		ingressClass, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressClassName")
+		add("IngressClass", ingressClass)
+	case "RoleBinding", "ClusterRoleBinding":
+		kind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+		name, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+		add(kind, name)
+	}
+	return references
+}
+
+// podSpecReferences returns the objects a pod spec references, except the
+// ones referenced as optional.
+func podSpecReferences(spec map[string]interface{}) []objectReference {
+	var references []objectReference
+	add := func(kind string, obj map[string]interface{}, fields ...string) {
+		name, _, _ := unstructured.NestedString(obj, fields...)
+		optional, _, _ := unstructured.NestedBool(obj, append(fields[:len(fields)-1:len(fields)-1], "optional")...)
+		if name != "" && !optional {
+			references = append(references, objectReference{Kind: kind, Name: name})
+		}
+	}
+
+	add("ServiceAccount", spec, "serviceAccountName")
+	add("PriorityClass", spec, "priorityClassName")
+	add("RuntimeClass", spec, "runtimeClassName")
+	for _, pullSecret := range nestedMaps(spec, "imagePullSecrets") {
+		add("Secret", pullSecret, "name")
+	}
+
+	for _, volume := range nestedMaps(spec, "volumes") {
+		add("Secret", volume, "secret", "secretName")
+		add("ConfigMap", volume, "configMap", "name")
+		add("PersistentVolumeClaim", volume, "persistentVolumeClaim", "claimName")
+		for _, source := range nestedMaps(volume, "projected", "sources") {
+			add("Secret", source, "secret", "name")
+			add("ConfigMap", source, "configMap", "name")
+		}
+	}
+
+	for _, container := range podContainers(spec) {
+		for _, env := range nestedMaps(container, "env") {
+			add("Secret", env, "valueFrom", "secretKeyRef", "name")
+			add("ConfigMap", env, "valueFrom", "configMapKeyRef", "name")
+		}
+		for _, envFrom := range nestedMaps(container, "envFrom") {
+			add("Secret", envFrom, "secretRef", "name")
+			add("ConfigMap", envFrom, "configMapRef", "name")
+		}
+	}
+	return references
+}
@@ -0,0 +1,200 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestUnresolvedReferences(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      serviceAccountName: app
+      priorityClassName: high
+      imagePullSecrets:
+        - name: pull-secret
+      volumes:
+        - name: certs
+          secret:
+            secretName: app-certs
+        - name: config
+          configMap:
+            name: app-config
+        - name: optional
+          configMap:
+            name: optional-config
+            optional: true
+        - name: data
+          persistentVolumeClaim:
+            claimName: app-data
+      containers:
+        - name: app
+          env:
+            - name: PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: db-credentials
+                  key: password
+          envFrom:
+            - configMapRef:
+                name: kube-root-ca.crt
+---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  annotations:
+    service.beta.openshift.io/serving-cert-secret-name: app-certs
+---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+---
+# Source: chart/templates/pvc.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: app-data
+spec:
+  storageClassName: fast
+---
+# Source: chart/templates/rolebinding.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: app
+roleRef:
+  kind: ClusterRole
+  name: view
+---
+# Source: chart/templates/certificate.yaml
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: app
+---
+# Source: chart/templates/widget.yaml
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+---
+# Source: chart/crds/widgets.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+`
+
+	objects, err := decodeRenderedManifests(manifests)
+	require.NoError(t, err)
+	require.Len(t, objects, 8)
+	require.Equal(t, "chart/templates/deployment.yaml", objects[0].Source)
+
+	require.Equal(t, []string{
+		`CustomResourceDefinition/certificates.cert-manager.io referenced by Certificate "app" in chart/templates/certificate.yaml`,
+		`PriorityClass/high referenced by Deployment "app" in chart/templates/deployment.yaml`,
+		`Secret/db-credentials referenced by Deployment "app" in chart/templates/deployment.yaml`,
+		`Secret/pull-secret referenced by Deployment "app" in chart/templates/deployment.yaml`,
+		`ServiceAccount/app referenced by Deployment "app" in chart/templates/deployment.yaml`,
+		`StorageClass/fast referenced by PersistentVolumeClaim "app-data" in chart/templates/pvc.yaml`,
+	}, unresolvedReferences(objects, defaultPlatformObjects))
+
+	require.Equal(t, []string{
+		`PriorityClass/high referenced by Deployment "app" in chart/templates/deployment.yaml`,
+		`Secret/db-credentials referenced by Deployment "app" in chart/templates/deployment.yaml`,
+	}, unresolvedReferences(objects, append([]string{
+		"CustomResourceDefinition/certificates.cert-manager.io",
+		"Secret/pull-secret",
+		"ServiceAccount/app",
+		"StorageClass/fast",
+	}, defaultPlatformObjects...)))
+}
+
+func TestCanBeInstalledWithoutManualPreRequisites(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	ciValuesChart := writeTestChart(t, "demo", map[string]string{
+		"Chart.yaml":         "apiVersion: v2\nname: demo\nversion: 0.1.0\n",
+		"values.yaml":        "serviceAccount: \"\"\n",
+		"ci/sa-values.yaml":  "serviceAccount: app\n",
+		"templates/pod.yaml": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: demo\nspec:\n  serviceAccountName: {{ .Values.serviceAccount | quote }}\n",
+	})
+
+	referencingValues := map[string]interface{}{
+		"imagePullSecrets": []interface{}{map[string]interface{}{"name": "pull-secret"}},
+		"serviceAccount":   map[string]interface{}{"create": false, "name": "app"},
+	}
+
+	testCases := []testCase{
+		{
+			description: "chart creates the objects it references",
+			ok:          true,
+			reason:      NoManualPreRequisites,
+		},
+		{
+			description: "chart references objects it doesn't create",
+			values:      referencingValues,
+			ok:          false,
+			reason: ManualPreRequisites +
+				"\nSecret/pull-secret referenced by Deployment \"test-release-chart\" in chart/templates/deployment.yaml" +
+				"\nServiceAccount/app referenced by Deployment \"test-release-chart\" in chart/templates/deployment.yaml",
+		},
+		{
+			description: "referenced objects are provided by the platform",
+			values:      referencingValues,
+			config: map[string]interface{}{
+				PlatformObjectsConfigString: []string{"Secret/pull-secret", "ServiceAccount/app"},
+			},
+			ok:     true,
+			reason: NoManualPreRequisites,
+		},
+		{
+			description: "CI values file references an object the chart doesn't create",
+			uri:         ciValuesChart,
+			ok:          false,
+			reason:      ManualPreRequisites + "\nServiceAccount/app referenced by Pod \"demo\" in demo/templates/pod.yaml (with ci/sa-values.yaml)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			uri := tc.uri
+			if uri == "" {
+				uri = "chart-0.1.0-v3.valid.tgz"
+			}
+			r, err := CanBeInstalledWithoutManualPreRequisites(&CheckOptions{URI: uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/kube"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
+	"github.com/redhat-certification/chart-verifier/internal/tool"
+)
+
+// RulesFileConfigString is the path of a file holding the Roles and
+// ClusterRoles to evaluate the install permissions against, instead of the
+// permissions of the current user of the cluster.
+const RulesFileConfigString string = "rulesFile"
+
+// clusterScopedKinds are the built-in cluster scoped kinds, used to find the
+// scope of objects when no cluster is available to discover it.
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Kind: "Namespace"}:        true,
+	{Kind: "Node"}:             true,
+	{Kind: "PersistentVolume"}: true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"}:      true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "config.openshift.io", Kind: "ClusterVersion"}:                          true,
+	{Group: "console.openshift.io", Kind: "ConsoleLink"}:                            true,
+	{Group: "console.openshift.io", Kind: "ConsolePlugin"}:                          true,
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                              true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "security.openshift.io", Kind: "SecurityContextConstraints"}:            true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+}
+
+// releaseStorageResources are the resources the helm storage drivers, as
+// selected by HELM_DRIVER, store the releases in. The memory and sql drivers
+// store them outside of the cluster.
+var releaseStorageResources = map[string]string{
+	"":           "secrets",
+	"secret":     "secrets",
+	"secrets":    "secrets",
+	"configmap":  "configmaps",
+	"configmaps": "configmaps",
+}
+
+// installMode describes how helm installs the release, which decides the
+// permissions needed on the objects of the chart and on the release
+// storage.
+type installMode struct {
+	serverSideApply bool
+	upgrade         bool
+	uninstall       bool
+	waitStrategy    kube.WaitStrategy
+	driver          string
+}
+
+// objectVerbs returns the verbs needed on an object of the chart. Helm gets
+// every object to detect conflicts with existing ones, then creates it, or
+// patches it when server-side apply is used. An upgrade also patches the
+// objects of the previous release, and an uninstall deletes them. Hooks are
+// deleted before being created again, the default before-hook-creation
+// policy, and are watched until they complete, as are the other objects
+// unless the wait strategy is hookOnly.
+func (m installMode) objectVerbs(hook bool) []string {
+	var verbs []string
+	switch {
+	case m.serverSideApply:
+		verbs = []string{"get", "patch"}
+	case m.upgrade:
+		verbs = []string{"create", "get", "patch"}
+	default:
+		verbs = []string{"create", "get"}
+	}
+	if hook || m.uninstall {
+		verbs = append(verbs, "delete")
+	}
+	if hook || m.waitStrategy != kube.HookOnlyStrategy {
+		verbs = append(verbs, "list", "watch")
+	}
+	return verbs
+}
+
+// releaseStorageAccess returns the permissions needed to store the release
+// in namespace: helm lists the releases of the same name, creates the
+// release record and then updates its status. An uninstall gets and deletes
+// the release records.
+func (m installMode) releaseStorageAccess(namespace string) []tool.ResourceAccess {
+	resource, ok := releaseStorageResources[m.driver]
+	if !ok {
+		return nil
+	}
+	verbs := []string{"create", "list", "update"}
+	if m.uninstall {
+		verbs = append(verbs, "get", "delete")
+	}
+	var accesses []tool.ResourceAccess
+	for _, verb := range verbs {
+		accesses = append(accesses, tool.ResourceAccess{Verb: verb, Resource: resource, Namespace: namespace})
+	}
+	return accesses
+}
+
+// CanBeInstalledWithoutClusterAdminPrivileges renders the chart, with its
+// default values and each of its CI values files, and checks that the
+// permissions needed to install every object it contains are granted, either
+// to the current user of the cluster, through SelfSubjectAccessReviews, or by
+// the Roles and ClusterRoles of the rulesFile setting.
+func CanBeInstalledWithoutClusterAdminPrivileges(opts *CheckOptions) (Result, error) {
+	kubeVersionString := defaultMockedKubeVersionString
+	if userKubeVersion := opts.ViperConfig.GetString("kube-version"); userKubeVersion != "" {
+		kubeVersionString = userKubeVersion
+	}
+
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", InstallPermissionsNotEvaluated, err)), nil
+	}
+	valuesSets, err := ciValuesSets(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", InstallPermissionsNotEvaluated, err)), nil
+	}
+	manifests, err := renderValuesSetsManifests(opts.URI, valuesSets, kubeVersionString)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", InstallPermissionsNotEvaluated, err)), nil
+	}
+
+	namespace := opts.ViperConfig.GetString("namespace")
+	if namespace == "" {
+		namespace = opts.HelmEnvSettings.Namespace()
+	}
+
+	mode := installMode{
+		serverSideApply: opts.ServerSideApply,
+		upgrade:         opts.Upgrade,
+		waitStrategy:    kube.WaitStrategy(opts.WaitStrategy),
+		driver:          os.Getenv("HELM_DRIVER"),
+	}
+
+	if rulesFile := opts.ViperConfig.GetString(RulesFileConfigString); rulesFile != "" {
+		roles, clusterRoles, err := readRoles(rulesFile)
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s: error reading %s: %v", InstallPermissionsNotEvaluated, rulesFile, err)), nil
+		}
+		accesses, err := requiredAccess(manifests, nil, namespace, mode)
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s: %v", InstallPermissionsNotEvaluated, err)), nil
+		}
+		return installPermissionsResult(tool.MissingAccessForRoles(accesses, roles, clusterRoles)), nil
+	}
+
+	kubectl, err := tool.NewKubectl(tool.GetClientConfig(opts.HelmEnvSettings))
+	if err != nil {
+		return NewSkippedResult(fmt.Sprintf("%s: no cluster available and %s not set: %v", InstallPermissionsNotEvaluated, RulesFileConfigString, err)), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	missing, err := missingInstallAccess(ctx, kubectl, manifests, namespace, mode)
+	if err != nil {
+		return NewSkippedResult(fmt.Sprintf("%s: %v", InstallPermissionsNotEvaluated, err)), nil
+	}
+	return installPermissionsResult(missing), nil
+}
+
+// missingInstallAccess returns the permissions needed to install manifests in
+// namespace that the current user of the cluster is not granted.
+func missingInstallAccess(ctx context.Context, kubectl *tool.Kubectl, manifests, namespace string, mode installMode) ([]tool.ResourceAccess, error) {
+	mapper, err := kubectl.RESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("error discovering the cluster resources: %w", err)
+	}
+	accesses, err := requiredAccess(manifests, mapper, namespace, mode)
+	if err != nil {
+		return nil, err
+	}
+	return kubectl.MissingAccess(ctx, accesses)
+}
+
+// missingChartTestingAccess returns the permissions needed to install the
+// chart in namespace that the current user of the cluster is not granted.
+// Like the install, the chart is rendered with each of its CI values files,
+// or with its default values when it has none, at the version of the
+// cluster.
+func missingChartTestingAccess(ctx context.Context, opts *CheckOptions, kubectl *tool.Kubectl, namespace string, mode installMode) ([]tool.ResourceAccess, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return nil, err
+	}
+	valuesSets, err := ciValuesSets(opts, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(valuesSets) > 1 {
+		valuesSets = valuesSets[1:]
+	}
+
+	kubeVersionString := defaultMockedKubeVersionString
+	if serverVersion, err := kubectl.GetServerVersion(); err == nil {
+		kubeVersionString = serverVersion.GitVersion
+	}
+
+	manifests, err := renderValuesSetsManifests(opts.URI, valuesSets, kubeVersionString)
+	if err != nil {
+		return nil, err
+	}
+	return missingInstallAccess(ctx, kubectl, manifests, namespace, mode)
+}
+
+// renderValuesSetsManifests returns the manifests of the chart at uri
+// rendered for kubeVersion with each of valuesSets.
+func renderValuesSetsManifests(uri string, valuesSets []valuesSet, kubeVersion string) (string, error) {
+	rendered := make([]string, 0, len(valuesSets))
+	for _, set := range valuesSets {
+		manifests, err := renderManifests(uri, set.values, kubeVersion)
+		if err != nil {
+			if set.file != "" {
+				return "", fmt.Errorf("error rendering the chart with %s: %w", set.file, err)
+			}
+			return "", fmt.Errorf("error rendering the chart: %w", err)
+		}
+		rendered = append(rendered, manifests)
+	}
+	return strings.Join(rendered, "\n---\n"), nil
+}
+
+func installPermissionsResult(missing []tool.ResourceAccess) Result {
+	if len(missing) == 0 {
+		return NewResult(true, InstallPermissionsGranted)
+	}
+	return NewResult(false, missingInstallAccessReason(missing))
+}
+
+func missingInstallAccessReason(missing []tool.ResourceAccess) string {
+	permissions := make([]string, 0, len(missing))
+	for _, access := range missing {
+		permissions = append(permissions, access.String())
+	}
+	return fmt.Sprintf("%s: %s", InstallPermissionsMissing, strings.Join(permissions, ", "))
+}
+
+// requiredAccess returns the permissions needed to install the objects of
+// manifests as described by mode, in namespace when they are namespaced and
+// their namespace is not set, and to store the helm release. The resources of the objects are
+// found through mapper when set; otherwise, or when the cluster doesn't know
+// their kind yet, their scope is taken from the CRDs of manifests or the
+// built-in cluster scoped kinds.
+func requiredAccess(manifests string, mapper meta.RESTMapper, namespace string, mode installMode) ([]tool.ResourceAccess, error) {
+	objects, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterScoped := map[schema.GroupKind]bool{}
+	for gk, scoped := range clusterScopedKinds {
+		clusterScoped[gk] = scoped
+	}
+	for _, obj := range objects {
+		if obj.GroupVersionKind().GroupKind() != (schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}) {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+		clusterScoped[schema.GroupKind{Group: group, Kind: kind}] = scope == "Cluster"
+	}
+
+	accesses := map[tool.ResourceAccess]bool{}
+	for _, access := range mode.releaseStorageAccess(namespace) {
+		accesses[access] = true
+	}
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		var resource schema.GroupVersionResource
+		var namespaced bool
+		if mapping, err := restMapping(mapper, gvk); err == nil {
+			resource = mapping.Resource
+			namespaced = mapping.Scope.Name() == meta.RESTScopeNameNamespace
+		} else {
+			resource, _ = meta.UnsafeGuessKindToResource(gvk)
+			namespaced = !clusterScoped[gvk.GroupKind()]
+		}
+
+		objectNamespace := ""
+		if namespaced {
+			objectNamespace = obj.GetNamespace()
+			if objectNamespace == "" {
+				objectNamespace = namespace
+			}
+		}
+		_, hook := obj.GetAnnotations()[hookAnnotation]
+		for _, verb := range mode.objectVerbs(hook) {
+			accesses[tool.ResourceAccess{Verb: verb, Group: resource.Group, Resource: resource.Resource, Namespace: objectNamespace}] = true
+		}
+	}
+
+	required := make([]tool.ResourceAccess, 0, len(accesses))
+	for access := range accesses {
+		required = append(required, access)
+	}
+	sort.Slice(required, func(i, j int) bool { return required[i].String() < required[j].String() })
+	return required, nil
+}
+
+func restMapping(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	if mapper == nil {
+		return nil, errors.New("no mapper")
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// decodeManifests returns the objects of the YAML documents of manifests.
+func decodeManifests(manifests string) ([]unstructured.Unstructured, error) {
+	var objects []unstructured.Unstructured
+	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(manifests), 4096)
+	for {
+		obj := unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding the rendered manifests: %w", err)
+		}
+		if obj.Object == nil || obj.GetKind() == "" {
+			continue
+		}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, *item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// readRoles returns the Roles and ClusterRoles of the YAML documents of the
+// file at path.
+func readRoles(path string) ([]rbacv1.Role, []rbacv1.ClusterRole, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	objects, err := decodeManifests(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var roles []rbacv1.Role
+	var clusterRoles []rbacv1.ClusterRole
+	for _, obj := range objects {
+		switch obj.GroupVersionKind().GroupKind() {
+		case schema.GroupKind{Group: rbacv1.GroupName, Kind: "Role"}:
+			role := rbacv1.Role{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &role); err != nil {
+				return nil, nil, err
+			}
+			roles = append(roles, role)
+		case schema.GroupKind{Group: rbacv1.GroupName, Kind: "ClusterRole"}:
+			clusterRole := rbacv1.ClusterRole{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &clusterRole); err != nil {
+				return nil, nil, err
+			}
+			clusterRoles = append(clusterRoles, clusterRole)
+		default:
+			utils.LogWarning(fmt.Sprintf("Ignoring %s %s of %s, only Roles and ClusterRoles are evaluated", obj.GetKind(), obj.GetName(), path))
+		}
+	}
+	if len(roles) == 0 && len(clusterRoles) == 0 {
+		return nil, nil, errors.New("no Role or ClusterRole found")
+	}
+	return roles, clusterRoles, nil
+}
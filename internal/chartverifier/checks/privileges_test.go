@@ -0,0 +1,281 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+
+	"github.com/redhat-certification/chart-verifier/internal/tool"
+)
+
+func TestRequiredAccess(t *testing.T) {
+	manifests := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: app
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  scope: Cluster
+  names:
+    kind: Widget
+    plural: widgets
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+---
+# empty document
+`
+
+	accesses, err := requiredAccess(manifests, nil, "ns", installMode{waitStrategy: kube.HookOnlyStrategy})
+	require.NoError(t, err)
+	require.Equal(t, []tool.ResourceAccess{
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+		{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "ns"},
+		{Verb: "create", Resource: "secrets", Namespace: "ns"},
+		{Verb: "create", Resource: "services", Namespace: "other"},
+		{Verb: "create", Group: "example.com", Resource: "widgets"},
+		{Verb: "get", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+		{Verb: "get", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "get", Group: "apps", Resource: "deployments", Namespace: "ns"},
+		{Verb: "get", Resource: "services", Namespace: "other"},
+		{Verb: "get", Group: "example.com", Resource: "widgets"},
+		{Verb: "list", Resource: "secrets", Namespace: "ns"},
+		{Verb: "update", Resource: "secrets", Namespace: "ns"},
+	}, accesses)
+
+	_, err = requiredAccess("kind: [", nil, "ns", installMode{})
+	require.ErrorContains(t, err, "error decoding the rendered manifests")
+}
+
+func TestRequiredAccessInstallMode(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+
+	type testCase struct {
+		description string
+		mode        installMode
+		accesses    []tool.ResourceAccess
+	}
+
+	testCases := []testCase{
+		{
+			description: "server-side apply",
+			mode:        installMode{serverSideApply: true, upgrade: true, waitStrategy: kube.HookOnlyStrategy},
+			accesses: []tool.ResourceAccess{
+				{Verb: "create", Resource: "secrets", Namespace: "ns"},
+				{Verb: "get", Resource: "services", Namespace: "ns"},
+				{Verb: "list", Resource: "secrets", Namespace: "ns"},
+				{Verb: "patch", Resource: "services", Namespace: "ns"},
+				{Verb: "update", Resource: "secrets", Namespace: "ns"},
+			},
+		},
+		{
+			description: "upgrade with configmap driver",
+			mode:        installMode{upgrade: true, waitStrategy: kube.HookOnlyStrategy, driver: "configmap"},
+			accesses: []tool.ResourceAccess{
+				{Verb: "create", Resource: "configmaps", Namespace: "ns"},
+				{Verb: "create", Resource: "services", Namespace: "ns"},
+				{Verb: "get", Resource: "services", Namespace: "ns"},
+				{Verb: "list", Resource: "configmaps", Namespace: "ns"},
+				{Verb: "patch", Resource: "services", Namespace: "ns"},
+				{Verb: "update", Resource: "configmaps", Namespace: "ns"},
+			},
+		},
+		{
+			description: "memory driver",
+			mode:        installMode{waitStrategy: kube.HookOnlyStrategy, driver: "memory"},
+			accesses: []tool.ResourceAccess{
+				{Verb: "create", Resource: "services", Namespace: "ns"},
+				{Verb: "get", Resource: "services", Namespace: "ns"},
+			},
+		},
+		{
+			description: "uninstall and wait",
+			mode:        installMode{uninstall: true, waitStrategy: kube.StatusWatcherStrategy},
+			accesses: []tool.ResourceAccess{
+				{Verb: "create", Resource: "secrets", Namespace: "ns"},
+				{Verb: "create", Resource: "services", Namespace: "ns"},
+				{Verb: "delete", Resource: "secrets", Namespace: "ns"},
+				{Verb: "delete", Resource: "services", Namespace: "ns"},
+				{Verb: "get", Resource: "secrets", Namespace: "ns"},
+				{Verb: "get", Resource: "services", Namespace: "ns"},
+				{Verb: "list", Resource: "secrets", Namespace: "ns"},
+				{Verb: "list", Resource: "services", Namespace: "ns"},
+				{Verb: "update", Resource: "secrets", Namespace: "ns"},
+				{Verb: "watch", Resource: "services", Namespace: "ns"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			accesses, err := requiredAccess(manifests, nil, "ns", tc.mode)
+			require.NoError(t, err)
+			require.Equal(t, tc.accesses, accesses)
+		})
+	}
+}
+
+func TestRequiredAccessHooks(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: Pod
+metadata:
+  name: app-test
+  annotations:
+    helm.sh/hook: test
+`
+
+	accesses, err := requiredAccess(manifests, nil, "ns", installMode{waitStrategy: kube.HookOnlyStrategy, driver: "memory"})
+	require.NoError(t, err)
+	require.Equal(t, []tool.ResourceAccess{
+		{Verb: "create", Resource: "pods", Namespace: "ns"},
+		{Verb: "delete", Resource: "pods", Namespace: "ns"},
+		{Verb: "get", Resource: "pods", Namespace: "ns"},
+		{Verb: "list", Resource: "pods", Namespace: "ns"},
+		{Verb: "watch", Resource: "pods", Namespace: "ns"},
+	}, accesses)
+}
+
+func TestCanBeInstalledWithoutClusterAdminPrivileges(t *testing.T) {
+	writeRules := func(t *testing.T, rules string) string {
+		path := filepath.Join(t.TempDir(), "rules.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(rules), 0o600))
+		return path
+	}
+
+	type testCase struct {
+		description string
+		rules       string
+		ok          bool
+		reason      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "role grants every permission",
+			rules: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: installer
+rules:
+  - apiGroups: ["", "apps", "autoscaling", "networking.k8s.io"]
+    resources: ["*"]
+    verbs: ["create", "delete", "get", "list", "update", "watch"]
+`,
+			ok:     true,
+			reason: InstallPermissionsGranted,
+		},
+		{
+			description: "cluster role misses permissions",
+			rules: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: installer
+rules:
+  - apiGroups: ["apps"]
+    resources: ["deployments"]
+    verbs: ["create", "get", "list", "watch"]
+  - apiGroups: [""]
+    resources: ["secrets"]
+    verbs: ["create", "list", "update"]
+`,
+			ok:     false,
+			reason: InstallPermissionsMissing + ": create pods in namespace default, create serviceaccounts in namespace default, create services in namespace default, delete pods in namespace default, get pods in namespace default, get serviceaccounts in namespace default, get services in namespace default, list pods in namespace default, list serviceaccounts in namespace default, list services in namespace default, watch pods in namespace default, watch serviceaccounts in namespace default, watch services in namespace default",
+		},
+		{
+			description: "no roles",
+			rules: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: rules
+`,
+			ok:     false,
+			reason: InstallPermissionsNotEvaluated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(RulesFileConfigString, writeRules(t, tc.rules))
+			config.Set("namespace", "default")
+			r, err := CanBeInstalledWithoutClusterAdminPrivileges(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Contains(t, r.Reason, tc.reason)
+		})
+	}
+
+	t.Run("CI values files and upgrade", func(t *testing.T) {
+		chartPath := writeTestChart(t, "demo", map[string]string{
+			"Chart.yaml":  "apiVersion: v2\nname: demo\nversion: 0.1.0\n",
+			"values.yaml": "clusterRole: false\n",
+			"templates/cm.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+`,
+			"templates/clusterrole.yaml": `{{- if .Values.clusterRole }}
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: demo
+{{- end }}
+`,
+			"ci/rbac-values.yaml": "clusterRole: true\n",
+		})
+		config := viper.New()
+		config.Set(RulesFileConfigString, writeRules(t, `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: installer
+rules:
+  - apiGroups: [""]
+    resources: ["*"]
+    verbs: ["create", "get", "list", "update", "watch"]
+`))
+		config.Set("namespace", "default")
+		r, err := CanBeInstalledWithoutClusterAdminPrivileges(&CheckOptions{URI: chartPath, ViperConfig: config, HelmEnvSettings: cli.New(), Upgrade: true})
+		require.NoError(t, err)
+		require.False(t, r.Ok)
+		require.Contains(t, r.Reason, "create clusterroles.rbac.authorization.k8s.io (cluster scope)")
+		require.Contains(t, r.Reason, "patch configmaps in namespace default")
+	})
+
+	t.Run("no cluster", func(t *testing.T) {
+		settings := cli.New()
+		settings.KubeConfig = filepath.Join(t.TempDir(), "kubeconfig")
+		r, err := CanBeInstalledWithoutClusterAdminPrivileges(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: viper.New(), HelmEnvSettings: settings})
+		require.NoError(t, err)
+		require.True(t, r.Skipped)
+		require.Contains(t, r.Reason, InstallPermissionsNotEvaluated)
+	})
+}
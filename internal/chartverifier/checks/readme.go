@@ -0,0 +1,192 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+const (
+	// ReadmeSectionsConfigString lists the sections the README must have. A
+	// section lists alternative names separated by slashes, and matches the
+	// headings with a word starting with one of them.
+	ReadmeSectionsConfigString string = "sections"
+	// ReadmeValuesTableConfigString requires a table documenting the
+	// top-level keys of values.yaml when true, the default.
+	ReadmeValuesTableConfigString string = "valuesTable"
+	// ReadmePlaceholdersConfigString lists the regular expressions matching
+	// placeholder text.
+	ReadmePlaceholdersConfigString string = "placeholders"
+)
+
+var (
+	defaultReadmeSections     = []string{"Prerequisite/Requirement", "Install", "Configuration/Parameters/Values", "Uninstall"}
+	defaultReadmePlaceholders = []string{`(?i)lorem ipsum`, `\bTODO\b`, `\bTBD\b`, `\bFIXME\b`, `(?i)\bcoming soon\b`, `(?i)\binsert .+ here\b`}
+)
+
+// readmeDocument holds the parts of a README the check looks at.
+type readmeDocument struct {
+	// Headings are the texts of the headings.
+	Headings []string
+	// TableKeys are the first cells of the body rows of the tables.
+	TableKeys []string
+	// Text is the text outside of code blocks, one line per heading,
+	// paragraph or cell.
+	Text []string
+}
+
+// HasReadme_V1_1 checks that the chart has a README with the required
+// sections, a table documenting the top-level keys of its default values, and
+// no placeholder text.
+//
+//nolint:stylecheck // Note(komish) separating numeric values is a valid use case for underscores.
+func HasReadme_V1_1(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var readme []byte
+	hasReadme := false
+	for _, f := range c.Files {
+		if f.Name == "README.md" {
+			readme, hasReadme = f.Data, true
+			break
+		}
+	}
+	if !hasReadme {
+		return NewResult(false, ReadmeDoesNotExist), nil
+	}
+
+	config := opts.ViperConfig
+	sections := defaultReadmeSections
+	if config.IsSet(ReadmeSectionsConfigString) {
+		sections = config.GetStringSlice(ReadmeSectionsConfigString)
+	}
+	placeholders := defaultReadmePlaceholders
+	if config.IsSet(ReadmePlaceholdersConfigString) {
+		placeholders = config.GetStringSlice(ReadmePlaceholdersConfigString)
+	}
+	valuesTable := true
+	if config.IsSet(ReadmeValuesTableConfigString) {
+		valuesTable = config.GetBool(ReadmeValuesTableConfigString)
+	}
+
+	doc := parseReadme(readme)
+	r := NewResult(true, ReadmeComplete)
+	if missing := missingSections(doc, sections); len(missing) > 0 {
+		addReason(&r, ReadmeIncomplete, "missing sections: "+strings.Join(missing, ", "))
+	}
+	if valuesTable {
+		var undocumented []string
+		for key := range c.Values {
+			if !slices.Contains(dependencyValuesKeys(c), key) && !documentedValue(doc, key) {
+				undocumented = append(undocumented, key)
+			}
+		}
+		if len(undocumented) > 0 {
+			sort.Strings(undocumented)
+			addReason(&r, ReadmeIncomplete, "values missing from the values table: "+strings.Join(undocumented, ", "))
+		}
+	}
+	found, err := placeholderText(doc, placeholders)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ReadmeNotEvaluated, err)), nil
+	}
+	if len(found) > 0 {
+		addReason(&r, ReadmeIncomplete, "placeholder text: "+strings.Join(found, ", "))
+	}
+	return r, nil
+}
+
+// parseReadme parses the Markdown of data.
+func parseReadme(data []byte) readmeDocument {
+	var doc readmeDocument
+	root := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions)).Parse(data)
+	root.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if !entering {
+			return blackfriday.GoToNext
+		}
+		switch node.Type {
+		case blackfriday.Heading:
+			doc.Headings = append(doc.Headings, nodeText(node))
+			doc.Text = append(doc.Text, nodeText(node))
+			return blackfriday.SkipChildren
+		case blackfriday.TableCell:
+			text := nodeText(node)
+			if !node.IsHeader && node.Prev == nil {
+				doc.TableKeys = append(doc.TableKeys, text)
+			}
+			doc.Text = append(doc.Text, text)
+			return blackfriday.SkipChildren
+		case blackfriday.Paragraph:
+			doc.Text = append(doc.Text, nodeText(node))
+			return blackfriday.SkipChildren
+		}
+		return blackfriday.GoToNext
+	})
+	return doc
+}
+
+// nodeText returns the text of node and its children, except code blocks.
+func nodeText(node *blackfriday.Node) string {
+	var text strings.Builder
+	node.Walk(func(child *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		switch {
+		case !entering:
+		case child.Type == blackfriday.CodeBlock:
+			return blackfriday.SkipChildren
+		case child.Type == blackfriday.Softbreak || child.Type == blackfriday.Hardbreak:
+			text.WriteString(" ")
+		default:
+			text.Write(child.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+	return strings.TrimSpace(text.String())
+}
+
+// missingSections returns the sections without a heading of doc.
+func missingSections(doc readmeDocument, sections []string) []string {
+	var missing []string
+	for _, section := range sections {
+		names := strings.Split(strings.ToLower(section), "/")
+		if !slices.ContainsFunc(doc.Headings, func(heading string) bool {
+			return slices.ContainsFunc(strings.Fields(strings.ToLower(heading)), func(word string) bool {
+				return slices.ContainsFunc(names, func(name string) bool { return strings.HasPrefix(word, strings.TrimSpace(name)) })
+			})
+		}) {
+			missing = append(missing, section)
+		}
+	}
+	return missing
+}
+
+// documentedValue returns whether a table of doc documents the top-level
+// values key, by itself or by one of its nested keys such as key.name.
+func documentedValue(doc readmeDocument, key string) bool {
+	return slices.ContainsFunc(doc.TableKeys, func(tableKey string) bool {
+		return tableKey == key || strings.HasPrefix(tableKey, key+".") || strings.HasPrefix(tableKey, key+"[")
+	})
+}
+
+// placeholderText returns the text of doc matching placeholders.
+func placeholderText(doc readmeDocument, placeholders []string) ([]string, error) {
+	var found []string
+	for _, placeholder := range placeholders {
+		re, err := regexp.Compile(placeholder)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling the placeholder %q: %w", placeholder, err)
+		}
+		for _, text := range doc.Text {
+			if match := re.FindString(text); match != "" && !slices.Contains(found, fmt.Sprintf("%q", match)) {
+				found = append(found, fmt.Sprintf("%q", match))
+			}
+		}
+	}
+	return found, nil
+}
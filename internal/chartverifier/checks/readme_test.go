@@ -0,0 +1,121 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+const testReadme = "# Demo\n\nA demo chart.\n\n" +
+	"## Prerequisites\n\n- Kubernetes 1.25+\n\n" +
+	"## Installing the Chart\n\n```\nhelm install demo ./demo # TODO\n```\n\n" +
+	"## Uninstalling the Chart\n\n```\nhelm uninstall demo\n```\n\n" +
+	"## Parameters\n\n" +
+	"| Name | Description | Value |\n" +
+	"|------|-------------|-------|\n" +
+	"| `replicaCount` | Number of replicas | `1` |\n" +
+	"| `image.repository` | Image repository | `quay.io/example/demo` |\n" +
+	"| `image.tag` | Image tag | `\"\"` |\n"
+
+// readmeChart writes a chart with readme, unless empty, and values, and
+// returns its directory.
+func readmeChart(t *testing.T, readme, values string) string {
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: demo\nversion: 0.1.0\ndependencies:\n  - name: redis\n    version: 1.0.0\n",
+		"values.yaml": values,
+		"README.md":   readme,
+	}
+	if readme == "" {
+		delete(files, "README.md")
+	}
+	return writeTestChart(t, "demo", files)
+}
+
+func TestParseReadme(t *testing.T) {
+	doc := parseReadme([]byte(testReadme))
+	require.Equal(t, []string{"Demo", "Prerequisites", "Installing the Chart", "Uninstalling the Chart", "Parameters"}, doc.Headings)
+	require.Equal(t, []string{"replicaCount", "image.repository", "image.tag"}, doc.TableKeys)
+	require.NotContains(t, doc.Text, "helm install demo ./demo # TODO")
+
+	require.Equal(t, []string{"Configuration/Parameters/Values"}, missingSections(parseReadme([]byte("## Install\n## Uninstall\n## Requirements\n")), defaultReadmeSections))
+	require.Equal(t, []string{"Install"}, missingSections(parseReadme([]byte("## Uninstalling\n")), []string{"Install", "Uninstall"}))
+}
+
+func TestHasReadme_V1_1(t *testing.T) {
+	type testCase struct {
+		description string
+		readme      string
+		values      string
+		config      map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	values := "replicaCount: 1\nimage:\n  repository: quay.io/example/demo\n  tag: \"\"\nredis:\n  enabled: true\nglobal: {}\n"
+
+	testCases := []testCase{
+		{
+			description: "README documents the chart",
+			readme:      testReadme,
+			values:      values,
+			ok:          true,
+			reason:      ReadmeComplete,
+		},
+		{
+			description: "no README",
+			values:      values,
+			ok:          false,
+			reason:      ReadmeDoesNotExist,
+		},
+		{
+			description: "empty README",
+			readme:      " ",
+			values:      values,
+			ok:          false,
+			reason: ReadmeIncomplete +
+				"\nmissing sections: Prerequisite/Requirement, Install, Configuration/Parameters/Values, Uninstall" +
+				"\nvalues missing from the values table: image, replicaCount",
+		},
+		{
+			description: "README with placeholders and undocumented values",
+			readme:      testReadme + "\nTODO: describe the ingress.\n\nLorem ipsum dolor sit amet.\n",
+			values:      values + "ingress:\n  enabled: false\nservice: {}\n",
+			ok:          false,
+			reason: ReadmeIncomplete +
+				"\nvalues missing from the values table: ingress, service" +
+				"\nplaceholder text: \"Lorem ipsum\", \"TODO\"",
+		},
+		{
+			description: "README with the configured sections",
+			readme:      "# Demo\n\n## Usage\n",
+			values:      values,
+			config:      map[string]interface{}{ReadmeSectionsConfigString: []string{"Usage"}, ReadmeValuesTableConfigString: false},
+			ok:          true,
+			reason:      ReadmeComplete,
+		},
+		{
+			description: "invalid placeholder",
+			readme:      testReadme,
+			values:      values,
+			config:      map[string]interface{}{ReadmePlaceholdersConfigString: []string{"("}},
+			ok:          false,
+			reason:      ReadmeNotEvaluated + ": error compiling the placeholder \"(\": error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
+			uri := readmeChart(t, tc.readme, tc.values)
+			r, err := HasReadme_V1_1(&CheckOptions{URI: uri, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
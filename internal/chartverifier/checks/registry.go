@@ -23,6 +23,7 @@ import (
 	helmcli "helm.sh/helm/v4/pkg/cli"
 
 	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
 type Result struct {
@@ -33,6 +34,11 @@ type Result struct {
 	// Reason for the result value.  This is a message indicating
 	// the reason for the value of Ok became true or false.
 	Reason string
+	// ValuesFileResults holds the per values file breakdown of checks
+	// installing the chart.
+	ValuesFileResults []apiReport.ValuesFileResult
+	// Findings details the reason of the result.
+	Findings []apiReport.Finding
 }
 
 func NewResult(outcome bool, reason string) Result {
@@ -78,10 +84,19 @@ func (r *Result) AddResult(outcome bool, reason string) Result {
 	return *r
 }
 
+// AddFinding adds finding to the findings of r, leaving its outcome as is.
+func (r *Result) AddFinding(finding apiReport.Finding) Result {
+	r.Findings = append(r.Findings, finding)
+	return *r
+}
+
 type AnnotationHolder interface {
 	SetCertifiedOpenShiftVersion(version string)
 	GetCertifiedOpenShiftVersionFlag() string
 	SetSupportedOpenShiftVersions(versions string)
+	SetUpgradedFrom(chart, version string)
+	SetApplyOptions(serverSideApply bool, waitStrategy string)
+	SetTestEnvironment(environment apiReport.TestEnvironment)
 }
 
 type CheckID struct {
@@ -92,6 +107,8 @@ type Check struct {
 	CheckID CheckID
 	Type    apiChecks.CheckType
 	Func    CheckFunc
+	// Config is the configuration of the check set by the profile.
+	Config map[string]interface{}
 }
 
 // CheckOptions contains options collected from the environment a check can
@@ -115,6 +132,16 @@ type CheckOptions struct {
 	HelmInstallTimeout time.Duration
 	// skip helm cleanup
 	SkipCleanup bool
+	// apply releases using server-side apply
+	ServerSideApply bool
+	// helm wait strategy: legacy, watcher or hookOnly
+	WaitStrategy string
+	// upgrade the release, as set by the upgrade setting of chart-testing
+	Upgrade bool
+	// provider of the ephemeral cluster the checks run against, if any
+	EphemeralCluster string
+	// checks of the profile, applied to the subcharts by the dependency audit
+	ProfileChecks []Check
 }
 
 type CheckFunc func(options *CheckOptions) (Result, error)
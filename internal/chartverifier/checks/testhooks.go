@@ -0,0 +1,122 @@
+package checks
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+)
+
+// CertifyTestImagesConfigString certifies the images of the test hooks, as
+// images-are-certified does, when true. By default they are certified when
+// images-are-certified is run.
+const CertifyTestImagesConfigString string = "certifyImages"
+
+var (
+	podGroupKind = schema.GroupKind{Kind: "Pod"}
+	jobGroupKind = schema.GroupKind{Group: "batch", Kind: "Job"}
+)
+
+const (
+	hookAnnotation             = "helm.sh/hook"
+	hookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+)
+
+// ContainsTest_V1_1 renders the chart and checks that it has at least one
+// Pod or Job test hook, that its test hooks have a delete policy and that
+// their images are certified.
+//
+//nolint:stylecheck // Note(komish) separating numeric values is a valid use case for underscores.
+func ContainsTest_V1_1(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	objects, err := renderChartObjects(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ChartTestsNotEvaluated, err)), nil
+	}
+
+	var hooks []renderedObject
+	for _, obj := range objects {
+		if isTestHook(obj) {
+			hooks = append(hooks, obj)
+		}
+	}
+	if len(hooks) == 0 {
+		return NewResult(false, ChartTestHooksDoNotExist), nil
+	}
+
+	r := NewResult(true, ChartTestHooksExist)
+	images := map[string]bool{}
+	for _, hook := range hooks {
+		if _, found := hook.GetAnnotations()[hookDeletePolicyAnnotation]; !found {
+			description := hook.String()
+			if location := hook.Location(); location != "" {
+				description += fmt.Sprintf(" in %s", location)
+			}
+			addReason(&r, ChartTestHooksNotValid, fmt.Sprintf("%s: no %s annotation", description, hookDeletePolicyAnnotation))
+		}
+		for _, container := range podContainers(podSpec(hook.Unstructured)) {
+			if image, _, _ := unstructured.NestedString(container, "image"); image != "" {
+				images[image] = true
+			}
+		}
+	}
+
+	for _, f := range c.Templates {
+		if !strings.HasPrefix(f.Name, TestTemplatePrefix) || !strings.HasSuffix(f.Name, ".yaml") {
+			continue
+		}
+		source := c.Name() + "/" + f.Name
+		if !slices.ContainsFunc(hooks, func(hook renderedObject) bool { return hook.Source == source }) {
+			r.AddResult(true, fmt.Sprintf("%s: %s", ChartTestTemplateNotHook, source))
+		}
+	}
+
+	if !certifyTestImages(opts) {
+		return r, nil
+	}
+	testImages := make([]string, 0, len(images))
+	for image := range images {
+		testImages = append(testImages, image)
+	}
+	sort.Strings(testImages)
+	certified := certifyImageReferences(NewResult(true, ""), testImages, RedHatRegistry)
+	if !certified.Ok {
+		addReason(&r, ChartTestHooksNotValid, "test images: "+strings.ReplaceAll(certified.Reason, "\n", "; "))
+	}
+	r.Findings = append(r.Findings, certified.Findings...)
+	return r, nil
+}
+
+// certifyTestImages returns whether the images of the test hooks are
+// certified, as set in the check config or else when images-are-certified is
+// among the checks run.
+func certifyTestImages(opts *CheckOptions) bool {
+	if opts.ViperConfig.IsSet(CertifyTestImagesConfigString) {
+		return opts.ViperConfig.GetBool(CertifyTestImagesConfigString)
+	}
+	return opts.ProfileChecks == nil || slices.ContainsFunc(opts.ProfileChecks, func(check Check) bool {
+		return check.CheckID.Name == apiChecks.ImagesAreCertified
+	})
+}
+
+// isTestHook returns whether obj is a Pod or Job run by helm test.
+func isTestHook(obj renderedObject) bool {
+	if !isGroupKind(obj.Unstructured, podGroupKind) && !isGroupKind(obj.Unstructured, jobGroupKind) {
+		return false
+	}
+	for _, hook := range strings.Split(obj.GetAnnotations()[hookAnnotation], ",") {
+		if hook = strings.TrimSpace(hook); hook == "test" || hook == "test-success" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package checks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+
+	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+)
+
+// testHooksChart writes a chart with templates and returns its directory.
+func testHooksChart(t *testing.T, templates map[string]string) string {
+	files := map[string]string{"Chart.yaml": "apiVersion: v2\nname: demo\nversion: 0.1.0\n"}
+	for name, content := range templates {
+		files[filepath.Join("templates", name)] = content
+	}
+	return writeTestChart(t, "demo", files)
+}
+
+func TestContainsTest_V1_1(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		ok          bool
+		reason      string
+	}
+
+	const testJob = "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: demo-test\n  annotations:\n" +
+		"    helm.sh/hook: test\n    helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded\n" +
+		"spec:\n  template:\n    spec:\n      restartPolicy: Never\n      containers:\n        - name: test\n          image: registry.access.redhat.com/ubi9/ubi-minimal\n"
+	const configMap = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo-test-data\n"
+
+	testCases := []testCase{
+		{
+			description: "test pod without a delete policy",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			ok:          false,
+			reason: ChartTestHooksNotValid +
+				"\nPod \"test-release-chart-test-connection\" in chart/templates/tests/test-connection.yaml: no helm.sh/hook-delete-policy annotation",
+		},
+		{
+			description: "test job with a delete policy",
+			uri:         testHooksChart(t, map[string]string{"tests/test-job.yaml": testJob}),
+			ok:          true,
+			reason:      ChartTestHooksExist,
+		},
+		{
+			description: "test template without a test hook",
+			uri:         testHooksChart(t, map[string]string{"tests/test-job.yaml": testJob, "tests/data.yaml": configMap}),
+			ok:          true,
+			reason:      ChartTestHooksExist + "\n" + ChartTestTemplateNotHook + ": demo/templates/tests/data.yaml",
+		},
+		{
+			description: "no test hooks",
+			uri:         testHooksChart(t, map[string]string{"tests/data.yaml": configMap}),
+			ok:          false,
+			reason:      ChartTestHooksDoNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(CertifyTestImagesConfigString, false)
+			r, err := ContainsTest_V1_1(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
+func TestCertifyTestImages(t *testing.T) {
+	imagesAreCertified := Check{CheckID: CheckID{Name: apiChecks.ImagesAreCertified, Version: "v1.1"}}
+	helmLint := Check{CheckID: CheckID{Name: apiChecks.HelmLint, Version: "v1.0"}}
+
+	require.True(t, certifyTestImages(&CheckOptions{ViperConfig: viper.New()}))
+	require.True(t, certifyTestImages(&CheckOptions{ViperConfig: viper.New(), ProfileChecks: []Check{helmLint, imagesAreCertified}}))
+	require.False(t, certifyTestImages(&CheckOptions{ViperConfig: viper.New(), ProfileChecks: []Check{helmLint}}))
+
+	config := viper.New()
+	config.Set(CertifyTestImagesConfigString, false)
+	require.False(t, certifyTestImages(&CheckOptions{ViperConfig: config, ProfileChecks: []Check{imagesAreCertified}}))
+}
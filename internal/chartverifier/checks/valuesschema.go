@@ -0,0 +1,145 @@
+package checks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	commonutil "helm.sh/helm/v4/pkg/chart/common/util"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// supportedSchemaDrafts are the JSON schema drafts helm validates values
+// against.
+var supportedSchemaDrafts = []string{
+	"json-schema.org/draft-04/schema",
+	"json-schema.org/draft-06/schema",
+	"json-schema.org/draft-07/schema",
+	"json-schema.org/draft/2019-09/schema",
+	"json-schema.org/draft/2020-12/schema",
+}
+
+// ContainsValuesSchema_V1_1 checks that the chart contains a valid values
+// schema, that its default values and the ones of its CI values files,
+// overridden by the chart values of the verification, match the schema, and
+// warns about top level values the schema doesn't describe.
+//
+//nolint:stylecheck // Note(komish) separating numeric values is a valid use case for underscores.
+func ContainsValuesSchema_V1_1(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(c.Schema) == 0 {
+		return NewResult(false, ValuesSchemaFileDoesNotExist), nil
+	}
+
+	schema := map[string]interface{}{}
+	if err := json.Unmarshal(c.Schema, &schema); err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ValuesSchemaFileNotValid, err)), nil
+	}
+	if err := checkSchemaDraft(schema); err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ValuesSchemaFileNotValid, err)), nil
+	}
+
+	valuesSets, err := ciValuesSets(opts, c)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s: %v", ValuesSchemaNotEvaluated, err)), nil
+	}
+
+	r := NewResult(true, ValuesMatchSchema)
+	for _, set := range valuesSets {
+		file := set.file
+		if file == "" {
+			file = "values.yaml"
+		}
+		err := commonutil.ValidateAgainstSingleSchema(mergeValues(c.Values, set.values), c.Schema)
+		if err == nil {
+			continue
+		}
+		var validationErr commonutil.JSONSchemaValidationError
+		if !errors.As(err, &validationErr) {
+			return NewResult(false, fmt.Sprintf("%s: %v", ValuesSchemaFileNotValid, err)), nil
+		}
+		violations := strings.Split(strings.TrimSpace(validationErr.Error()), "\n")
+		sort.Strings(violations)
+		for _, violation := range violations {
+			addReason(&r, ValuesDoNotMatchSchema, fmt.Sprintf("%s: %s", file, strings.TrimPrefix(strings.TrimSpace(violation), "- ")))
+		}
+	}
+
+	if undescribed := undescribedValues(c, schema); len(undescribed) > 0 {
+		r.AddResult(true, fmt.Sprintf("%s: %s", ValuesNotDescribedBySchema, strings.Join(undescribed, ", ")))
+	}
+	return r, nil
+}
+
+// checkSchemaDraft returns an error when schema declares a JSON schema draft
+// helm doesn't support.
+func checkSchemaDraft(schema map[string]interface{}) error {
+	draft, ok := schema["$schema"]
+	if !ok {
+		return nil
+	}
+	draftURL, ok := draft.(string)
+	if !ok {
+		return fmt.Errorf("$schema must be a string, got %v", draft)
+	}
+	normalized := strings.TrimSuffix(draftURL, "#")
+	normalized = strings.TrimPrefix(strings.TrimPrefix(normalized, "http://"), "https://")
+	if !slices.Contains(supportedSchemaDrafts, normalized) {
+		return fmt.Errorf("unsupported JSON schema draft %q", draftURL)
+	}
+	return nil
+}
+
+// undescribedValues returns the top level keys of the default values of c
+// that are neither properties nor pattern properties of schema, nor values of
+// the dependencies of c. It returns nothing when schema describes no
+// property.
+func undescribedValues(c *chartv2.Chart, schema map[string]interface{}) []string {
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	if len(properties) == 0 && len(patternProperties) == 0 {
+		return nil
+	}
+
+	var patterns []*regexp.Regexp
+	for pattern := range patternProperties {
+		if re, err := regexp.Compile(pattern); err == nil {
+			patterns = append(patterns, re)
+		}
+	}
+	dependencies := dependencyValuesKeys(c)
+
+	var undescribed []string
+	for key := range c.Values {
+		if _, ok := properties[key]; ok || slices.Contains(dependencies, key) {
+			continue
+		}
+		if slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool { return re.MatchString(key) }) {
+			continue
+		}
+		undescribed = append(undescribed, key)
+	}
+	sort.Strings(undescribed)
+	return undescribed
+}
+
+// dependencyValuesKeys returns the top-level values keys that are not values
+// of c itself: global, and the names and aliases of its dependencies.
+func dependencyValuesKeys(c *chartv2.Chart) []string {
+	keys := []string{"global"}
+	for _, dependency := range c.Metadata.Dependencies {
+		keys = append(keys, dependency.Name)
+		if dependency.Alias != "" {
+			keys = append(keys, dependency.Alias)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,119 @@
+package checks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+const testValuesSchema = `{
+  "$schema": "https://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "replicaCount": {"type": "integer", "minimum": 1},
+    "image": {"type": "object", "properties": {"tag": {"type": "string"}}}
+  },
+  "patternProperties": {"^x-": {}}
+}`
+
+// valuesSchemaChart writes a chart with schema and values, and the CI values
+// files of ciValues, and returns its directory.
+func valuesSchemaChart(t *testing.T, schema, values string, ciValues map[string]string) string {
+	files := map[string]string{
+		"Chart.yaml":         "apiVersion: v2\nname: schema\nversion: 0.1.0\ndependencies:\n  - name: redis\n    version: 1.0.0\n    alias: cache\n",
+		"values.yaml":        values,
+		"values.schema.json": schema,
+	}
+	for name, content := range ciValues {
+		files[filepath.Join("ci", name)] = content
+	}
+	if schema == "" {
+		delete(files, "values.schema.json")
+	}
+	return writeTestChart(t, "schema", files)
+}
+
+func TestContainsValuesSchema_V1_1(t *testing.T) {
+	type testCase struct {
+		description string
+		schema      string
+		values      string
+		ciValues    map[string]string
+		chartValues map[string]interface{}
+		ok          bool
+		reason      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "values match the schema",
+			schema:      testValuesSchema,
+			values:      "replicaCount: 1\nimage:\n  tag: latest\nx-extra: true\ncache:\n  enabled: false\nglobal: {}\n",
+			ciValues:    map[string]string{"ha-values.yaml": "replicaCount: 3\n"},
+			ok:          true,
+			reason:      ValuesMatchSchema,
+		},
+		{
+			description: "no schema",
+			values:      "replicaCount: 1\n",
+			ok:          false,
+			reason:      ValuesSchemaFileDoesNotExist,
+		},
+		{
+			description: "schema is not valid JSON",
+			schema:      `{"type": "object",}`,
+			values:      "replicaCount: 1\n",
+			ok:          false,
+			reason:      ValuesSchemaFileNotValid + ": invalid character '}' looking for beginning of object key string",
+		},
+		{
+			description: "schema uses an unsupported draft",
+			schema:      `{"$schema": "https://json-schema.org/draft-03/schema#"}`,
+			values:      "replicaCount: 1\n",
+			ok:          false,
+			reason:      ValuesSchemaFileNotValid + `: unsupported JSON schema draft "https://json-schema.org/draft-03/schema#"`,
+		},
+		{
+			description: "schema doesn't compile",
+			schema:      `{"type": "object", "properties": {"replicaCount": {"type": "number", "minimum": "one"}}}`,
+			values:      "replicaCount: 1\n",
+			ok:          false,
+		},
+		{
+			description: "default, CI and chart values don't match the schema",
+			schema:      testValuesSchema,
+			values:      "replicaCount: 1\nimage:\n  tag: latest\n",
+			ciValues:    map[string]string{"ha-values.yaml": "replicaCount: 0\n"},
+			chartValues: map[string]interface{}{"image": map[string]interface{}{"tag": 1}},
+			ok:          false,
+			reason: ValuesDoNotMatchSchema +
+				"\nvalues.yaml: at '/image/tag': got number, want string" +
+				"\nci/ha-values.yaml: at '/image/tag': got number, want string" +
+				"\nci/ha-values.yaml: at '/replicaCount': minimum: got 0, want 1",
+		},
+		{
+			description: "values not described by the schema",
+			schema:      testValuesSchema,
+			values:      "replicaCount: 1\nservice: {}\ningress: {}\n",
+			ok:          true,
+			reason:      ValuesMatchSchema + "\n" + ValuesNotDescribedBySchema + ": ingress, service",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			uri := valuesSchemaChart(t, tc.schema, tc.values, tc.ciValues)
+			r, err := ContainsValuesSchema_V1_1(&CheckOptions{URI: uri, Values: tc.chartValues, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, r.Ok)
+			if tc.reason != "" {
+				require.Equal(t, tc.reason, r.Reason)
+			} else {
+				require.Contains(t, r.Reason, ValuesSchemaFileNotValid)
+			}
+		})
+	}
+}
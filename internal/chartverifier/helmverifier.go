@@ -29,6 +29,8 @@ import (
 type VerifierBuilder interface {
 	SetRegistry(registry checks.Registry) VerifierBuilder
 	SetValues(vals map[string]interface{}) VerifierBuilder
+	SetValuesFiles(files []string) VerifierBuilder
+	SetSetValues(sets []string) VerifierBuilder
 	SetChecks(checks FilteredRegistry) VerifierBuilder
 	SetConfig(config *viper.Viper) VerifierBuilder
 	GetConfig() *viper.Viper
@@ -37,6 +39,9 @@ type VerifierBuilder interface {
 	SetOpenShiftVersion(string) VerifierBuilder
 	SetWebCatalogOnly(bool) VerifierBuilder
 	SetSkipCleanup(bool) VerifierBuilder
+	SetServerSideApply(bool) VerifierBuilder
+	SetWaitStrategy(string) VerifierBuilder
+	SetEphemeralCluster(string) VerifierBuilder
 	SetTimeout(time.Duration) VerifierBuilder
 	SetPublicKeys([]string) VerifierBuilder
 	SetHelmInstallTimeout(time.Duration) VerifierBuilder
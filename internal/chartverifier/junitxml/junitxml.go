@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
@@ -19,6 +21,8 @@ type JUnitTestSuite struct {
 	Failures     int             `xml:"failures,attr"`
 	Skipped      int             `xml:"skipped,attr"`
 	Unknown      int             `xml:"unknown,attr"`
+	Time         string          `xml:"time,attr,omitempty"`
+	Timestamp    string          `xml:"timestamp,attr,omitempty"`
 	ReportDigest string          `xml:"reportDigest,attr"`
 	Name         string          `xml:"name,attr"`
 	Properties   []JUnitProperty `xml:"properties>property,omitempty"`
@@ -29,6 +33,7 @@ type JUnitTestCase struct {
 	XMLName     xml.Name          `xml:"testcase"`
 	Classname   string            `xml:"classname,attr"`
 	Name        string            `xml:"name,attr"`
+	Time        string            `xml:"time,attr,omitempty"`
 	SkipMessage *JUnitSkipMessage `xml:"skipped,omitempty"`
 	Failure     *JUnitMessage     `xml:"failure,omitempty"`
 	Warning     *JUnitMessage     `xml:"warning,omitempty"`
@@ -80,11 +85,16 @@ func Format(r report.Report) ([]byte, error) {
 		},
 		TestCases: []JUnitTestCase{},
 	}
+	if run := r.Metadata.ToolMetadata.Run; run != nil {
+		testsuite.Time = junitTime(run.Duration)
+		testsuite.Timestamp = run.StartTime
+	}
 
 	for _, tc := range checksByOutcome[report.PassOutcomeType] {
 		c := JUnitTestCase{
 			Classname: r.Metadata.ToolMetadata.ChartUri,
 			Name:      string(tc.Check),
+			Time:      junitTime(tc.Duration),
 			Failure:   nil,
 			Message:   tc.Reason,
 		}
@@ -95,6 +105,7 @@ func Format(r report.Report) ([]byte, error) {
 		c := JUnitTestCase{
 			Classname: r.Metadata.ToolMetadata.ChartUri,
 			Name:      string(tc.Check),
+			Time:      junitTime(tc.Duration),
 			Failure: &JUnitMessage{
 				Message:  "Failed",
 				Type:     string(tc.Type),
@@ -109,6 +120,7 @@ func Format(r report.Report) ([]byte, error) {
 		c := JUnitTestCase{
 			Classname: r.Metadata.ToolMetadata.ChartUri,
 			Name:      string(tc.Check),
+			Time:      junitTime(tc.Duration),
 			Failure: &JUnitMessage{
 				Message:  "Unknown",
 				Type:     string(tc.Type),
@@ -123,6 +135,7 @@ func Format(r report.Report) ([]byte, error) {
 		c := JUnitTestCase{
 			Classname: r.Metadata.ToolMetadata.ChartUri,
 			Name:      string(tc.Check),
+			Time:      junitTime(tc.Duration),
 			Failure:   nil,
 			Message:   tc.Reason,
 			SkipMessage: &JUnitSkipMessage{
@@ -132,6 +145,21 @@ func Format(r report.Report) ([]byte, error) {
 		testsuite.TestCases = append(testsuite.TestCases, c)
 	}
 
+	// Checks installing the chart get a test case per values file, in
+	// addition to the test case of the check itself.
+	for _, result := range results {
+		for _, vf := range result.ValuesFileResults {
+			testsuite.TestCases = append(testsuite.TestCases, valuesFileTestCase(r.Metadata.ToolMetadata.ChartUri, result, vf))
+			testsuite.Tests++
+			switch vf.Outcome {
+			case report.FailOutcomeType:
+				testsuite.Failures++
+			case report.SkippedOutcomeType:
+				testsuite.Skipped++
+			}
+		}
+	}
+
 	suites := JUnitTestSuites{
 		Suites: []JUnitTestSuite{testsuite},
 	}
@@ -148,3 +176,51 @@ func Format(r report.Report) ([]byte, error) {
 
 	return bytes, nil
 }
+
+// valuesFileTestCase builds the test case of a single values file of check,
+// listing the outcome and duration of each step.
+func valuesFileTestCase(classname string, check *report.CheckReport, vf report.ValuesFileResult) JUnitTestCase {
+	var steps, failures []string
+	var duration time.Duration
+	for _, step := range vf.Steps {
+		if stepDuration, err := time.ParseDuration(step.Duration); err == nil {
+			duration += stepDuration
+		}
+		line := fmt.Sprintf("%s: %s", step.Name, step.Outcome)
+		if step.Duration != "" {
+			line += fmt.Sprintf(" (%s)", step.Duration)
+		}
+		steps = append(steps, line)
+		if step.Outcome == report.FailOutcomeType {
+			failures = append(failures, fmt.Sprintf("%s: %s", step.Name, step.Message))
+		}
+	}
+
+	c := JUnitTestCase{
+		Classname: classname,
+		Name:      fmt.Sprintf("%s (%s)", check.Check, vf.ValuesFile),
+		Time:      fmt.Sprintf("%.3f", duration.Seconds()),
+		Message:   strings.Join(steps, "\n"),
+	}
+	switch vf.Outcome {
+	case report.FailOutcomeType:
+		c.Failure = &JUnitMessage{
+			Message:  "Failed",
+			Type:     string(check.Type),
+			Contents: strings.Join(failures, "\n"),
+		}
+	case report.SkippedOutcomeType:
+		c.SkipMessage = &JUnitSkipMessage{Message: c.Message}
+	}
+	return c
+}
+
+// junitTime returns duration, as recorded in the report, in seconds, or an
+// empty string if it is not set.
+func junitTime(duration string) string {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,90 @@
+package junitxml
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
+)
+
+func TestFormatValuesFileResults(t *testing.T) {
+	r := report.Report{
+		Results: []*report.CheckReport{
+			{
+				Check:   "v1.0/chart-testing",
+				Type:    "Mandatory",
+				Outcome: report.FailOutcomeType,
+				Reason:  "ci/b-values.yaml: chart test failure: timed out",
+				ValuesFileResults: []report.ValuesFileResult{
+					{
+						ValuesFile: "ci/a-values.yaml",
+						Outcome:    report.PassOutcomeType,
+						Steps: []report.StepResult{
+							{Name: "install", Outcome: report.PassOutcomeType, Duration: "2s"},
+							{Name: "test", Outcome: report.PassOutcomeType, Duration: "1s"},
+						},
+					},
+					{
+						ValuesFile: "ci/b-values.yaml",
+						Outcome:    report.FailOutcomeType,
+						Steps: []report.StepResult{
+							{Name: "install", Outcome: report.PassOutcomeType, Duration: "2s"},
+							{Name: "test", Outcome: report.FailOutcomeType, Duration: "5m0s", Message: "timed out"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out, err := Format(r)
+	require.NoError(t, err)
+
+	var suites JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(out, &suites))
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	require.Equal(t, 3, suite.Tests)
+	require.Equal(t, 2, suite.Failures)
+	require.Len(t, suite.TestCases, 3)
+
+	passed := suite.TestCases[1]
+	require.Equal(t, "v1.0/chart-testing (ci/a-values.yaml)", passed.Name)
+	require.Nil(t, passed.Failure)
+	require.Equal(t, "install: PASS (2s)\ntest: PASS (1s)", passed.Message)
+	require.Equal(t, "3.000", passed.Time)
+
+	failed := suite.TestCases[2]
+	require.Equal(t, "v1.0/chart-testing (ci/b-values.yaml)", failed.Name)
+	require.NotNil(t, failed.Failure)
+	require.Equal(t, "test: timed out", failed.Failure.Contents)
+}
+
+func TestFormatTime(t *testing.T) {
+	r := report.Report{
+		Metadata: report.ReportMetadata{
+			ToolMetadata: report.ToolMetadata{
+				Run: &report.RunMetadata{StartTime: "2026-10-19T10:00:00-04:00", Duration: "1m2.5s"},
+			},
+		},
+		Results: []*report.CheckReport{
+			{Check: "v1.0/helm-lint", Type: "Mandatory", Outcome: report.PassOutcomeType, Duration: "1.234s"},
+			{Check: "v1.0/has-readme", Type: "Mandatory", Outcome: report.FailOutcomeType},
+		},
+	}
+
+	out, err := Format(r)
+	require.NoError(t, err)
+
+	var suites JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(out, &suites))
+	suite := suites.Suites[0]
+	require.Equal(t, "62.500", suite.Time)
+	require.Equal(t, "2026-10-19T10:00:00-04:00", suite.Timestamp)
+	require.Equal(t, "1.234", suite.TestCases[0].Time)
+	require.Empty(t, suite.TestCases[1].Time)
+	require.NotContains(t, string(out), `time=""`)
+}
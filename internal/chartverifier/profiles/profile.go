@@ -59,6 +59,9 @@ type Profile struct {
 type Check struct {
 	Name string              `json:"name" yaml:"name"`
 	Type apiChecks.CheckType `json:"type" yaml:"type"`
+	// Config is the configuration of the check in the profile, overridden
+	// by the configuration set by the user.
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
 }
 
 type FilteredRegistry map[apiChecks.CheckName]checks.Check
@@ -88,6 +91,12 @@ func New(values map[string]interface{}) *Profile {
 		}
 	}
 
+	// Without a version, use the default version rather than the latest one,
+	// so that a new profile version is only used when asked for.
+	if len(profileVersion) == 0 {
+		profileVersion = DefaultProfileVersion
+	}
+
 	profileInUse = getDefaultProfile(fmt.Sprintf("profile %s not found", profileVendorType))
 
 	if vendorProfiles, ok := profileMap[profileVendorType]; ok {
@@ -145,6 +154,7 @@ func (profile *Profile) FilterChecks(registry checks.DefaultRegistry) FilteredRe
 		checkIndex := checks.CheckID{Name: apiChecks.CheckName(splitCheck[1]), Version: splitCheck[0]}
 		if newCheck, ok := registry[checkIndex]; ok {
 			newCheck.Type = check.Type
+			newCheck.Config = check.Config
 			filteredChecks[checkIndex.Name] = newCheck
 		}
 	}
@@ -23,6 +23,7 @@ const (
 	configVersion11     string     = "v1.1"
 	configVersion12     string     = "v1.2"
 	configVersion13     string     = "v1.3"
+	configVersion14     string     = "v1.4"
 	checkVersion10      string     = CheckVersion10
 	checkVersion11      string     = "v1.1"
 	NoVendorType        VendorType = ""
@@ -66,11 +67,14 @@ func TestGetProfiles(t *testing.T) {
 	getAndCheckProfile(t, RedhatVendorType, RedhatVendorType, NoVersion, configVersion13)
 	getAndCheckProfile(t, NoVendorType, PartnerVendorType, NoVersion, configVersion13)
 	getAndCheckProfile(t, PartnerVendorType, PartnerVendorType, configVersion13, configVersion13)
-	getAndCheckProfile(t, PartnerVendorType, PartnerVendorType, configVersion00, configVersion13)
+	getAndCheckProfile(t, PartnerVendorType, PartnerVendorType, configVersion14, configVersion14)
+	getAndCheckProfile(t, PartnerVendorType, PartnerVendorType, configVersion00, configVersion14)
 	getAndCheckProfile(t, RedhatVendorType, RedhatVendorType, configVersion13, configVersion13)
-	getAndCheckProfile(t, RedhatVendorType, RedhatVendorType, configVersion00, configVersion13)
-	getAndCheckProfile(t, CommunityVendorType, CommunityVendorType, configVersion00, configVersion13)
+	getAndCheckProfile(t, RedhatVendorType, RedhatVendorType, configVersion14, configVersion14)
+	getAndCheckProfile(t, RedhatVendorType, RedhatVendorType, configVersion00, configVersion14)
+	getAndCheckProfile(t, CommunityVendorType, CommunityVendorType, configVersion00, configVersion14)
 	getAndCheckProfile(t, CommunityVendorType, CommunityVendorType, configVersion13, configVersion13)
+	getAndCheckProfile(t, CommunityVendorType, CommunityVendorType, configVersion14, configVersion14)
 }
 
 func getAndCheckProfile(t *testing.T, configVendorType, expectVendorType VendorType, configVersion, expectVersion string) {
@@ -101,11 +105,11 @@ func TestProfileFilter(t *testing.T) {
 	defaultRegistry.Add(apiChecks.ContainsTest, checkVersion10, checks.ContainsTest)
 	defaultRegistry.Add(apiChecks.ContainsValues, checkVersion10, checks.ContainsValues)
 
-	defaultRegistry.Add(apiChecks.HasReadme, checkVersion11, checks.HasReadme)
+	defaultRegistry.Add(apiChecks.HasReadme, checkVersion11, checks.HasReadme_V1_1)
 	defaultRegistry.Add(apiChecks.IsHelmV3, checkVersion11, checks.IsHelmV3)
-	defaultRegistry.Add(apiChecks.ContainsTest, checkVersion11, checks.ContainsTest)
+	defaultRegistry.Add(apiChecks.ContainsTest, checkVersion11, checks.ContainsTest_V1_1)
 	defaultRegistry.Add(apiChecks.ContainsValues, checkVersion11, checks.ContainsValues)
-	defaultRegistry.Add(apiChecks.ContainsValuesSchema, checkVersion11, checks.ContainsValuesSchema)
+	defaultRegistry.Add(apiChecks.ContainsValuesSchema, checkVersion11, checks.ContainsValuesSchema_V1_1)
 	defaultRegistry.Add(apiChecks.HasKubeVersion, checkVersion11, checks.HasKubeVersion_V1_1)
 	defaultRegistry.Add(apiChecks.NotContainsCRDs, checkVersion11, checks.NotContainCRDs)
 	defaultRegistry.Add(apiChecks.HelmLint, checkVersion11, checks.HelmLint)
@@ -126,6 +130,7 @@ func TestProfileFilter(t *testing.T) {
 	expectedChecks[apiChecks.ImagesAreCertified] = checks.Check{CheckID: checks.CheckID{Name: apiChecks.ImagesAreCertified, Version: checkVersion11}, Type: apiChecks.MandatoryCheckType, Func: checks.ImagesAreCertified_V1_1}
 
 	config := make(map[string]interface{})
+	config[VersionConfigName] = configVersion13
 	t.Run("Checks filtered using profile subset", func(t *testing.T) {
 		filteredChecks := New(config).FilterChecks(defaultRegistry.AllChecks())
 		CompareCheckMaps(t, expectedChecks, filteredChecks)
@@ -153,6 +158,28 @@ func TestProfileFilter(t *testing.T) {
 	})
 }
 
+func TestProfileFilterVersion14(t *testing.T) {
+	registry := checks.NewRegistry()
+	registry.Add(apiChecks.HasReadme, checkVersion10, checks.HasReadme)
+	registry.Add(apiChecks.HasReadme, checkVersion11, checks.HasReadme_V1_1)
+	registry.Add(apiChecks.ContainsTest, checkVersion10, checks.ContainsTest)
+	registry.Add(apiChecks.ContainsTest, checkVersion11, checks.ContainsTest_V1_1)
+	registry.Add(apiChecks.ContainsValuesSchema, checkVersion10, checks.ContainsValuesSchema)
+	registry.Add(apiChecks.ContainsValuesSchema, checkVersion11, checks.ContainsValuesSchema_V1_1)
+
+	expectedChecks := FilteredRegistry{}
+	expectedChecks[apiChecks.HasReadme] = checks.Check{CheckID: checks.CheckID{Name: apiChecks.HasReadme, Version: checkVersion11}, Type: apiChecks.MandatoryCheckType, Func: checks.HasReadme_V1_1}
+	expectedChecks[apiChecks.ContainsTest] = checks.Check{CheckID: checks.CheckID{Name: apiChecks.ContainsTest, Version: checkVersion11}, Type: apiChecks.MandatoryCheckType, Func: checks.ContainsTest_V1_1}
+	expectedChecks[apiChecks.ContainsValuesSchema] = checks.Check{CheckID: checks.CheckID{Name: apiChecks.ContainsValuesSchema, Version: checkVersion11}, Type: apiChecks.MandatoryCheckType, Func: checks.ContainsValuesSchema_V1_1}
+
+	config := make(map[string]interface{})
+	config[VersionConfigName] = configVersion14
+	t.Run("Checks filtered using profile v1.4", func(t *testing.T) {
+		filteredChecks := New(config).FilterChecks(registry.AllChecks())
+		CompareCheckMaps(t, expectedChecks, filteredChecks)
+	})
+}
+
 func CompareCheckMaps(t *testing.T, expectedChecks, filteredChecks FilteredRegistry) {
 	assert.Equal(t, len(expectedChecks), len(filteredChecks), fmt.Sprintf("Expected map length : %d does not match returned mao length : %d", len(expectedChecks), len(filteredChecks)))
 	for k, v := range filteredChecks {
@@ -169,3 +196,31 @@ func CompareCheckMaps(t *testing.T, expectedChecks, filteredChecks FilteredRegis
 		}
 	}
 }
+
+func TestProfileCheckConfig(t *testing.T) {
+	profile, err := readProfile([]byte(`
+apiversion: v1
+kind: verifier-profile
+vendorType: partner
+version: v1.4
+checks:
+    - name: v1.0/required-annotations-present
+      type: Mandatory
+      config:
+        annotations:
+          - charts.openshift.io/name
+          - charts.openshift.io/provider
+    - name: v1.0/has-readme
+      type: Mandatory
+`))
+	assert.NoError(t, err)
+
+	registry := checks.NewRegistry()
+	registry.Add(apiChecks.RequiredAnnotationsPresent, checkVersion10, checks.RequiredAnnotationsPresent)
+	registry.Add(apiChecks.HasReadme, checkVersion10, checks.HasReadme)
+	filteredChecks := profile.FilterChecks(registry.AllChecks())
+
+	assert.Equal(t, map[string]interface{}{"annotations": []interface{}{"charts.openshift.io/name", "charts.openshift.io/provider"}},
+		filteredChecks[apiChecks.RequiredAnnotationsPresent].Config)
+	assert.Nil(t, filteredChecks[apiChecks.HasReadme].Config)
+}
@@ -42,15 +42,22 @@ type ReportBuilder interface {
 	SetToolVersion(name string) ReportBuilder
 	SetProfile(vendorType profiles.VendorType, version string) ReportBuilder
 	SetChartURI(name string) ReportBuilder
-	AddCheck(check checks.Check, result checks.Result) ReportBuilder
+	AddCheck(check checks.Check, result checks.Result, start, end time.Time) ReportBuilder
 	SetChart(chart *helmchart.Chart) ReportBuilder
 	SetTestedOpenShiftVersion(version string) ReportBuilder
 	SetSupportedOpenShiftVersions(versions string) ReportBuilder
 	SetWebCatalogOnly(webCatalogOnly bool) ReportBuilder
 	SetPublicKeyDigest(digest string) ReportBuilder
+	SetUpgradedFrom(chart, version string) ReportBuilder
+	SetApplyOptions(serverSideApply bool, waitStrategy string) ReportBuilder
+	SetTestEnvironment(environment apiReport.TestEnvironment) ReportBuilder
+	SetRunMetadata(run apiReport.RunMetadata) ReportBuilder
 	Build() (*apiReport.Report, error)
 }
 
+// timestampFormat is the format of the timestamps of the report.
+const timestampFormat = "2006-01-02T15:04:05.999999-07:00"
+
 type CheckResult struct {
 	checks.Result
 	Name string
@@ -112,9 +119,48 @@ func (r *reportBuilder) SetPublicKeyDigest(digest string) ReportBuilder {
 	return r
 }
 
-func (r *reportBuilder) AddCheck(check checks.Check, result checks.Result) ReportBuilder {
+func (r *reportBuilder) SetUpgradedFrom(chart, version string) ReportBuilder {
+	chartTesting := r.chartTestingMetadata()
+	chartTesting.UpgradedFromChart = chart
+	chartTesting.UpgradedFromVersion = version
+	return r
+}
+
+func (r *reportBuilder) SetApplyOptions(serverSideApply bool, waitStrategy string) ReportBuilder {
+	chartTesting := r.chartTestingMetadata()
+	chartTesting.ServerSideApply = serverSideApply
+	chartTesting.WaitStrategy = waitStrategy
+	return r
+}
+
+func (r *reportBuilder) SetTestEnvironment(environment apiReport.TestEnvironment) ReportBuilder {
+	r.chartTestingMetadata().Environment = &environment
+	return r
+}
+
+// chartTestingMetadata returns the chart-testing metadata of the report,
+// creating it if needed.
+func (r *reportBuilder) chartTestingMetadata() *apiReport.ChartTestingMetadata {
+	toolMetadata := &r.Report.GetAPIReport().Metadata.ToolMetadata
+	if toolMetadata.ChartTesting == nil {
+		toolMetadata.ChartTesting = &apiReport.ChartTestingMetadata{}
+	}
+	return toolMetadata.ChartTesting
+}
+
+func (r *reportBuilder) SetRunMetadata(run apiReport.RunMetadata) ReportBuilder {
+	r.Report.GetAPIReport().Metadata.ToolMetadata.Run = &run
+	return r
+}
+
+func (r *reportBuilder) AddCheck(check checks.Check, result checks.Result, start, end time.Time) ReportBuilder {
 	checkReport := r.Report.AddCheck(check)
 	checkReport.SetResult(result.Ok, result.Skipped, result.Reason)
+	checkReport.APICheckReport.ValuesFileResults = result.ValuesFileResults
+	checkReport.APICheckReport.Findings = result.Findings
+	checkReport.APICheckReport.StartTime = start.Format(timestampFormat)
+	checkReport.APICheckReport.EndTime = end.Format(timestampFormat)
+	checkReport.APICheckReport.Duration = end.Sub(start).Round(time.Millisecond).String()
 	utils.LogInfo(fmt.Sprintf("Check: %s:%s result : %t", check.CheckID.Name, check.CheckID.Version, result.Ok))
 	if !result.Ok {
 		utils.LogInfo(fmt.Sprintf("Check: %s:%s reason : %s", check.CheckID.Name, check.CheckID.Version, result.Reason))
@@ -130,7 +176,7 @@ func (r *reportBuilder) Build() (*apiReport.Report, error) {
 		case profiles.DigestAnnotation:
 			apiReport.Metadata.ToolMetadata.Digests.Chart = GenerateSha(r.Chart.Raw)
 		case profiles.LastCertifiedTimestampAnnotation:
-			apiReport.Metadata.ToolMetadata.LastCertifiedTimestamp = time.Now().Format("2006-01-02T15:04:05.999999-07:00")
+			apiReport.Metadata.ToolMetadata.LastCertifiedTimestamp = time.Now().Format(timestampFormat)
 		case profiles.OCPVersionAnnotation:
 			if len(r.OCPVersion) == 0 {
 				apiReport.Metadata.ToolMetadata.CertifiedOpenShiftVersions = "N/A"
@@ -158,6 +204,11 @@ func (r *reportBuilder) Build() (*apiReport.Report, error) {
 		r.SetChartURI(("N/A"))
 	}
 
+	toolMetadata := apiReport.Metadata.ToolMetadata
+	if toolMetadata.Run != nil && toolMetadata.ChartTesting != nil && toolMetadata.ChartTesting.Environment != nil {
+		toolMetadata.Run.KubernetesVersion = toolMetadata.ChartTesting.Environment.KubernetesVersion
+	}
+
 	r.Report.SetReportDigest()
 
 	return apiReport, nil
@@ -0,0 +1,102 @@
+package chartverifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	chartcommon "helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/cli"
+
+	"github.com/redhat-certification/chart-verifier/internal/tool"
+	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
+)
+
+const helmModulePath = "helm.sh/helm/v4"
+
+// runMetadata returns the metadata of a run of the checks from start to end,
+// with settings, the values files and the values set on the command line.
+func runMetadata(start, end time.Time, settings *cli.EnvSettings, valuesFiles, setValues []string) apiReport.RunMetadata {
+	run := apiReport.RunMetadata{
+		StartTime:   start.Format(timestampFormat),
+		EndTime:     end.Format(timestampFormat),
+		Duration:    end.Sub(start).Round(time.Millisecond).String(),
+		HelmVersion: helmVersion(),
+		KubeContext: kubeContext(settings),
+	}
+	for _, file := range valuesFiles {
+		run.ValuesFiles = append(run.ValuesFiles, redactValuesFile(file))
+	}
+	for _, set := range setValues {
+		run.SetValues = append(run.SetValues, redactSetValue(set))
+	}
+	sort.Strings(run.SetValues)
+	return run
+}
+
+// helmVersion returns the version of the Helm library the verifier is built
+// with.
+func helmVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != helmModulePath {
+				continue
+			}
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return chartcommon.DefaultCapabilities.HelmVersion.Version
+}
+
+// kubeContext returns the digest of the name of the kube context of
+// settings, or of the kubeconfig, if any.
+func kubeContext(settings *cli.EnvSettings) string {
+	if settings == nil {
+		return ""
+	}
+	name := settings.KubeContext
+	if name == "" {
+		if config, err := tool.GetClientConfig(settings).RawConfig(); err == nil {
+			name = config.CurrentContext
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	return sha256Digest(name)
+}
+
+// redactValuesFile returns file without the credentials, query and fragment
+// of its URL.
+func redactValuesFile(file string) string {
+	u, err := url.Parse(file)
+	if err != nil || u.Host == "" {
+		return file
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// redactSetValue returns set, key=value, as key=digest with the digest of
+// the value.
+func redactSetValue(set string) string {
+	key, value, found := strings.Cut(set, "=")
+	if !found {
+		return sha256Digest(set)
+	}
+	return key + "=" + sha256Digest(value)
+}
+
+func sha256Digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -64,6 +64,18 @@ func (holder *AnnotationHolder) SetSupportedOpenShiftVersions(versions string) {
 	holder.Holder.SetSupportedOpenShiftVersions(versions)
 }
 
+func (holder *AnnotationHolder) SetUpgradedFrom(chart, version string) {
+	holder.Holder.SetUpgradedFrom(chart, version)
+}
+
+func (holder *AnnotationHolder) SetApplyOptions(serverSideApply bool, waitStrategy string) {
+	holder.Holder.SetApplyOptions(serverSideApply, waitStrategy)
+}
+
+func (holder *AnnotationHolder) SetTestEnvironment(environment apiReport.TestEnvironment) {
+	holder.Holder.SetTestEnvironment(environment)
+}
+
 type verifier struct {
 	config             *viper.Viper
 	registry           checks.Registry
@@ -74,18 +86,70 @@ type verifier struct {
 	openshiftVersion   string
 	webCatalogOnly     bool
 	skipCleanup        bool
+	serverSideApply    bool
+	waitStrategy       string
+	ephemeralCluster   string
 	timeout            time.Duration
 	helmInstallTimeout time.Duration
 	publicKeys         []string
 	values             map[string]interface{}
+	valuesFiles        []string
+	setValues          []string
+}
+
+// profileOnlyConfig lists the configuration of checks which only the profile
+// can set, since the user could otherwise relax a mandatory check with it.
+var profileOnlyConfig = map[apiChecks.CheckName][]string{
+	apiChecks.RequiredAnnotationsPresent: {checks.RequiredAnnotationsConfigString},
+	apiChecks.HasReadme: {
+		checks.ReadmeSectionsConfigString,
+		checks.ReadmeValuesTableConfigString,
+		checks.ReadmePlaceholdersConfigString,
+	},
+	apiChecks.ContainsTest: {checks.CertifyTestImagesConfigString},
 }
 
-func (c *verifier) subConfig(name string) *viper.Viper {
-	if sub := c.config.Sub(name); sub == nil {
-		return viper.New()
-	} else {
-		return sub
+// isProfileOnlyConfig returns whether key of the configuration of check can
+// only be set by the profile.
+func isProfileOnlyConfig(check apiChecks.CheckName, key string) bool {
+	for _, profileKey := range profileOnlyConfig[check] {
+		profileKey = strings.ToLower(profileKey)
+		if key == profileKey || strings.HasPrefix(key, profileKey+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// subConfig returns the configuration of check, the configuration set by the
+// user over the one set by the profile, except for the keys only the profile
+// can set.
+func (c *verifier) subConfig(check checks.Check) *viper.Viper {
+	config := viper.New()
+	for key, value := range check.Config {
+		config.SetDefault(key, value)
+	}
+	if sub := c.config.Sub(string(check.CheckID.Name)); sub != nil {
+		for _, key := range sub.AllKeys() {
+			if isProfileOnlyConfig(check.CheckID.Name, key) {
+				continue
+			}
+			config.Set(key, sub.Get(key))
+		}
+	}
+	return config
+}
+
+// chartTestingUpgrade returns whether chart-testing upgrades the release,
+// which the checks evaluating the install take into account.
+func (c *verifier) chartTestingUpgrade() bool {
+	check := checks.Check{CheckID: checks.CheckID{Name: apiChecks.ChartTesting}}
+	for _, required := range c.requiredChecks {
+		if required.CheckID.Name == apiChecks.ChartTesting {
+			check = required
+		}
 	}
+	return c.subConfig(check).GetBool("upgrade")
 }
 
 func (c *verifier) Verify(uri string) (*apiReport.Report, error) {
@@ -95,11 +159,13 @@ func (c *verifier) Verify(uri string) (*apiReport.Report, error) {
 		}
 	}
 
+	checks.RevalidateCachedCharts()
 	chrt, _, err := checks.LoadChartFromURI(&checks.CheckOptions{HelmEnvSettings: c.settings, URI: uri})
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
 	result := NewReportBuilder().
 		SetToolVersion(c.toolVersion).
 		SetChartURI(uri).
@@ -116,22 +182,28 @@ func (c *verifier) Verify(uri string) (*apiReport.Report, error) {
 			CertifiedOpenShiftVersionFlag: c.openshiftVersion,
 		}
 
+		checkStart := time.Now()
 		r, checkErr := check.Func(&checks.CheckOptions{
 			HelmEnvSettings:    c.settings,
 			URI:                uri,
 			Values:             c.values,
-			ViperConfig:        c.subConfig(string(check.CheckID.Name)),
+			ViperConfig:        c.subConfig(check),
 			AnnotationHolder:   &holder,
 			Timeout:            c.timeout,
 			HelmInstallTimeout: c.helmInstallTimeout,
 			SkipCleanup:        c.skipCleanup,
+			ServerSideApply:    c.serverSideApply,
+			WaitStrategy:       c.waitStrategy,
+			Upgrade:            c.chartTestingUpgrade(),
+			EphemeralCluster:   c.ephemeralCluster,
 			PublicKeys:         c.publicKeys,
+			ProfileChecks:      c.requiredChecks,
 		})
 
 		if checkErr != nil {
 			return nil, NewCheckErr(checkErr)
 		}
-		_ = result.AddCheck(check, r)
+		_ = result.AddCheck(check, r, checkStart, time.Now())
 
 		if check.CheckID.Name == apiChecks.SignatureIsValid {
 			if len(c.publicKeys) == 1 && strings.Contains(r.Reason, checks.ChartSigned) {
@@ -144,5 +216,6 @@ func (c *verifier) Verify(uri string) (*apiReport.Report, error) {
 		}
 	}
 
+	result.SetRunMetadata(runMetadata(start, time.Now(), c.settings, c.valuesFiles, c.setValues))
 	return result.Build()
 }
@@ -29,6 +29,7 @@ import (
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/internal/testutil"
+	apiChecks "github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	apiReport "github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 )
 
@@ -120,12 +121,23 @@ func TestVerifier_Verify(t *testing.T) {
 			registry:       checks.NewRegistry().Add(dummyCheck.CheckID.Name, "v1.0", positiveCheck),
 			requiredChecks: []checks.Check{dummyCheck},
 			webCatalogOnly: true,
+			valuesFiles:    []string{"values.yaml"},
+			setValues:      []string{"password=secret"},
 		}
 
 		r, err := c.Verify(validChartURI)
 		require.NoError(t, err)
 		require.NotNil(t, r)
 		require.True(t, isOk(r))
+		require.NotEmpty(t, r.Results[0].StartTime)
+		require.NotEmpty(t, r.Results[0].Duration)
+
+		run := r.Metadata.ToolMetadata.Run
+		require.NotNil(t, run)
+		require.NotEmpty(t, run.Duration)
+		require.NotEmpty(t, run.HelmVersion)
+		require.Equal(t, []string{"values.yaml"}, run.ValuesFiles)
+		require.Equal(t, []string{"password=" + sha256Digest("secret")}, run.SetValues)
 	})
 
 	t.Run("Result should be negative is provider deliver is set and uri is not a tarball", func(t *testing.T) {
@@ -145,3 +157,49 @@ func TestVerifier_Verify(t *testing.T) {
 	})
 	cancel()
 }
+
+func TestVerifier_SubConfig(t *testing.T) {
+	config := viper.New()
+	config.Set("dummy-check.annotations", []string{"example.com/team"})
+	c := &verifier{config: config}
+
+	check := checks.Check{
+		CheckID: checks.CheckID{Name: "dummy-check"},
+		Config:  map[string]interface{}{"annotations": []string{"charts.openshift.io/name"}, "requireIcon": true},
+	}
+	sub := c.subConfig(check)
+	require.Equal(t, []string{"example.com/team"}, sub.GetStringSlice("annotations"))
+	require.True(t, sub.GetBool("requireIcon"))
+
+	check.CheckID.Name = "other-check"
+	sub = c.subConfig(check)
+	require.Equal(t, []string{"charts.openshift.io/name"}, sub.GetStringSlice("annotations"))
+}
+
+func TestVerifier_SubConfigProfileOnly(t *testing.T) {
+	config := viper.New()
+	config.Set("required-annotations-present.annotations", "")
+	config.Set("has-readme.sections", []string{})
+	config.Set("has-readme.valuesTable", false)
+	config.Set("has-readme.placeholders", []string{})
+	config.Set("contains-test.certifyImages", false)
+	c := &verifier{config: config}
+
+	check := checks.Check{
+		CheckID: checks.CheckID{Name: apiChecks.RequiredAnnotationsPresent},
+		Config:  map[string]interface{}{"annotations": []string{"charts.openshift.io/name"}},
+	}
+	require.Equal(t, []string{"charts.openshift.io/name"}, c.subConfig(check).GetStringSlice("annotations"))
+
+	check = checks.Check{
+		CheckID: checks.CheckID{Name: apiChecks.HasReadme},
+		Config:  map[string]interface{}{"valuesTable": true},
+	}
+	sub := c.subConfig(check)
+	require.True(t, sub.GetBool(checks.ReadmeValuesTableConfigString))
+	require.False(t, sub.IsSet(checks.ReadmeSectionsConfigString))
+	require.False(t, sub.IsSet(checks.ReadmePlaceholdersConfigString))
+
+	check = checks.Check{CheckID: checks.CheckID{Name: apiChecks.ContainsTest}}
+	require.False(t, c.subConfig(check).IsSet(checks.CertifyTestImagesConfigString))
+}
@@ -36,10 +36,13 @@ func init() {
 	defaultRegistry = checks.NewRegistry()
 
 	defaultRegistry.Add(apiChecks.HasReadme, "v1.0", checks.HasReadme)
+	defaultRegistry.Add(apiChecks.HasReadme, "v1.1", checks.HasReadme_V1_1)
 	defaultRegistry.Add(apiChecks.IsHelmV3, "v1.0", checks.IsHelmV3)
 	defaultRegistry.Add(apiChecks.ContainsTest, "v1.0", checks.ContainsTest)
+	defaultRegistry.Add(apiChecks.ContainsTest, "v1.1", checks.ContainsTest_V1_1)
 	defaultRegistry.Add(apiChecks.ContainsValues, "v1.0", checks.ContainsValues)
 	defaultRegistry.Add(apiChecks.ContainsValuesSchema, "v1.0", checks.ContainsValuesSchema)
+	defaultRegistry.Add(apiChecks.ContainsValuesSchema, "v1.1", checks.ContainsValuesSchema_V1_1)
 	defaultRegistry.Add(apiChecks.HasKubeVersion, "v1.0", checks.HasKubeVersion)
 	defaultRegistry.Add(apiChecks.HasKubeVersion, "v1.1", checks.HasKubeVersion_V1_1)
 	defaultRegistry.Add(apiChecks.NotContainsCRDs, "v1.0", checks.NotContainCRDs)
@@ -51,6 +54,15 @@ func init() {
 	defaultRegistry.Add(apiChecks.RequiredAnnotationsPresent, "v1.0", checks.RequiredAnnotationsPresent)
 	defaultRegistry.Add(apiChecks.SignatureIsValid, "v1.0", checks.SignatureIsValid)
 	defaultRegistry.Add(apiChecks.HasNotes, "v1.0", checks.HasNotes)
+	defaultRegistry.Add(apiChecks.CanBeInstalledWithoutClusterAdminPrivileges, "v1.0", checks.CanBeInstalledWithoutClusterAdminPrivileges)
+	defaultRegistry.Add(apiChecks.KeywordsAreOpenshiftCategories, "v1.0", checks.KeywordsAreOpenshiftCategories)
+	defaultRegistry.Add(apiChecks.CanBeInstalledWithoutManualPreRequisites, "v1.0", checks.CanBeInstalledWithoutManualPreRequisites)
+	defaultRegistry.Add(apiChecks.NotContainsInfraPluginsAndDrivers, "v1.0", checks.NotContainsInfraPluginsAndDrivers)
+	defaultRegistry.Add(apiChecks.APIVersionsSupported, "v1.0", checks.APIVersionsSupported)
+	defaultRegistry.Add(apiChecks.PodSecurityCompatible, "v1.0", checks.PodSecurityCompatible)
+	defaultRegistry.Add(apiChecks.ContainersFollowBestPractices, "v1.0", checks.ContainersFollowBestPractices)
+	defaultRegistry.Add(apiChecks.DependenciesAreAudited, "v1.0", checks.DependenciesAreAudited)
+	defaultRegistry.Add(apiChecks.MetadataIsValid, "v1.0", checks.MetadataIsValid)
 }
 
 func DefaultRegistry() checks.Registry {
@@ -73,10 +85,15 @@ type verifierBuilder struct {
 	supportedOpenshiftVersions string
 	webCatalogOnly             bool
 	skipCleanup                bool
+	serverSideApply            bool
+	waitStrategy               string
+	ephemeralCluster           string
 	timeout                    time.Duration
 	publicKeys                 []string
 	helmInstallTimeout         time.Duration
 	values                     map[string]interface{}
+	valuesFiles                []string
+	setValues                  []string
 	settings                   *cli.EnvSettings
 }
 
@@ -90,6 +107,16 @@ func (b *verifierBuilder) SetValues(vals map[string]interface{}) VerifierBuilder
 	return b
 }
 
+func (b *verifierBuilder) SetValuesFiles(files []string) VerifierBuilder {
+	b.valuesFiles = files
+	return b
+}
+
+func (b *verifierBuilder) SetSetValues(sets []string) VerifierBuilder {
+	b.setValues = sets
+	return b
+}
+
 func (b *verifierBuilder) SetRegistry(registry checks.Registry) VerifierBuilder {
 	b.registry = registry
 	return b
@@ -133,6 +160,21 @@ func (b *verifierBuilder) SetSkipCleanup(skipCleanup bool) VerifierBuilder {
 	return b
 }
 
+func (b *verifierBuilder) SetServerSideApply(serverSideApply bool) VerifierBuilder {
+	b.serverSideApply = serverSideApply
+	return b
+}
+
+func (b *verifierBuilder) SetWaitStrategy(waitStrategy string) VerifierBuilder {
+	b.waitStrategy = waitStrategy
+	return b
+}
+
+func (b *verifierBuilder) SetEphemeralCluster(provider string) VerifierBuilder {
+	b.ephemeralCluster = provider
+	return b
+}
+
 func (b *verifierBuilder) SetTimeout(timeout time.Duration) VerifierBuilder {
 	b.timeout = timeout
 	return b
@@ -187,10 +229,15 @@ func (b *verifierBuilder) Build() (Verifier, error) {
 		openshiftVersion:   b.openshiftVersion,
 		webCatalogOnly:     b.webCatalogOnly,
 		skipCleanup:        b.skipCleanup,
+		serverSideApply:    b.serverSideApply,
+		waitStrategy:       b.waitStrategy,
+		ephemeralCluster:   b.ephemeralCluster,
 		timeout:            b.timeout,
 		helmInstallTimeout: b.helmInstallTimeout,
 		publicKeys:         b.publicKeys,
 		values:             b.values,
+		valuesFiles:        b.valuesFiles,
+		setValues:          b.setValues,
 	}, nil
 }
 
@@ -25,9 +25,6 @@ func RenderManifests(releaseName string, chartURL string, vals map[string]any, c
 	installer := action.NewInstall(conf)
 	// Prepare client for rendering only, no installation
 	installer.DryRunStrategy = "client"
-	// Skip the releaseName check by enabling Replace mode.
-	installer.Replace = true
-	emptyResponse := ""
 
 	// Must set the capabilities on *action.Install{}.KubeVersion directly
 	// because Helm will replace our capabilities with the defaults they
@@ -36,6 +33,29 @@ func RenderManifests(releaseName string, chartURL string, vals map[string]any, c
 		installer.KubeVersion = &conf.Capabilities.KubeVersion
 	}
 
+	return renderManifests(installer, releaseName, chartURL, vals)
+}
+
+// RenderManifestsWithCapabilities renders chartURL like RenderManifests, but
+// with the API versions of conf.Capabilities as well as its KubeVersion,
+// which a client-side dry run replaces with the default API versions of
+// Helm. The installer is configured for a server-side dry run, which uses
+// the capabilities of conf as is, so conf must hold a fake KubeClient and no
+// RESTClientGetter for no cluster to be reached.
+func RenderManifestsWithCapabilities(releaseName string, chartURL string, vals map[string]any, conf *action.Configuration) (string, error) {
+	if conf.Capabilities == nil || conf.RESTClientGetter != nil {
+		return "", errors.New("rendering with capabilities requires capabilities and no cluster")
+	}
+	installer := action.NewInstall(conf)
+	installer.DryRunStrategy = action.DryRunServer
+	return renderManifests(installer, releaseName, chartURL, vals)
+}
+
+func renderManifests(installer *action.Install, releaseName string, chartURL string, vals map[string]any) (string, error) {
+	// Skip the releaseName check by enabling Replace mode.
+	installer.Replace = true
+	emptyResponse := ""
+
 	// Roundtrip through the installer name validation to make sure
 	// installer flags don't conflict with a passed in releaseName and chartURL.
 	releaseName, chartURL, err := installer.NameAndChart([]string{releaseName, chartURL})
@@ -0,0 +1,118 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/restmapper"
+)
+
+// ResourceAccess is a permission, a verb on a resource, needed to install a
+// chart. Namespace is empty for cluster scoped resources.
+type ResourceAccess struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+func (a ResourceAccess) String() string {
+	resource := a.Resource
+	if a.Group != "" {
+		resource = fmt.Sprintf("%s.%s", a.Resource, a.Group)
+	}
+	if a.Namespace == "" {
+		return fmt.Sprintf("%s %s (cluster scope)", a.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", a.Verb, resource, a.Namespace)
+}
+
+// RESTMapper returns a mapper of the kinds served by the cluster to their
+// resources.
+func (k Kubectl) RESTMapper() (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(k.clientset.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// MissingAccess returns the accesses that are denied to the current user, as
+// reported by SelfSubjectAccessReviews.
+func (k Kubectl) MissingAccess(context context.Context, accesses []ResourceAccess) ([]ResourceAccess, error) {
+	var missing []ResourceAccess
+	for _, access := range accesses {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      access.Verb,
+					Group:     access.Group,
+					Resource:  access.Resource,
+					Namespace: access.Namespace,
+				},
+			},
+		}
+		response, err := k.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing access to %s: %w", access, err)
+		}
+		if !response.Status.Allowed {
+			missing = append(missing, access)
+		}
+	}
+	return missing, nil
+}
+
+// MissingAccessForRoles returns the accesses that are not granted by the
+// rules of roles, which apply to their namespace, or to any namespace when
+// theirs is not set, or of clusterRoles, which apply to every namespace and
+// to cluster scoped resources.
+func MissingAccessForRoles(accesses []ResourceAccess, roles []rbacv1.Role, clusterRoles []rbacv1.ClusterRole) []ResourceAccess {
+	var missing []ResourceAccess
+	for _, access := range accesses {
+		if !isAccessGranted(access, roles, clusterRoles) {
+			missing = append(missing, access)
+		}
+	}
+	return missing
+}
+
+func isAccessGranted(access ResourceAccess, roles []rbacv1.Role, clusterRoles []rbacv1.ClusterRole) bool {
+	for _, clusterRole := range clusterRoles {
+		if rulesAllow(clusterRole.Rules, access) {
+			return true
+		}
+	}
+	if access.Namespace == "" {
+		return false
+	}
+	for _, role := range roles {
+		if (role.Namespace == "" || role.Namespace == access.Namespace) && rulesAllow(role.Rules, access) {
+			return true
+		}
+	}
+	return false
+}
+
+func rulesAllow(rules []rbacv1.PolicyRule, access ResourceAccess) bool {
+	for _, rule := range rules {
+		// Rules restricted to resource names can't allow creating resources,
+		// whose names are unknown at authorization time.
+		if len(rule.ResourceNames) > 0 {
+			continue
+		}
+		if matches(rule.Verbs, access.Verb) && matches(rule.APIGroups, access.Group) && matches(rule.Resources, access.Resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(values []string, value string) bool {
+	return slices.Contains(values, rbacv1.ResourceAll) || slices.Contains(values, value)
+}
@@ -0,0 +1,95 @@
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	createDeployment         = ResourceAccess{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "ns"}
+	createService            = ResourceAccess{Verb: "create", Resource: "services", Namespace: "ns"}
+	createClusterRoleBinding = ResourceAccess{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
+)
+
+func TestResourceAccessString(t *testing.T) {
+	require.Equal(t, "create deployments.apps in namespace ns", createDeployment.String())
+	require.Equal(t, "create services in namespace ns", createService.String())
+	require.Equal(t, "create clusterrolebindings.rbac.authorization.k8s.io (cluster scope)", createClusterRoleBinding.String())
+}
+
+func TestMissingAccess(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace != ""
+		return true, review, nil
+	})
+	k := Kubectl{clientset: clientset}
+
+	missing, err := k.MissingAccess(context.Background(), []ResourceAccess{createDeployment, createClusterRoleBinding, createService})
+	require.NoError(t, err)
+	require.Equal(t, []ResourceAccess{createClusterRoleBinding}, missing)
+}
+
+func TestMissingAccessForRoles(t *testing.T) {
+	accesses := []ResourceAccess{createDeployment, createService, createClusterRoleBinding}
+
+	type testCase struct {
+		description  string
+		roles        []rbacv1.Role
+		clusterRoles []rbacv1.ClusterRole
+		missing      []ResourceAccess
+	}
+
+	testCases := []testCase{
+		{
+			description: "no roles",
+			missing:     accesses,
+		},
+		{
+			description: "role grants namespaced resources only",
+			roles: []rbacv1.Role{{
+				Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+			}},
+			missing: []ResourceAccess{createClusterRoleBinding},
+		},
+		{
+			description: "role of another namespace",
+			roles: []rbacv1.Role{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other"},
+				Rules:      []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+			}},
+			missing: accesses,
+		},
+		{
+			description: "rules restricted to resource names",
+			clusterRoles: []rbacv1.ClusterRole{{
+				Rules: []rbacv1.PolicyRule{
+					{Verbs: []string{"create"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+					{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"services"}, ResourceNames: []string{"svc"}},
+				},
+			}},
+			missing: []ResourceAccess{createService, createClusterRoleBinding},
+		},
+		{
+			description: "cluster role grants everything",
+			clusterRoles: []rbacv1.ClusterRole{{
+				Rules: []rbacv1.PolicyRule{{Verbs: []string{"create", "get"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.missing, MissingAccessForRoles(accesses, tc.roles, tc.clusterRoles))
+		})
+	}
+}
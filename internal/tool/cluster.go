@@ -0,0 +1,101 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/helm/chart-testing/v3/pkg/util"
+
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
+)
+
+// EphemeralCluster is a throwaway local cluster, started for a verifier run
+// when no cluster is available and deleted afterwards.
+type EphemeralCluster interface {
+	// Provider is the name of the provider running the cluster, e.g. kind.
+	Provider() string
+	// Start creates the cluster and writes its kubeconfig.
+	Start() error
+	// Kubeconfig is the path of the kubeconfig of the started cluster.
+	Kubeconfig() string
+	// Stop deletes the cluster and its kubeconfig.
+	Stop() error
+}
+
+// ephemeralClusterProviders maps the provider names to the constructors of
+// their clusters, which are named name and keep their files in dir.
+var ephemeralClusterProviders = map[string]func(executor ProcessExecutorer, name, dir string) EphemeralCluster{
+	"kind": newKindCluster,
+}
+
+// EphemeralClusterProviders returns the names of the supported providers.
+func EphemeralClusterProviders() []string {
+	var providers []string
+	for provider := range ephemeralClusterProviders {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// NewEphemeralCluster returns a cluster run by provider, which is not started
+// yet.
+func NewEphemeralCluster(provider string) (EphemeralCluster, error) {
+	newCluster, ok := ephemeralClusterProviders[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ephemeral cluster provider %q, expected one of %v", provider, EphemeralClusterProviders())
+	}
+	dir, err := os.MkdirTemp("", "chart-verifier-cluster-")
+	if err != nil {
+		return nil, err
+	}
+	name := "chart-verifier-" + strings.ToLower(util.RandomString(6))
+	return newCluster(NewProcessExecutor(false), name, dir), nil
+}
+
+// kindCluster runs the cluster in containers using the kind CLI, which must
+// be in the PATH.
+type kindCluster struct {
+	executor ProcessExecutorer
+	name     string
+	dir      string
+}
+
+// kindWaitTimeout is how long kind waits for the control plane to be ready.
+var kindWaitTimeout = "5m"
+
+func newKindCluster(executor ProcessExecutorer, name, dir string) EphemeralCluster {
+	return &kindCluster{executor: executor, name: name, dir: dir}
+}
+
+func (c *kindCluster) Provider() string { return "kind" }
+
+func (c *kindCluster) Kubeconfig() string {
+	return filepath.Join(c.dir, "kubeconfig")
+}
+
+func (c *kindCluster) Start() error {
+	utils.LogInfo(fmt.Sprintf("Create kind cluster %s", c.name))
+	if _, err := c.executor.RunProcessAndCaptureOutput("kind", "create", "cluster",
+		"--name", c.name, "--kubeconfig", c.Kubeconfig(), "--wait", kindWaitTimeout); err != nil {
+		utils.LogError(fmt.Sprintf("Error creating kind cluster: %v", err))
+		return err
+	}
+	return nil
+}
+
+func (c *kindCluster) Stop() error {
+	utils.LogInfo(fmt.Sprintf("Delete kind cluster %s", c.name))
+	_, err := c.executor.RunProcessAndCaptureOutput("kind", "delete", "cluster",
+		"--name", c.name, "--kubeconfig", c.Kubeconfig())
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error deleting kind cluster: %v", err))
+	}
+	if rmErr := os.RemoveAll(c.dir); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
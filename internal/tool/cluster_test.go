@@ -0,0 +1,58 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProcessExecutor struct {
+	commands []string
+	err      error
+}
+
+func (f *fakeProcessExecutor) RunProcessAndCaptureOutput(executable string, execArgs ...interface{}) (string, error) {
+	f.commands = append(f.commands, strings.TrimSpace(executable+" "+strings.Join(toStringArray(execArgs), " ")))
+	return "", f.err
+}
+
+func TestKindCluster(t *testing.T) {
+	dir := t.TempDir()
+	executor := &fakeProcessExecutor{}
+	cluster := newKindCluster(executor, "chart-verifier-test", dir)
+
+	require.Equal(t, "kind", cluster.Provider())
+	require.NoError(t, cluster.Start())
+	require.NoError(t, cluster.Stop())
+
+	kubeconfig := cluster.Kubeconfig()
+	require.Equal(t, []string{
+		fmt.Sprintf("kind create cluster --name chart-verifier-test --kubeconfig %s --wait 5m", kubeconfig),
+		fmt.Sprintf("kind delete cluster --name chart-verifier-test --kubeconfig %s", kubeconfig),
+	}, executor.commands)
+
+	_, err := os.Stat(dir)
+	require.True(t, os.IsNotExist(err), "cluster directory should be removed")
+}
+
+func TestKindClusterErrors(t *testing.T) {
+	executor := &fakeProcessExecutor{err: errors.New("kind: command not found")}
+	cluster := newKindCluster(executor, "chart-verifier-test", t.TempDir())
+
+	require.ErrorContains(t, cluster.Start(), "kind: command not found")
+	require.ErrorContains(t, cluster.Stop(), "kind: command not found")
+}
+
+func TestNewEphemeralCluster(t *testing.T) {
+	_, err := NewEphemeralCluster("minikube")
+	require.ErrorContains(t, err, `unsupported ephemeral cluster provider "minikube", expected one of [kind]`)
+
+	cluster, err := NewEphemeralCluster("kind")
+	require.NoError(t, err)
+	require.Equal(t, "kind", cluster.Provider())
+	require.NoError(t, os.RemoveAll(strings.TrimSuffix(cluster.Kubeconfig(), "kubeconfig")))
+}
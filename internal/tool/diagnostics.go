@@ -0,0 +1,294 @@
+package tool
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
+)
+
+// helmHookAnnotation is set on resources created by helm hooks, which
+// includes helm test pods.
+const helmHookAnnotation = "helm.sh/hook"
+
+// diagnosticsLogTailLines bounds the number of log lines captured for each
+// container.
+var diagnosticsLogTailLines int64 = 500
+
+// Diagnostics holds the files describing the state of a release at the time
+// a chart-testing step failed, keyed by their path in the archive.
+type Diagnostics map[string][]byte
+
+// CollectDiagnostics captures the namespace events, the status and logs of
+// the release pods, including helm test hook pods, and a summary of the
+// release workload resources that are not ready. Failures to retrieve an
+// item are recorded in errors.txt rather than aborting the collection.
+func (k Kubectl) CollectDiagnostics(context context.Context, namespace, selector, release string) Diagnostics {
+	diagnostics := Diagnostics{}
+	var collectErrors []string
+	addError := func(what string, err error) {
+		collectErrors = append(collectErrors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if events, err := k.clientset.CoreV1().Events(namespace).List(context, metav1.ListOptions{}); err != nil {
+		addError("listing events", err)
+	} else {
+		diagnostics["events.txt"] = formatEvents(events.Items)
+	}
+
+	if pods, err := k.listReleasePods(context, namespace, selector, release); err != nil {
+		addError("listing pods", err)
+	} else {
+		diagnostics["pods.txt"] = formatPods(pods)
+		for _, pod := range pods {
+			containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+			for _, container := range containers {
+				logs, err := k.clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+					Container: container.Name,
+					TailLines: &diagnosticsLogTailLines,
+				}).DoRaw(context)
+				if err != nil {
+					addError(fmt.Sprintf("getting logs of %s/%s", pod.Name, container.Name), err)
+					continue
+				}
+				diagnostics[fmt.Sprintf("logs/%s/%s.log", pod.Name, container.Name)] = logs
+			}
+		}
+	}
+
+	var summaries []string
+	if deployments, err := listDeployments(k, context, namespace, selector); err != nil {
+		addError("listing deployments", err)
+	} else {
+		for _, d := range deployments {
+			if d.Status.UnavailableReplicas > 0 {
+				summaries = append(summaries, describeDeployment(d))
+			}
+		}
+	}
+	if daemonSets, err := listDaemonSets(k, context, namespace, selector); err != nil {
+		addError("listing daemonsets", err)
+	} else {
+		for _, ds := range daemonSets {
+			if ds.Status.NumberUnavailable > 0 {
+				summaries = append(summaries, describeDaemonSet(ds))
+			}
+		}
+	}
+	if statefulSets, err := listStatefulSets(k, context, namespace, selector); err != nil {
+		addError("listing statefulsets", err)
+	} else {
+		for _, sts := range statefulSets {
+			if sts.Status.Replicas-sts.Status.AvailableReplicas > 0 {
+				summaries = append(summaries, describeStatefulSet(sts))
+			}
+		}
+	}
+	if len(summaries) > 0 {
+		diagnostics["workloads.txt"] = []byte(strings.Join(summaries, "\n"))
+	}
+
+	if len(collectErrors) > 0 {
+		diagnostics["errors.txt"] = []byte(strings.Join(collectErrors, "\n") + "\n")
+	}
+
+	return diagnostics
+}
+
+// WriteArchive writes the diagnostics as a gzipped tarball at path.
+func (d Diagnostics) WriteArchive(path string) error {
+	// #nosec G301
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// #nosec G304
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(d[name])), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(d[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// listReleasePods returns the pods matching selector and the helm hook pods
+// of the release, which don't necessarily carry the release labels.
+func (k Kubectl) listReleasePods(context context.Context, namespace, selector, release string) ([]corev1.Pod, error) {
+	list, err := k.clientset.CoreV1().Pods(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	pods := list.Items
+
+	if selector == "" {
+		return pods, nil
+	}
+
+	all, err := k.clientset.CoreV1().Pods(namespace).List(context, metav1.ListOptions{})
+	if err != nil {
+		utils.LogWarning(fmt.Sprintf("error listing helm hook pods in namespace %s: %v", namespace, err))
+		return pods, nil
+	}
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		seen[pod.Name] = true
+	}
+	for _, pod := range all.Items {
+		if _, isHook := pod.Annotations[helmHookAnnotation]; isHook && !seen[pod.Name] && strings.HasPrefix(pod.Name, release) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func formatEvents(events []corev1.Event) []byte {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\n",
+			eventTime(e).Format(time.RFC3339), e.Type, e.Reason,
+			strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, strings.TrimSpace(e.Message))
+	}
+	w.Flush()
+	return []byte(sb.String())
+}
+
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+func formatPods(pods []corev1.Pod) []byte {
+	var sb strings.Builder
+	for _, pod := range pods {
+		fmt.Fprintf(&sb, "Pod: %s\n", pod.Name)
+		fmt.Fprintf(&sb, "  Phase: %s\n", pod.Status.Phase)
+		if pod.Status.Reason != "" {
+			fmt.Fprintf(&sb, "  Reason: %s: %s\n", pod.Status.Reason, pod.Status.Message)
+		}
+		if hook, ok := pod.Annotations[helmHookAnnotation]; ok {
+			fmt.Fprintf(&sb, "  Helm hook: %s\n", hook)
+		}
+		if len(pod.Status.Conditions) > 0 {
+			fmt.Fprintln(&sb, "  Conditions:")
+			for _, c := range pod.Status.Conditions {
+				fmt.Fprintf(&sb, "    %s=%s %s %s\n", c.Type, c.Status, c.Reason, c.Message)
+			}
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		if len(statuses) > 0 {
+			fmt.Fprintln(&sb, "  Containers:")
+			for _, cs := range statuses {
+				fmt.Fprintf(&sb, "    %s: ready=%t restarts=%d image=%s state=%s\n", cs.Name, cs.Ready, cs.RestartCount, cs.Image, containerState(cs.State))
+				if cs.LastTerminationState.Terminated != nil {
+					fmt.Fprintf(&sb, "      last state: %s\n", containerState(cs.LastTerminationState))
+				}
+			}
+		}
+		fmt.Fprintln(&sb)
+	}
+	return []byte(sb.String())
+}
+
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting (%s) %s", state.Waiting.Reason, state.Waiting.Message)
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated (%s, exit code %d) %s", state.Terminated.Reason, state.Terminated.ExitCode, state.Terminated.Message)
+	case state.Running != nil:
+		return "Running"
+	default:
+		return "Unknown"
+	}
+}
+
+func describeDeployment(d v1.Deployment) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Deployment: %s\n", d.Name)
+	fmt.Fprintf(&sb, "  Replicas: %d desired | %d updated | %d ready | %d available | %d unavailable\n",
+		desiredReplicas(d.Spec.Replicas), d.Status.UpdatedReplicas, d.Status.ReadyReplicas, d.Status.AvailableReplicas, d.Status.UnavailableReplicas)
+	if len(d.Status.Conditions) > 0 {
+		fmt.Fprintln(&sb, "  Conditions:")
+		for _, c := range d.Status.Conditions {
+			fmt.Fprintf(&sb, "    %s=%s %s %s\n", c.Type, c.Status, c.Reason, c.Message)
+		}
+	}
+	return sb.String()
+}
+
+func describeDaemonSet(ds v1.DaemonSet) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "DaemonSet: %s\n", ds.Name)
+	fmt.Fprintf(&sb, "  Pods: %d desired | %d scheduled | %d ready | %d available | %d unavailable\n",
+		ds.Status.DesiredNumberScheduled, ds.Status.CurrentNumberScheduled, ds.Status.NumberReady, ds.Status.NumberAvailable, ds.Status.NumberUnavailable)
+	for _, c := range ds.Status.Conditions {
+		fmt.Fprintf(&sb, "    %s=%s %s %s\n", c.Type, c.Status, c.Reason, c.Message)
+	}
+	return sb.String()
+}
+
+func describeStatefulSet(sts v1.StatefulSet) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "StatefulSet: %s\n", sts.Name)
+	fmt.Fprintf(&sb, "  Replicas: %d desired | %d current | %d ready | %d available\n",
+		desiredReplicas(sts.Spec.Replicas), sts.Status.CurrentReplicas, sts.Status.ReadyReplicas, sts.Status.AvailableReplicas)
+	for _, c := range sts.Status.Conditions {
+		fmt.Fprintf(&sb, "    %s=%s %s %s\n", c.Type, c.Status, c.Reason, c.Message)
+	}
+	return sb.String()
+}
+
+// desiredReplicas returns the replicas of a workload spec, which default to 1.
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
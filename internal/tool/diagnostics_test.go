@@ -0,0 +1,94 @@
+package tool
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listDeployments = getDeploymentsList
+	listDaemonSets = getDaemonSetsList
+	listStatefulSets = getStatefulSetsList
+
+	labels := map[string]string{"app.kubernetes.io/instance": "rel"}
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "rel-app", Namespace: "ns", Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "app",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "rel-test-connection", Namespace: "ns", Annotations: map[string]string{"helm.sh/hook": "test"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "wget"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "ns"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "other"}}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "rel-app.1", Namespace: "ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "rel-app"},
+			Type:           "Warning",
+			Reason:         "Failed",
+			Message:        "Failed to pull image",
+		},
+		&v1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "rel-app", Namespace: "ns", Labels: labels},
+			Status:     v1.DeploymentStatus{UnavailableReplicas: 1},
+		},
+	)
+
+	k := Kubectl{clientset: clientset}
+	diagnostics := k.CollectDiagnostics(ctx, "ns", "app.kubernetes.io/instance=rel", "rel")
+
+	require.Contains(t, string(diagnostics["events.txt"]), "Failed to pull image")
+	require.Contains(t, string(diagnostics["pods.txt"]), "Pod: rel-app")
+	require.Contains(t, string(diagnostics["pods.txt"]), "Waiting (ImagePullBackOff)")
+	require.Contains(t, string(diagnostics["pods.txt"]), "Helm hook: test")
+	require.NotContains(t, string(diagnostics["pods.txt"]), "unrelated")
+	require.Contains(t, diagnostics, "logs/rel-app/app.log")
+	require.Contains(t, diagnostics, "logs/rel-test-connection/wget.log")
+	require.Contains(t, string(diagnostics["workloads.txt"]), "Deployment: rel-app")
+	require.NotContains(t, diagnostics, "errors.txt")
+
+	archive := filepath.Join(t.TempDir(), "out", "diagnostics.tar.gz")
+	require.NoError(t, diagnostics.WriteArchive(archive))
+
+	f, err := os.Open(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	require.ElementsMatch(t, []string{"events.txt", "pods.txt", "workloads.txt", "logs/rel-app/app.log", "logs/rel-test-connection/wget.log"}, names)
+}
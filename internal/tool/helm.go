@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/utils"
@@ -15,29 +17,86 @@ import (
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/strvals"
 )
 
 type Helm struct {
 	config      *action.Configuration
 	envSettings *cli.EnvSettings
-	timeout     time.Duration
-	args        map[string]interface{}
+	// namespace is where the action configuration keeps track of releases.
+	namespace       string
+	timeout         time.Duration
+	args            map[string]interface{}
+	serverSideApply bool
+	waitStrategy    kube.WaitStrategy
+}
+
+// WaitStrategies are the wait strategies accepted by ParseWaitStrategy.
+var WaitStrategies = []kube.WaitStrategy{kube.LegacyStrategy, kube.StatusWatcherStrategy, kube.HookOnlyStrategy}
+
+// ParseWaitStrategy returns the wait strategy named s, defaulting to
+// kube.LegacyStrategy when s is empty.
+func ParseWaitStrategy(s string) (kube.WaitStrategy, error) {
+	if s == "" {
+		return kube.LegacyStrategy, nil
+	}
+	for _, strategy := range WaitStrategies {
+		if strings.EqualFold(s, string(strategy)) {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid wait strategy %q, expected one of %v", s, WaitStrategies)
 }
 
 func NewHelm(envSettings *cli.EnvSettings, args map[string]interface{}, timeout time.Duration) (*Helm, error) {
-	helm := &Helm{envSettings: envSettings, args: args, timeout: timeout}
+	return newHelm(envSettings, envSettings.Namespace(), args, timeout)
+}
+
+// newHelm returns a Helm whose action configuration keeps track of the
+// releases in namespace.
+func newHelm(envSettings *cli.EnvSettings, namespace string, args map[string]interface{}, timeout time.Duration) (*Helm, error) {
+	helm := &Helm{envSettings: envSettings, namespace: namespace, args: args, timeout: timeout, waitStrategy: kube.LegacyStrategy}
 	if timeout < 5*time.Minute {
 		helm.timeout = 5 * time.Minute
 	}
 	config := action.NewConfiguration(action.ConfigurationSetLogger(utils.SlogHandler()))
-	if err := config.Init(envSettings.RESTClientGetter(), envSettings.Namespace(), os.Getenv("HELM_DRIVER")); err != nil {
+	if err := config.Init(envSettings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER")); err != nil {
 		return nil, err
 	}
 	helm.config = config
 	return helm, nil
 }
 
+// Clone returns a Helm sharing the settings of h with its own action
+// configuration, keeping track of the releases in namespace, so that it can
+// be used concurrently with h to install releases in namespace.
+func (h Helm) Clone(namespace string) (*Helm, error) {
+	clone, err := newHelm(h.envSettings, namespace, h.args, h.timeout)
+	if err != nil {
+		return nil, err
+	}
+	clone.SetApplyOptions(h.serverSideApply, h.waitStrategy)
+	return clone, nil
+}
+
+// SetApplyOptions sets whether releases are applied with server-side apply
+// and the strategy used to wait for resources on install, upgrade and
+// uninstall.
+//
+// Both default to the helm/v3 behavior, client-side apply and
+// kube.LegacyStrategy, because the helm/v4 defaults require additional
+// permissions: server-side apply requires patch permissions to some cluster
+// scoped resources (e.g. ClusterRoleBindings) and kube.StatusWatcherStrategy
+// requires list permissions for them. These permissions are currently
+// disallowed in our public-good cluster, the historical behavior gives
+// partners a little more room to use it before they have to use their own
+// cluster and submit reports.
+func (h *Helm) SetApplyOptions(serverSideApply bool, waitStrategy kube.WaitStrategy) {
+	h.serverSideApply = serverSideApply
+	h.waitStrategy = waitStrategy
+}
+
 func (h Helm) Install(ctx context.Context, namespace, chart, release, valuesFile string) error {
 	utils.LogInfo(fmt.Sprintf("Execute helm install. namespace: %s, release: %s chart: %s", namespace, release, chart))
 	client := action.NewInstall(h.config)
@@ -46,26 +105,10 @@ func (h Helm) Install(ctx context.Context, namespace, chart, release, valuesFile
 	// default timeout duration
 	// ref: https://helm.sh/docs/helm/helm_install
 	client.Timeout = h.timeout
-	// TODO(komish): Swapping ServerSideApply and WaitStrategy settings for
-	// helm/v3 defaults instead of helm/v4 defaults.
-	//
-	// ServerSideApply (default in helm/v4) requires patch permissions (e.g. for
-	// ClusterRoleBindings) which are currently disallowed in our public-good
-	// cluster.
-	//
-	// StatusWatcherStrategy (default in helm/v4) requires list permissions
-	// (e.g. for ClusterRoleBinding) at the cluster scope which is currently
-	// disallowed in our public-good cluster.
-	//
-	// To enable the newer approaches, we should
-	// (a) consider if we want to expand service account permissions in our public-good clusters
-	// (b) offer these settings as options
-	// (c) force the newer behavior and adjust our test fixtures which expect the old behavior.
-	//
-	// Enabling the historical functionality is potentially less effective, but gives partners
-	// a little more room to use our cluster before they have to use their own and submit reports.
-	client.ServerSideApply = false
-	client.WaitStrategy = kube.LegacyStrategy
+	// ServerSideApply and WaitStrategy default to the helm/v3 behavior, see
+	// SetApplyOptions.
+	client.ServerSideApply = h.serverSideApply
+	client.WaitStrategy = h.waitStrategy
 
 	cp, err := client.LocateChart(chart, h.envSettings)
 	if err != nil {
@@ -151,10 +194,7 @@ func (h Helm) Test(ctx context.Context, namespace, release string) error {
 func (h Helm) Uninstall(namespace, release string) error {
 	utils.LogInfo(fmt.Sprintf("Execute helm uninstall. namespace: %s, release: %s", namespace, release))
 	client := action.NewUninstall(h.config)
-	// LegacyStrategy enabled over StatusWatcherStrategy because the latter requires
-	// additional perms (verb: list), in some cases for resources at a cluster scope
-	// which is not allowed in our public-good cluster.
-	client.WaitStrategy = kube.LegacyStrategy
+	client.WaitStrategy = h.waitStrategy
 	// TODO: support other options if required
 	_, err := client.Run(release)
 	if err != nil {
@@ -166,19 +206,43 @@ func (h Helm) Uninstall(namespace, release string) error {
 	return nil
 }
 
+// LocateChart resolves chart to a path on disk. The chart can be a local path,
+// an OCI reference, or the name of a chart in the Helm repository at repoURL,
+// in which cases it is downloaded to the repository cache. version can be an
+// exact version or a semver constraint; the highest matching version is used.
+func (h Helm) LocateChart(chart, repoURL, version string) (string, error) {
+	utils.LogInfo(fmt.Sprintf("Locate chart. chart: %s, repo: %s, version: %s", chart, repoURL, version))
+	client := action.NewInstall(h.config)
+	client.RepoURL = repoURL
+	client.Version = version
+
+	if registry.IsOCI(chart) {
+		registryClient, err := registry.NewClient(
+			registry.ClientOptEnableCache(true),
+			registry.ClientOptCredentialsFile(h.envSettings.RegistryConfig),
+		)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error creating registry client: %v", err))
+			return "", err
+		}
+		client.SetRegistryClient(registryClient)
+	}
+
+	cp, err := client.LocateChart(chart, h.envSettings)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error LocateChart: %v", err))
+		return "", err
+	}
+	return cp, nil
+}
+
 func (h Helm) Upgrade(ctx context.Context, namespace, chart, release string) error {
 	utils.LogInfo(fmt.Sprintf("Execute helm upgrade. namespace: %s, release: %s chart: %s", namespace, release, chart))
 	client := action.NewUpgrade(h.config)
 	client.Namespace = namespace
 	client.ReuseValues = true
-	// LegacyStrategy enabled over StatusWatcherStrategy because the latter requires
-	// additional perms (verb: list), in some cases for resources at a cluster scope
-	// which is not allowed in our public-good cluster.
-	client.WaitStrategy = kube.LegacyStrategy
-	// ServerSideApply disabled for our use case because it seems to  require
-	// patch permissions to certain resources (e.g. ClusterRoleBindings) at
-	// a cluster scope, which is disallowed in our cluster.
-	client.ServerSideApply = "false"
+	client.WaitStrategy = h.waitStrategy
+	client.ServerSideApply = strconv.FormatBool(h.serverSideApply)
 
 	cp, err := client.LocateChart(chart, h.envSettings)
 	if err != nil {
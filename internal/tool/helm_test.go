@@ -14,6 +14,7 @@ import (
 	chartcommon "helm.sh/helm/v4/pkg/chart/common"
 	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 
 	releasecommon "helm.sh/helm/v4/pkg/release/common"
@@ -315,3 +316,38 @@ func TestReleaseTesting(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWaitStrategy(t *testing.T) {
+	for input, expected := range map[string]kube.WaitStrategy{
+		"":         kube.LegacyStrategy,
+		"legacy":   kube.LegacyStrategy,
+		"watcher":  kube.StatusWatcherStrategy,
+		"hookonly": kube.HookOnlyStrategy,
+	} {
+		strategy, err := ParseWaitStrategy(input)
+		require.NoError(t, err)
+		require.Equal(t, expected, strategy)
+	}
+
+	_, err := ParseWaitStrategy("eventually")
+	require.ErrorContains(t, err, `invalid wait strategy "eventually"`)
+}
+
+func TestClone(t *testing.T) {
+	t.Setenv("HELM_DRIVER", "memory")
+	settings := cli.New()
+	settings.SetNamespace("verifier")
+
+	helm, err := NewHelm(settings, nil, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "verifier", helm.namespace)
+	helm.SetApplyOptions(true, kube.HookOnlyStrategy)
+
+	clone, err := helm.Clone("verifier-1")
+	require.NoError(t, err)
+	require.Equal(t, "verifier-1", clone.namespace)
+	require.NotSame(t, helm.config, clone.config)
+	require.True(t, clone.serverSideApply)
+	require.Equal(t, kube.HookOnlyStrategy, clone.waitStrategy)
+	require.Equal(t, "verifier", settings.Namespace())
+}
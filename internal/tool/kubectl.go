@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"helm.sh/helm/v4/pkg/cli"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubectl/pkg/scheme"
 
@@ -29,10 +34,16 @@ type workloadNotReady struct {
 	ResourceType string
 	Name         string
 	Unavailable  int32
+	// Reason explains why resources without replicas are not ready.
+	Reason string
+	// Failed is set for resources which will not become ready, e.g. failed
+	// Jobs, so that there is no point in waiting for them.
+	Failed bool
 }
 
 type Kubectl struct {
-	clientset kubernetes.Interface
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
 }
 
 func NewKubectl(kubeConfig clientcmd.ClientConfig) (*Kubectl, error) {
@@ -41,10 +52,16 @@ func NewKubectl(kubeConfig clientcmd.ClientConfig) (*Kubectl, error) {
 		return nil, err
 	}
 
+	kubectl := new(Kubectl)
+	// The dynamic client needs the config untouched by the settings below.
+	kubectl.dynamicClient, err = dynamic.NewForConfig(rest.CopyConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
 	config.APIPath = "/api"
 	config.GroupVersion = &schema.GroupVersion{Group: "core", Version: "v1"}
 	config.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: scheme.Codecs}
-	kubectl := new(Kubectl)
 	kubectl.clientset, err = kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -53,68 +70,71 @@ func NewKubectl(kubeConfig clientcmd.ClientConfig) (*Kubectl, error) {
 	return kubectl, nil
 }
 
-// WaitForWorkloadResources returns nil when all pods for requested workload resources are confirmed ready
+// WaitForWorkloadResources returns nil when all requested resources are confirmed ready
 // or an error if resources cannot be confirmed ready before the timeout is exceeded.
-// Currently checks deployments, daemonSets, and statefulSets.
-func (k Kubectl) WaitForWorkloadResources(context context.Context, namespace string, selector string) error {
+// The evaluators determine which resources are checked; deployments, daemonSets and
+// statefulSets are checked if none is given.
+func (k Kubectl) WaitForWorkloadResources(context context.Context, namespace string, selector string, evaluators ...ReadinessEvaluator) error {
+	if len(evaluators) == 0 {
+		evaluators = []ReadinessEvaluator{deploymentEvaluator{}, daemonSetEvaluator{}, statefulSetEvaluator{}}
+	}
+
 	deadline, _ := context.Deadline()
 	unavailableWorkloadResources := []workloadNotReady{{Name: "none", Unavailable: 1}}
 	getWorkloadResourceError := ""
 
-	// Loop until timeout reached or all requested pods are available
+	// Loop until timeout reached or all requested resources are ready
 	utils.LogInfo(fmt.Sprintf("Start wait for workloads resources. --timeout time left: %s ", time.Until(deadline).String()))
 	for deadline.After(time.Now()) && len(unavailableWorkloadResources) > 0 {
 		unavailableWorkloadResources = []workloadNotReady{}
 
-		deployments, errDeployments := listDeployments(k, context, namespace, selector)
-		daemonSets, errDaemonSets := listDaemonSets(k, context, namespace, selector)
-		statefulSets, errStatefulSets := listStatefulSets(k, context, namespace, selector)
+		var errResourceType string
+		var errMsg error
+		for _, evaluator := range evaluators {
+			notReady, err := evaluator.NotReady(context, k, namespace, selector)
+			if err != nil {
+				errResourceType = evaluator.Kind()
+				errMsg = err
+				break
+			}
+			unavailableWorkloadResources = append(unavailableWorkloadResources, notReady...)
+		}
 
 		// Inspect the resources that are successfully returned or handle API request errors
-		if errDeployments == nil && errDaemonSets == nil && errStatefulSets == nil {
+		if errMsg == nil {
 			getWorkloadResourceError = ""
-			// Check the number of unavailable replicas for each workload type
-			for _, deployment := range deployments {
-				if deployment.Status.UnavailableReplicas > 0 {
-					unavailableWorkloadResources = append(unavailableWorkloadResources, workloadNotReady{Name: deployment.Name, ResourceType: "Deployment", Unavailable: deployment.Status.UnavailableReplicas})
-				}
-			}
-			for _, daemonSet := range daemonSets {
-				if daemonSet.Status.NumberUnavailable > 0 {
-					unavailableWorkloadResources = append(unavailableWorkloadResources, workloadNotReady{Name: daemonSet.Name, ResourceType: "DaemonSet", Unavailable: daemonSet.Status.NumberUnavailable})
+
+			// Resources which failed will not become ready, stop waiting.
+			var failed []string
+			for _, unavailableWorkloadResource := range unavailableWorkloadResources {
+				if unavailableWorkloadResource.Failed {
+					failed = append(failed, fmt.Sprintf("%s/%s: %s", unavailableWorkloadResource.ResourceType, unavailableWorkloadResource.Name, unavailableWorkloadResource.Reason))
 				}
 			}
-			for _, statefulSet := range statefulSets {
-				// StatefulSet doesn't report unavailable replicas so it is calculated here
-				unavailableReplicas := statefulSet.Status.Replicas - statefulSet.Status.AvailableReplicas
-				if unavailableReplicas > 0 {
-					unavailableWorkloadResources = append(unavailableWorkloadResources, workloadNotReady{Name: statefulSet.Name, ResourceType: "StatefulSet", Unavailable: unavailableReplicas})
-				}
+			if len(failed) > 0 {
+				errorMsg := "error failed workload resources: " + strings.Join(failed, ", ")
+				utils.LogError(errorMsg)
+				return errors.New(errorMsg)
 			}
 
-			// If any pods are unavailable report it and sleep until the next loop
+			// If any resources are not ready report it and sleep until the next loop
 			// Else everything is available and the loop will exit
 			if len(unavailableWorkloadResources) > 0 {
 				utils.LogInfo(fmt.Sprintf("Wait for %d workload resources:", len(unavailableWorkloadResources)))
 				for _, unavailableWorkloadResource := range unavailableWorkloadResources {
-					utils.LogInfo(fmt.Sprintf("    - %s %s with %d unavailable pods", unavailableWorkloadResource.ResourceType, unavailableWorkloadResource.Name, unavailableWorkloadResource.Unavailable))
+					if unavailableWorkloadResource.Reason != "" {
+						utils.LogInfo(fmt.Sprintf("    - %s %s: %s", unavailableWorkloadResource.ResourceType, unavailableWorkloadResource.Name, unavailableWorkloadResource.Reason))
+					} else {
+						utils.LogInfo(fmt.Sprintf("    - %s %s with %d unavailable pods", unavailableWorkloadResource.ResourceType, unavailableWorkloadResource.Name, unavailableWorkloadResource.Unavailable))
+					}
 				}
 				time.Sleep(time.Second)
 			} else {
 				utils.LogInfo(fmt.Sprintf("Finish wait for workload resources, --timeout time left %s", time.Until(deadline).String()))
 			}
 		} else {
-			resourceType := "Deployment"
-			errMsg := errDeployments
-			if errDaemonSets != nil {
-				resourceType = "DaemonSet"
-				errMsg = errDaemonSets
-			} else if errStatefulSets != nil {
-				resourceType = "StatefulSet"
-				errMsg = errStatefulSets
-			}
-			unavailableWorkloadResources = []workloadNotReady{{Name: "none", ResourceType: resourceType, Unavailable: 1}}
-			getWorkloadResourceError = fmt.Sprintf("error getting %s from namespace %s : %v", resourceType, namespace, errMsg)
+			unavailableWorkloadResources = []workloadNotReady{{Name: "none", ResourceType: errResourceType, Unavailable: 1}}
+			getWorkloadResourceError = fmt.Sprintf("error getting %s from namespace %s : %v", errResourceType, namespace, errMsg)
 			utils.LogWarning(getWorkloadResourceError)
 			time.Sleep(time.Second)
 		}
@@ -138,6 +158,14 @@ func (k Kubectl) WaitForWorkloadResources(context context.Context, namespace str
 	return nil
 }
 
+func (k Kubectl) CreateNamespace(context context.Context, namespace string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	if _, err := k.clientset.CoreV1().Namespaces().Create(context, ns, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (k Kubectl) DeleteNamespace(context context.Context, namespace string) error {
 	if err := k.clientset.CoreV1().Namespaces().Delete(context, namespace, *metav1.NewDeleteOptions(0)); err != nil {
 		return err
@@ -153,6 +181,43 @@ func (k Kubectl) GetServerVersion() (*version.Info, error) {
 	return version, err
 }
 
+// clusterVersionResource is the OpenShift ClusterVersion resource, whose
+// single instance is named version.
+var clusterVersionResource = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusterversions"}
+
+// GetOpenShiftVersion returns the full version of the OpenShift cluster, e.g.
+// 4.16.12, read from its ClusterVersion: the latest completed update of its
+// history, or the desired version when no update completed. While an update
+// is in progress the desired version is the target of the update rather
+// than the version running, so it is only used as a fallback.
+// The error satisfies apierrors.IsNotFound when the cluster isn't an
+// OpenShift cluster.
+func (k Kubectl) GetOpenShiftVersion(context context.Context) (string, error) {
+	if k.dynamicClient == nil {
+		return "", errors.New("no dynamic client available to get the ClusterVersion")
+	}
+	clusterVersion, err := k.dynamicClient.Resource(clusterVersionResource).Get(context, "version", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	history, _, _ := unstructured.NestedSlice(clusterVersion.Object, "status", "history")
+	// The history is ordered from the most recent update.
+	for _, h := range history {
+		update, ok := h.(map[string]interface{})
+		if !ok || update["state"] != "Completed" {
+			continue
+		}
+		if version, ok := update["version"].(string); ok && version != "" {
+			return version, nil
+		}
+	}
+	if version, _, _ := unstructured.NestedString(clusterVersion.Object, "status", "desired", "version"); version != "" {
+		return version, nil
+	}
+	return "", errors.New("ClusterVersion reports neither a completed update nor a desired version")
+}
+
 func GetClientConfig(envSettings *cli.EnvSettings) clientcmd.ClientConfig {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if len(envSettings.KubeConfig) > 0 {
@@ -10,7 +10,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	v1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -354,3 +359,83 @@ func statefulSetTestListBadToGood(k Kubectl, context context.Context, namespace
 	fmt.Println("statefulSetTestListBadToGood good path")
 	return statefulSetTestListGood(k, context, namespace, selector)
 }
+
+func TestGetOpenShiftVersion(t *testing.T) {
+	clusterVersion := func(status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "ClusterVersion",
+			"metadata":   map[string]interface{}{"name": "version"},
+			"status":     status,
+		}}
+	}
+
+	type testCase struct {
+		description string
+		objects     []runtime.Object
+		version     string
+		error       string
+		notFound    bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "latest completed update",
+			objects: []runtime.Object{clusterVersion(map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.16.10"},
+				"history": []interface{}{
+					map[string]interface{}{"state": "Completed", "version": "4.16.10"},
+					map[string]interface{}{"state": "Completed", "version": "4.16.8"},
+				},
+			})},
+			version: "4.16.10",
+		},
+		{
+			description: "update in progress",
+			objects: []runtime.Object{clusterVersion(map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.17.2"},
+				"history": []interface{}{
+					map[string]interface{}{"state": "Partial", "version": "4.17.2"},
+					map[string]interface{}{"state": "Completed", "version": "4.16.10"},
+				},
+			})},
+			version: "4.16.10",
+		},
+		{
+			description: "desired version without completed update",
+			objects: []runtime.Object{clusterVersion(map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.16.12"},
+				"history": []interface{}{map[string]interface{}{"state": "Partial", "version": "4.16.12"}},
+			})},
+			version: "4.16.12",
+		},
+		{
+			description: "no version reported",
+			objects:     []runtime.Object{clusterVersion(map[string]interface{}{})},
+			error:       "ClusterVersion reports neither a completed update nor a desired version",
+		},
+		{
+			description: "not an OpenShift cluster",
+			notFound:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{clusterVersionResource: "ClusterVersionList"}, tc.objects...)
+			k := Kubectl{clientset: fake.NewClientset(), dynamicClient: dynamicClient}
+
+			version, err := k.GetOpenShiftVersion(context.Background())
+			switch {
+			case tc.notFound:
+				require.True(t, apierrors.IsNotFound(err))
+			case tc.error != "":
+				require.EqualError(t, err, tc.error)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.version, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ReadinessEvaluator reports the resources of a kind, selected by a label
+// selector, that are not ready yet.
+type ReadinessEvaluator interface {
+	// Kind is the kind of resources evaluated, used in messages.
+	Kind() string
+	// NotReady returns the resources of the namespace matching selector
+	// that are not ready.
+	NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error)
+}
+
+// DefaultWaitKinds are the kinds WaitForWorkloadResources waits for when no
+// evaluators are given.
+var DefaultWaitKinds = []string{"Deployment", "DaemonSet", "StatefulSet"}
+
+// readinessEvaluators maps the kinds that can be waited for to their
+// evaluators.
+var readinessEvaluators = map[string]ReadinessEvaluator{
+	"deployment":            deploymentEvaluator{},
+	"daemonset":             daemonSetEvaluator{},
+	"statefulset":           statefulSetEvaluator{},
+	"job":                   jobEvaluator{},
+	"persistentvolumeclaim": pvcEvaluator{},
+	"service":               serviceEvaluator{},
+	"route":                 routeEvaluator{},
+}
+
+// NewReadinessEvaluators returns the evaluators for the given kinds, e.g.
+// Job, and for the resources whose readiness is given by their Ready
+// status condition, in the resource.version.group form, e.g.
+// certificates.v1.cert-manager.io. DefaultWaitKinds are used if both are
+// empty.
+func NewReadinessEvaluators(kinds []string, readyConditionResources []string) ([]ReadinessEvaluator, error) {
+	if len(kinds) == 0 && len(readyConditionResources) == 0 {
+		kinds = DefaultWaitKinds
+	}
+
+	var evaluators []ReadinessEvaluator
+	for _, kind := range kinds {
+		evaluator, ok := readinessEvaluators[strings.ToLower(kind)]
+		if !ok {
+			return nil, fmt.Errorf("waiting for kind %q is not supported", kind)
+		}
+		evaluators = append(evaluators, evaluator)
+	}
+
+	for _, resource := range readyConditionResources {
+		gvr, _ := schema.ParseResourceArg(resource)
+		if gvr == nil {
+			return nil, fmt.Errorf("invalid resource %q, expected resource.version.group", resource)
+		}
+		if gvr.GroupResource() == routeGVR.GroupResource() {
+			// Routes have no Ready condition, they are admitted by routers.
+			evaluators = append(evaluators, routeEvaluator{})
+			continue
+		}
+		evaluators = append(evaluators, readyConditionEvaluator{gvr: *gvr})
+	}
+
+	return evaluators, nil
+}
+
+type deploymentEvaluator struct{}
+
+func (deploymentEvaluator) Kind() string { return "Deployment" }
+
+func (deploymentEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	deployments, err := listDeployments(k, context, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, deployment := range deployments {
+		if deployment.Status.UnavailableReplicas > 0 {
+			notReady = append(notReady, workloadNotReady{Name: deployment.Name, ResourceType: "Deployment", Unavailable: deployment.Status.UnavailableReplicas})
+		}
+	}
+	return notReady, nil
+}
+
+type daemonSetEvaluator struct{}
+
+func (daemonSetEvaluator) Kind() string { return "DaemonSet" }
+
+func (daemonSetEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	daemonSets, err := listDaemonSets(k, context, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, daemonSet := range daemonSets {
+		if daemonSet.Status.NumberUnavailable > 0 {
+			notReady = append(notReady, workloadNotReady{Name: daemonSet.Name, ResourceType: "DaemonSet", Unavailable: daemonSet.Status.NumberUnavailable})
+		}
+	}
+	return notReady, nil
+}
+
+type statefulSetEvaluator struct{}
+
+func (statefulSetEvaluator) Kind() string { return "StatefulSet" }
+
+func (statefulSetEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	statefulSets, err := listStatefulSets(k, context, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, statefulSet := range statefulSets {
+		// StatefulSet doesn't report unavailable replicas so it is calculated here
+		unavailableReplicas := statefulSet.Status.Replicas - statefulSet.Status.AvailableReplicas
+		if unavailableReplicas > 0 {
+			notReady = append(notReady, workloadNotReady{Name: statefulSet.Name, ResourceType: "StatefulSet", Unavailable: unavailableReplicas})
+		}
+	}
+	return notReady, nil
+}
+
+// jobEvaluator waits for Jobs to complete. Failed Jobs are reported as
+// failed, so that the wait stops right away.
+type jobEvaluator struct{}
+
+func (jobEvaluator) Kind() string { return "Job" }
+
+func (jobEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	list, err := k.clientset.BatchV1().Jobs(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, job := range list.Items {
+		complete, failed := false, false
+		reason := "not complete"
+		for _, c := range job.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				complete = true
+			case batchv1.JobFailed:
+				failed = true
+				reason = fmt.Sprintf("failed: %s", c.Message)
+			}
+		}
+		if !complete {
+			notReady = append(notReady, workloadNotReady{Name: job.Name, ResourceType: "Job", Reason: reason, Failed: failed})
+		}
+	}
+	return notReady, nil
+}
+
+// pvcEvaluator waits for PersistentVolumeClaims to be bound.
+type pvcEvaluator struct{}
+
+func (pvcEvaluator) Kind() string { return "PersistentVolumeClaim" }
+
+func (pvcEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	list, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, pvc := range list.Items {
+		if pvc.Status.Phase != corev1.ClaimBound {
+			notReady = append(notReady, workloadNotReady{Name: pvc.Name, ResourceType: "PersistentVolumeClaim", Reason: fmt.Sprintf("phase %s", pvc.Status.Phase)})
+		}
+	}
+	return notReady, nil
+}
+
+// serviceEvaluator waits for Services selecting pods to have at least one
+// ready endpoint.
+type serviceEvaluator struct{}
+
+func (serviceEvaluator) Kind() string { return "Service" }
+
+func (serviceEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	list, err := k.clientset.CoreV1().Services(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, service := range list.Items {
+		// Services without a selector have their endpoints managed by
+		// something else, and ExternalName services have none.
+		if len(service.Spec.Selector) == 0 || service.Spec.Type == corev1.ServiceTypeExternalName {
+			continue
+		}
+		slices, err := k.clientset.DiscoveryV1().EndpointSlices(namespace).List(context, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, service.Name),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !hasReadyEndpoint(slices.Items) {
+			notReady = append(notReady, workloadNotReady{Name: service.Name, ResourceType: "Service", Reason: "no ready endpoints"})
+		}
+	}
+	return notReady, nil
+}
+
+func hasReadyEndpoint(slices []discoveryv1.EndpointSlice) bool {
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition should be interpreted as ready.
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routeGVR is the resource of OpenShift Routes.
+var routeGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// routeEvaluator waits for OpenShift Routes to be admitted by every router
+// which reported on them.
+type routeEvaluator struct{}
+
+func (routeEvaluator) Kind() string { return "Route" }
+
+func (routeEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	if k.dynamicClient == nil {
+		return nil, fmt.Errorf("no dynamic client available to list %s", routeGVR.GroupResource())
+	}
+	list, err := k.dynamicClient.Resource(routeGVR).Namespace(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, item := range list.Items {
+		if admitted, reason := routeAdmitted(item); !admitted {
+			notReady = append(notReady, workloadNotReady{Name: item.GetName(), ResourceType: "Route", Reason: reason})
+		}
+	}
+	return notReady, nil
+}
+
+// routeAdmitted returns whether route has been admitted by every router in
+// its status and, if not, the reason why.
+func routeAdmitted(route unstructured.Unstructured) (bool, string) {
+	ingresses, found, err := unstructured.NestedSlice(route.Object, "status", "ingress")
+	if err != nil || !found || len(ingresses) == 0 {
+		return false, "not admitted"
+	}
+	for _, i := range ingresses {
+		ingress, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(ingress, "conditions")
+		admitted := false
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != "Admitted" {
+				continue
+			}
+			admitted = condition["status"] == string(metav1.ConditionTrue)
+			if !admitted {
+				return false, fmt.Sprintf("Admitted=%v by router %v: %v", condition["status"], ingress["routerName"], condition["message"])
+			}
+		}
+		if !admitted {
+			return false, fmt.Sprintf("not admitted by router %v", ingress["routerName"])
+		}
+	}
+	return true, ""
+}
+
+// readyConditionEvaluator waits for resources of any kind to have a Ready
+// condition with status True.
+type readyConditionEvaluator struct {
+	gvr schema.GroupVersionResource
+}
+
+func (e readyConditionEvaluator) Kind() string {
+	return e.gvr.GroupResource().String()
+}
+
+func (e readyConditionEvaluator) NotReady(context context.Context, k Kubectl, namespace string, selector string) ([]workloadNotReady, error) {
+	if k.dynamicClient == nil {
+		return nil, fmt.Errorf("no dynamic client available to list %s", e.Kind())
+	}
+	list, err := k.dynamicClient.Resource(e.gvr).Namespace(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var notReady []workloadNotReady
+	for _, item := range list.Items {
+		if ready, reason := readyCondition(item); !ready {
+			notReady = append(notReady, workloadNotReady{Name: item.GetName(), ResourceType: e.Kind(), Reason: reason})
+		}
+	}
+	return notReady, nil
+}
+
+// readyCondition returns whether obj has a Ready condition with status True
+// and, if not, the reason why.
+func readyCondition(obj unstructured.Unstructured) (bool, string) {
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return false, "no Ready condition"
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == string(metav1.ConditionTrue) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("Ready=%v %v", condition["status"], condition["message"])
+	}
+	return false, "no Ready condition"
+}
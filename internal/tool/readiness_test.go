@@ -0,0 +1,188 @@
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func notReadyNames(notReady []workloadNotReady) []string {
+	var names []string
+	for _, r := range notReady {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestNewReadinessEvaluators(t *testing.T) {
+	evaluators, err := NewReadinessEvaluators(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, evaluators, len(DefaultWaitKinds))
+
+	evaluators, err = NewReadinessEvaluators([]string{"job", "PersistentVolumeClaim", "Service"}, []string{"certificates.v1.cert-manager.io"})
+	require.NoError(t, err)
+	var kinds []string
+	for _, e := range evaluators {
+		kinds = append(kinds, e.Kind())
+	}
+	require.Equal(t, []string{"Job", "PersistentVolumeClaim", "Service", "certificates.cert-manager.io"}, kinds)
+
+	evaluators, err = NewReadinessEvaluators([]string{"Route"}, []string{"routes.v1.route.openshift.io"})
+	require.NoError(t, err)
+	require.Equal(t, []ReadinessEvaluator{routeEvaluator{}, routeEvaluator{}}, evaluators)
+
+	_, err = NewReadinessEvaluators([]string{"Ingress"}, nil)
+	require.ErrorContains(t, err, `waiting for kind "Ingress" is not supported`)
+
+	_, err = NewReadinessEvaluators(nil, []string{"certificates"})
+	require.ErrorContains(t, err, "expected resource.version.group")
+}
+
+func TestReadinessEvaluators(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ready := true
+	clientset := fake.NewClientset(
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "ns"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "ns"},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "ns"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "backoff limit exceeded"}}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "bound", Namespace: "ns"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "ns"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "with-endpoints", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "a"}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "without-endpoints", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "b"}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "example.com"},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "with-endpoints-abc", Namespace: "ns", Labels: map[string]string{discoveryv1.LabelServiceName: "with-endpoints"}},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}}},
+		},
+	)
+
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	newCertificate := func(name string, conditions ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "ns"},
+		}}
+		if len(conditions) > 0 {
+			obj.Object["status"] = map[string]interface{}{"conditions": conditions}
+		}
+		return obj
+	}
+	newRoute := func(name string, ingress ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "route.openshift.io/v1",
+			"kind":       "Route",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "ns"},
+		}}
+		if len(ingress) > 0 {
+			obj.Object["status"] = map[string]interface{}{"ingress": ingress}
+		}
+		return obj
+	}
+	routerStatus := func(router string, conditions ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"routerName": router, "conditions": conditions}
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CertificateList", routeGVR: "RouteList"},
+		newCertificate("issued", map[string]interface{}{"type": "Ready", "status": "True"}),
+		newCertificate("issuing", map[string]interface{}{"type": "Ready", "status": "False", "message": "pending"}),
+		newCertificate("unknown"),
+		newRoute("admitted", routerStatus("default", map[string]interface{}{"type": "Admitted", "status": "True"})),
+		newRoute("rejected",
+			routerStatus("default", map[string]interface{}{"type": "Admitted", "status": "True"}),
+			routerStatus("sharded", map[string]interface{}{"type": "Admitted", "status": "False", "message": "host in use"})),
+		newRoute("pending"),
+	)
+
+	k := Kubectl{clientset: clientset, dynamicClient: dynamicClient}
+
+	testCases := []struct {
+		evaluator ReadinessEvaluator
+		notReady  []string
+	}{
+		{evaluator: jobEvaluator{}, notReady: []string{"running", "failed"}},
+		{evaluator: pvcEvaluator{}, notReady: []string{"pending"}},
+		{evaluator: serviceEvaluator{}, notReady: []string{"without-endpoints"}},
+		{evaluator: readyConditionEvaluator{gvr: gvr}, notReady: []string{"issuing", "unknown"}},
+		{evaluator: routeEvaluator{}, notReady: []string{"rejected", "pending"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.evaluator.Kind(), func(t *testing.T) {
+			notReady, err := tc.evaluator.NotReady(ctx, k, "ns", "")
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.notReady, notReadyNames(notReady))
+		})
+	}
+}
+
+func TestWaitForWorkloadResourcesWithEvaluators(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	clientset := fake.NewClientset(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "ns"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	})
+	k := Kubectl{clientset: clientset}
+
+	err := k.WaitForWorkloadResources(ctx, "ns", "", pvcEvaluator{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "PersistentVolumeClaim/pending")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, k.WaitForWorkloadResources(ctx, "other", "", pvcEvaluator{}))
+}
+
+func TestWaitForWorkloadResourcesFailedJob(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	clientset := fake.NewClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "ns"},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "backoff limit exceeded"}}},
+	})
+	k := Kubectl{clientset: clientset}
+
+	start := time.Now()
+	err := k.WaitForWorkloadResources(ctx, "ns", "", jobEvaluator{})
+	require.EqualError(t, err, "error failed workload resources: Job/migrate: failed: backoff limit exceeded")
+	require.Less(t, time.Since(start), 10*time.Second)
+}
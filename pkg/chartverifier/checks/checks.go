@@ -15,24 +15,44 @@ const (
 	RequiredAnnotationsPresent CheckName = "required-annotations-present"
 	SignatureIsValid           CheckName = "signature-is-valid"
 	HasNotes                   CheckName = "has-notes"
-	MandatoryCheckType         CheckType = "Mandatory"
-	OptionalCheckType          CheckType = "Optional"
-	ExperimentalCheckType      CheckType = "Experimental"
+
+	CanBeInstalledWithoutClusterAdminPrivileges CheckName = "can-be-installed-without-cluster-admin-privileges"
+	CanBeInstalledWithoutManualPreRequisites    CheckName = "can-be-installed-without-manual-prerequisites"
+	NotContainsInfraPluginsAndDrivers           CheckName = "not-contains-infra-plugins-and-drivers"
+	KeywordsAreOpenshiftCategories              CheckName = "keywords-are-openshift-categories"
+	APIVersionsSupported                        CheckName = "api-versions-supported"
+	PodSecurityCompatible                       CheckName = "pod-security-compatible"
+	ContainersFollowBestPractices               CheckName = "containers-follow-best-practices"
+	DependenciesAreAudited                      CheckName = "dependencies-are-audited"
+	MetadataIsValid                             CheckName = "metadata-is-valid"
+
+	MandatoryCheckType    CheckType = "Mandatory"
+	OptionalCheckType     CheckType = "Optional"
+	ExperimentalCheckType CheckType = "Experimental"
 )
 
 var setCheckNames = []CheckName{
+	APIVersionsSupported,
+	CanBeInstalledWithoutClusterAdminPrivileges,
+	CanBeInstalledWithoutManualPreRequisites,
 	ChartTesting,
+	ContainersFollowBestPractices,
 	ContainsTest,
 	ContainsValuesSchema,
 	ContainsValues,
+	DependenciesAreAudited,
 	HasKubeVersion,
 	HasNotes,
 	HasReadme,
 	HelmLint,
 	ImagesAreCertified,
 	IsHelmV3,
+	KeywordsAreOpenshiftCategories,
+	MetadataIsValid,
 	NotContainCsiObjects,
 	NotContainsCRDs,
+	NotContainsInfraPluginsAndDrivers,
+	PodSecurityCompatible,
 	RequiredAnnotationsPresent,
 	SignatureIsValid,
 }
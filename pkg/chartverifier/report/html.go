@@ -0,0 +1,45 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates/report.html
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").
+	Funcs(template.FuncMap{"outcome": normalizedOutcome}).
+	Parse(htmlTemplateText))
+
+// htmlView is the data the HTML template renders.
+type htmlView struct {
+	Title    string
+	Outcome  OutcomeType
+	Counts   string
+	Results  []*CheckReport
+	Failures []*CheckReport
+	Metadata []metadataRow
+}
+
+// html renders the report as a self-contained HTML page, with the same
+// content as markdown.
+func (r *Report) html() (string, error) {
+	counts := r.outcomeCounts()
+	view := htmlView{
+		Title:    r.chartTitle(),
+		Outcome:  counts.overallOutcome(),
+		Counts:   counts.String(),
+		Results:  r.Results,
+		Failures: r.failedResults(),
+		Metadata: r.metadataRows(),
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, view); err != nil {
+		return "", fmt.Errorf("report html render failed : %v", err)
+	}
+	return b.String(), nil
+}
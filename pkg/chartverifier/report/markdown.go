@@ -0,0 +1,95 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// outcomeEmoji marks the outcomes in GitHub-flavored markdown.
+var outcomeEmoji = map[OutcomeType]string{
+	PassOutcomeType:    ":white_check_mark:",
+	FailOutcomeType:    ":x:",
+	SkippedOutcomeType: ":fast_forward:",
+	UnknownOutcomeType: ":grey_question:",
+}
+
+// markdown renders the report as GitHub-flavored markdown, with a table of
+// the outcome of the checks, the reasons and findings of the checks which
+// failed, and the metadata of the report.
+func (r *Report) markdown() string {
+	var b strings.Builder
+	counts := r.outcomeCounts()
+
+	fmt.Fprintf(&b, "# Chart verifier report: %s\n\n", markdownText(r.chartTitle()))
+	fmt.Fprintf(&b, "**%s %s**: %s\n\n", markdownOutcome(counts.overallOutcome()), counts.overallOutcome(), counts)
+
+	b.WriteString("## Checks\n\n")
+	b.WriteString("| Check | Type | Outcome | Duration |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "| %s | %s | %s %s | %s |\n", markdownCell(string(result.Check)), markdownCell(string(result.Type)),
+			markdownOutcome(result.Outcome), markdownCell(result.Outcome), markdownCell(result.Duration))
+	}
+
+	if failed := r.failedResults(); len(failed) > 0 {
+		b.WriteString("\n## Failures\n")
+		for _, result := range failed {
+			fmt.Fprintf(&b, "\n### %s\n\n", markdownText(string(result.Check)))
+			b.WriteString(markdownCodeBlock(result.Reason))
+			if len(result.Findings) > 0 {
+				b.WriteString("\n| Severity | Code | Message | Object | Path | Remediation |\n")
+				b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+				for _, finding := range result.Findings {
+					path := finding.Path
+					if finding.ValuesFile != "" {
+						path = strings.TrimSpace(path + " (" + finding.ValuesFile + ")")
+					}
+					fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", markdownCell(finding.Severity), markdownCell(finding.Code),
+						markdownCell(finding.Message), markdownCell(finding.Object), markdownCell(path), markdownCell(finding.Remediation))
+				}
+			}
+		}
+	}
+
+	b.WriteString("\n## Metadata\n\n")
+	b.WriteString("| | |\n")
+	b.WriteString("| --- | --- |\n")
+	for _, row := range r.metadataRows() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row.Name, markdownCell(row.Value))
+	}
+
+	return b.String()
+}
+
+func markdownOutcome(outcome OutcomeType) string {
+	if emoji, ok := outcomeEmoji[normalizedOutcome(outcome)]; ok {
+		return emoji
+	}
+	return outcomeEmoji[UnknownOutcomeType]
+}
+
+// markdownText escapes the characters of s which markdown would otherwise
+// format.
+func markdownText(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownCell escapes s for a cell of a table, which must fit on one line.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownText(strings.TrimSpace(s)), "\n", "<br>")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "|", `\|`,
+	"<", "&lt;", ">", "&gt;", "[", `\[`, "]", `\]`,
+)
+
+// markdownCodeBlock fences s so that it is rendered as is, with a fence
+// longer than any run of backticks in s.
+func markdownCodeBlock(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + strings.TrimRight(s, "\n") + "\n" + fence + "\n"
+}
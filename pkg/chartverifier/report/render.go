@@ -0,0 +1,120 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metadataRow is a name and value of the metadata of a rendered report.
+type metadataRow struct {
+	Name  string
+	Value string
+}
+
+// outcomeCounts counts the checks of a report by outcome.
+type outcomeCounts struct {
+	Passed  int
+	Failed  int
+	Skipped int
+	Unknown int
+}
+
+// overallOutcome is FAIL if any check failed, and PASS otherwise.
+func (c outcomeCounts) overallOutcome() OutcomeType {
+	if c.Failed > 0 {
+		return FailOutcomeType
+	}
+	return PassOutcomeType
+}
+
+func (c outcomeCounts) String() string {
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped", c.Passed, c.Failed, c.Skipped)
+	if c.Unknown > 0 {
+		summary += fmt.Sprintf(", %d unknown", c.Unknown)
+	}
+	return summary
+}
+
+// normalizedOutcome returns outcome in upper case, as older reports may
+// have, e.g., Fail.
+func normalizedOutcome(outcome OutcomeType) OutcomeType {
+	return strings.ToUpper(outcome)
+}
+
+func (r *Report) outcomeCounts() outcomeCounts {
+	counts := outcomeCounts{}
+	for _, result := range r.Results {
+		switch normalizedOutcome(result.Outcome) {
+		case PassOutcomeType:
+			counts.Passed++
+		case FailOutcomeType:
+			counts.Failed++
+		case SkippedOutcomeType:
+			counts.Skipped++
+		default:
+			counts.Unknown++
+		}
+	}
+	return counts
+}
+
+// failedResults returns the checks of the report which failed.
+func (r *Report) failedResults() []*CheckReport {
+	var failed []*CheckReport
+	for _, result := range r.Results {
+		if normalizedOutcome(result.Outcome) == FailOutcomeType {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// chartTitle returns the name and version of the chart of the report, or its
+// URI if the chart could not be read.
+func (r *Report) chartTitle() string {
+	chart := r.Metadata.ChartData
+	if chart == nil || chart.Name == "" {
+		return r.Metadata.ToolMetadata.ChartUri
+	}
+	return strings.TrimSpace(chart.Name + " " + chart.Version)
+}
+
+// metadataRows returns the metadata of the report which is set.
+func (r *Report) metadataRows() []metadataRow {
+	tool := r.Metadata.ToolMetadata
+	rows := []metadataRow{
+		{"Chart URI", tool.ChartUri},
+		{"Profile", strings.TrimSpace(tool.Profile.VendorType + " " + tool.Profile.Version)},
+		{"Verifier version", tool.Version},
+		{"Chart digest", tool.Digests.Chart},
+		{"Package digest", tool.Digests.Package},
+		{"Report digest", tool.ReportDigest},
+		{"Tested OpenShift version", tool.TestedOpenShiftVersion},
+		{"Certified OpenShift versions", tool.CertifiedOpenShiftVersions},
+		{"Supported OpenShift versions", tool.SupportedOpenShiftVersions},
+		{"Chart overrides", r.Metadata.Overrides},
+	}
+	if tool.ProviderDelivery {
+		rows = append(rows, metadataRow{"Provider controlled delivery", "true"})
+	}
+	if run := tool.Run; run != nil {
+		rows = append(rows,
+			metadataRow{"Start time", run.StartTime},
+			metadataRow{"End time", run.EndTime},
+			metadataRow{"Duration", run.Duration},
+			metadataRow{"Kubernetes version", run.KubernetesVersion},
+			metadataRow{"Helm version", run.HelmVersion},
+			metadataRow{"Kube context", run.KubeContext},
+			metadataRow{"Values files", strings.Join(run.ValuesFiles, ", ")},
+			metadataRow{"Set values", strings.Join(run.SetValues, ", ")},
+		)
+	}
+
+	var set []metadataRow
+	for _, row := range rows {
+		if row.Value != "" {
+			set = append(set, row)
+		}
+	}
+	return set
+}
@@ -20,8 +20,25 @@ const (
 	SkippedOutcomeType OutcomeType = "SKIPPED"
 	UnknownOutcomeType OutcomeType = "UNKNOWN"
 
+	// ClusterVersionDetection reads the version from the OpenShift
+	// ClusterVersion resource.
+	ClusterVersionDetection OpenShiftVersionDetection = "ClusterVersion"
+	// KubeVersionMappingDetection maps the Kubernetes server version to
+	// an OpenShift version.
+	KubeVersionMappingDetection OpenShiftVersionDetection = "KubeVersionMapping"
+	// FlagDetection uses the version set by the openshift-version flag.
+	FlagDetection OpenShiftVersionDetection = "OpenShiftVersionFlag"
+
+	ErrorSeverity   FindingSeverity = "error"
+	WarningSeverity FindingSeverity = "warning"
+	InfoSeverity    FindingSeverity = "info"
+
 	JSONReport ReportFormat = "json"
 	YamlReport ReportFormat = "yaml"
+	// MarkdownReport and HTMLReport are for people to read, they cannot be
+	// loaded back.
+	MarkdownReport ReportFormat = "markdown"
+	HTMLReport     ReportFormat = "html"
 
 	ReportShaVersion string = "v1.9.0"
 )
@@ -53,13 +70,18 @@ func (r *Report) GetContent(format ReportFormat) (string, error) {
 		return "", loadErr
 	}
 
-	if format == JSONReport {
+	switch format {
+	case JSONReport:
 		b, marshalErr := json.Marshal(report)
 		if marshalErr != nil {
 			return "", fmt.Errorf("report json marshal failed : %v", marshalErr)
 		}
 		reportContent = string(b)
-	} else {
+	case MarkdownReport:
+		reportContent = report.markdown()
+	case HTMLReport:
+		return report.html()
+	default:
 		b, marshalErr := yaml.Marshal(report)
 		if marshalErr != nil {
 			return "", fmt.Errorf("report yaml marshal failed : %v", marshalErr)
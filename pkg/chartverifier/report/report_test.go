@@ -0,0 +1,140 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const reportWithoutFindings = `apiversion: v1
+kind: verify-report
+metadata:
+    tool:
+        verifier-version: 1.13.0
+        profile:
+            VendorType: partner
+            version: v1.3
+        chart-uri: chart-0.1.0-v3.with-crd.tgz
+        digests:
+            chart: sha256:0123456789abcdef
+    chart:
+        name: chart
+        version: 0.1.0
+    chart-overrides: ""
+results:
+    - check: v1.0/not-contains-crds
+      type: Mandatory
+      outcome: FAIL
+      reason: |-
+        Chart contains CRDs
+        CustomResourceDefinition "backservs.service.example.com" in crds/backend.yaml
+`
+
+const reportToRender = `apiversion: v1
+kind: verify-report
+metadata:
+    tool:
+        verifier-version: 1.13.0
+        profile:
+            VendorType: partner
+            version: v1.3
+        chart-uri: chart-0.1.0-v3.with-crd.tgz
+        digests:
+            chart: sha256:0123456789abcdef
+        run:
+            duration: 3m11.036s
+            helmVersion: v4.2.2
+    chart:
+        name: chart
+        version: 0.1.0
+    chart-overrides: ""
+results:
+    - check: v1.0/not-contains-crds
+      type: Mandatory
+      outcome: Fail
+      reason: |-
+        Chart contains CRDs
+        CustomResourceDefinition "backservs.service.example.com" in crds/backend.yaml
+      findings:
+        - severity: error
+          code: crd-template
+          message: CRD rendered from a <template>
+          object: CustomResourceDefinition "backservs.service.example.com"
+          path: crds/backend.yaml
+          remediation: Remove the CRD | or not
+    - check: v1.0/helm-lint
+      type: Mandatory
+      outcome: PASS
+      reason: Helm lint successful
+      duration: 234ms
+`
+
+func TestFindings(t *testing.T) {
+	r, err := NewReport().SetContent(reportWithoutFindings).Load()
+	require.NoError(t, err)
+	require.Len(t, r.Results, 1)
+	require.Empty(t, r.Results[0].Findings)
+
+	digest, err := r.GetReportDigest()
+	require.NoError(t, err)
+
+	r.Results[0].Findings = []Finding{{
+		Severity:    ErrorSeverity,
+		Code:        "crd-template",
+		Message:     "CRD rendered from a template",
+		Object:      `CustomResourceDefinition "backservs.service.example.com"`,
+		Path:        "crds/backend.yaml",
+		Remediation: "Remove the CRD from the chart",
+	}}
+	findingsDigest, err := r.GetReportDigest()
+	require.NoError(t, err)
+	require.Equal(t, digest, findingsDigest)
+
+	for _, format := range []ReportFormat{YamlReport, JSONReport} {
+		content, err := r.GetContent(format)
+		require.NoError(t, err)
+		loaded, err := NewReport().SetContent(content).Load()
+		require.NoError(t, err)
+		require.Equal(t, r.Results[0].Findings, loaded.Results[0].Findings)
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	r, err := NewReport().SetContent(reportToRender).Load()
+	require.NoError(t, err)
+
+	markdown, err := r.GetContent(MarkdownReport)
+	require.NoError(t, err)
+	require.Contains(t, markdown, "# Chart verifier report: chart 0.1.0\n")
+	require.Contains(t, markdown, "**:x: FAIL**: 1 passed, 1 failed, 0 skipped")
+	require.Contains(t, markdown, "| v1.0/not-contains-crds | Mandatory | :x: Fail |  |\n")
+	require.Contains(t, markdown, "| v1.0/helm-lint | Mandatory | :white_check_mark: PASS | 234ms |\n")
+	require.Contains(t, markdown, "### v1.0/not-contains-crds\n\n```\nChart contains CRDs\n")
+	require.Contains(t, markdown, "| error | crd-template | CRD rendered from a &lt;template&gt; |")
+	require.Contains(t, markdown, "| Remove the CRD \\| or not |")
+	require.Contains(t, markdown, "| Profile | partner v1.3 |")
+	require.Contains(t, markdown, "| Chart digest | sha256:0123456789abcdef |")
+	require.Contains(t, markdown, "| Helm version | v4.2.2 |")
+	require.NotContains(t, markdown, "| Report digest |")
+	require.NotContains(t, markdown, "### v1.0/helm-lint")
+
+	html, err := r.GetContent(HTMLReport)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	require.Contains(t, html, "<title>Chart verifier report: chart 0.1.0</title>")
+	require.Contains(t, html, `<span class="outcome FAIL">FAIL</span>: 1 passed, 1 failed, 0 skipped`)
+	require.Contains(t, html, `<td class="outcome PASS">PASS</td><td>234ms</td>`)
+	require.Contains(t, html, `<td class="outcome FAIL">Fail</td>`)
+	require.Contains(t, html, "<h3>v1.0/not-contains-crds</h3>")
+	require.Contains(t, html, "CRD rendered from a &lt;template&gt;")
+	require.Contains(t, html, "<tr><th>Helm version</th><td>v4.2.2</td></tr>")
+	require.NotContains(t, html, "<h3>v1.0/helm-lint</h3>")
+	require.NotContains(t, html, "<script")
+	require.NotContains(t, html, "<link")
+}
+
+func TestMarkdownCodeBlock(t *testing.T) {
+	require.Equal(t, "```\nreason\n```\n", markdownCodeBlock("reason\n"))
+	require.Equal(t, "````\nsee ```yaml\n````\n", markdownCodeBlock("see ```yaml"))
+}
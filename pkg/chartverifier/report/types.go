@@ -9,8 +9,10 @@ import (
 )
 
 type (
-	ReportFormat = string
-	OutcomeType  = string
+	ReportFormat              = string
+	OutcomeType               = string
+	OpenShiftVersionDetection = string
+	FindingSeverity           = string
 )
 
 type ShaValue struct{}
@@ -42,6 +44,54 @@ type ToolMetadata struct {
 	SupportedOpenShiftVersions string  `json:"supportedOpenShiftVersions,omitempty" yaml:"supportedOpenShiftVersions,omitempty"`
 	ProviderDelivery           bool    `json:"providerControlledDelivery,omitempty" yaml:"providerControlledDelivery,omitempty"`
 	WebCatalogOnly             bool    `json:"webCatalogOnly" yaml:"webCatalogOnly" hash:"ignore"`
+	// ChartTesting is excluded from the digest so that reports generated
+	// before it was added keep verifying.
+	ChartTesting *ChartTestingMetadata `json:"chartTesting,omitempty" yaml:"chartTesting,omitempty" hash:"ignore"`
+	// Run differs between runs of the same report, so it is excluded from
+	// the digest.
+	Run *RunMetadata `json:"run,omitempty" yaml:"run,omitempty" hash:"ignore"`
+}
+
+// RunMetadata describes when and how the checks were run.
+type RunMetadata struct {
+	StartTime string `json:"startTime" yaml:"startTime"`
+	EndTime   string `json:"endTime" yaml:"endTime"`
+	Duration  string `json:"duration" yaml:"duration"`
+	// KubernetesVersion is the server version of the cluster the chart was
+	// tested on, if any.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+	HelmVersion       string `json:"helmVersion,omitempty" yaml:"helmVersion,omitempty"`
+	// KubeContext is the sha256 digest of the name of the kube context, so
+	// that runs on the same context can be told apart without naming it.
+	KubeContext string `json:"kubeContext,omitempty" yaml:"kubeContext,omitempty"`
+	// ValuesFiles are the values files of the chart, without the
+	// credentials and queries of their URLs.
+	ValuesFiles []string `json:"valuesFiles,omitempty" yaml:"valuesFiles,omitempty"`
+	// SetValues are the values set on the command line, as key=digest with
+	// the sha256 digest of the value.
+	SetValues []string `json:"setValues,omitempty" yaml:"setValues,omitempty"`
+}
+
+// ChartTestingMetadata records how the chart-testing check exercised the chart.
+type ChartTestingMetadata struct {
+	UpgradedFromChart   string `json:"upgradedFromChart,omitempty" yaml:"upgradedFromChart,omitempty"`
+	UpgradedFromVersion string `json:"upgradedFromVersion,omitempty" yaml:"upgradedFromVersion,omitempty"`
+	ServerSideApply     bool   `json:"serverSideApply" yaml:"serverSideApply"`
+	WaitStrategy        string `json:"waitStrategy,omitempty" yaml:"waitStrategy,omitempty"`
+	// Environment describes the cluster the chart was tested on.
+	Environment *TestEnvironment `json:"environment,omitempty" yaml:"environment,omitempty"`
+}
+
+// TestEnvironment describes the cluster the chart-testing check ran against.
+type TestEnvironment struct {
+	// EphemeralClusterProvider is set when the chart was tested on a
+	// throwaway local cluster, e.g. kind.
+	EphemeralClusterProvider string `json:"ephemeralClusterProvider,omitempty" yaml:"ephemeralClusterProvider,omitempty"`
+	KubernetesVersion        string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+	OpenShift                bool   `json:"openShift" yaml:"openShift"`
+	// OpenShiftVersionDetection is how the tested OpenShift version was
+	// determined.
+	OpenShiftVersionDetection OpenShiftVersionDetection `json:"openShiftVersionDetection,omitempty" yaml:"openShiftVersionDetection,omitempty"`
 }
 
 type Digests struct {
@@ -60,6 +110,50 @@ type CheckReport struct {
 	Type    apichecks.CheckType `json:"type" yaml:"type"`
 	Outcome OutcomeType         `json:"outcome" yaml:"outcome"`
 	Reason  string              `json:"reason" yaml:"reason"`
+	// ValuesFileResults breaks down the chart-testing check per values file.
+	// It holds timings, so it is excluded from the digest.
+	ValuesFileResults []ValuesFileResult `json:"valuesFileResults,omitempty" yaml:"valuesFileResults,omitempty" hash:"ignore"`
+	// Findings details the reason of the check. They are excluded from the
+	// digest so that older reports keep verifying.
+	Findings []Finding `json:"findings,omitempty" yaml:"findings,omitempty" hash:"ignore"`
+	// StartTime, EndTime and Duration time the check. They are excluded
+	// from the digest.
+	StartTime string `json:"startTime,omitempty" yaml:"startTime,omitempty" hash:"ignore"`
+	EndTime   string `json:"endTime,omitempty" yaml:"endTime,omitempty" hash:"ignore"`
+	Duration  string `json:"duration,omitempty" yaml:"duration,omitempty" hash:"ignore"`
+}
+
+// ValuesFileResult is the outcome of installing and testing the chart with
+// a single values file.
+type ValuesFileResult struct {
+	ValuesFile string       `json:"valuesFile" yaml:"valuesFile"`
+	Outcome    OutcomeType  `json:"outcome" yaml:"outcome"`
+	Steps      []StepResult `json:"steps" yaml:"steps"`
+}
+
+// StepResult is the outcome of one step, e.g. install or test, of a
+// ValuesFileResult.
+type StepResult struct {
+	Name     string      `json:"name" yaml:"name"`
+	Outcome  OutcomeType `json:"outcome" yaml:"outcome"`
+	Duration string      `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Message  string      `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// Finding is a single problem, or notice, found by a check.
+type Finding struct {
+	Severity FindingSeverity `json:"severity" yaml:"severity"`
+	// Code identifies the kind of finding, e.g. image-not-certified.
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+	// Object is the object or image the finding is about, e.g.
+	// CustomResourceDefinition "foos.example.com".
+	Object string `json:"object,omitempty" yaml:"object,omitempty"`
+	// Path is the file of the chart the finding is about.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// ValuesFile is the CI values file the chart was rendered with, if any.
+	ValuesFile  string `json:"valuesFile,omitempty" yaml:"valuesFile,omitempty"`
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
 }
 
 type reportOptions struct {
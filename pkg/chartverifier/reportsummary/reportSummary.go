@@ -33,6 +33,10 @@ const (
 
 	JSONReport SummaryFormat = "json"
 	YAMLReport SummaryFormat = "yaml"
+	// MarkdownReport and HTMLReport render the whole report, so they are
+	// only supported for the all summary.
+	MarkdownReport SummaryFormat = "markdown"
+	HTMLReport     SummaryFormat = "html"
 
 	// SkipDigestCheck: Use for testing purpose only
 	SkipDigestCheck BooleanKey = "skipDigestCheck"
@@ -103,6 +107,13 @@ func (r *ReportSummary) GetContent(summary SummaryType, format SummaryFormat) (s
 		}
 	}
 
+	if format == MarkdownReport || format == HTMLReport {
+		if summary != AllSummary {
+			return "", fmt.Errorf("%s format is only supported for the %s summary", format, AllSummary)
+		}
+		return r.options.report.GetContent(string(format))
+	}
+
 	switch summary {
 	case MetadataSummary:
 		outputSummary.MetadataReport = r.MetadataReport
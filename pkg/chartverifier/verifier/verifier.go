@@ -27,6 +27,7 @@ import (
 
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/api"
 	"github.com/redhat-certification/chart-verifier/internal/chartverifier/profiles"
+	"github.com/redhat-certification/chart-verifier/internal/tool"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/report"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/version"
@@ -62,6 +63,8 @@ const (
 	ChartValues      StringKey = "chart-values"
 	KubeAsGroups     StringKey = "kube-as-group"
 	PGPPublicKey     StringKey = "pgp-public-key"
+	WaitStrategy     StringKey = "wait-strategy"
+	EphemeralCluster StringKey = "ephemeral-cluster"
 
 	ChartSet       ValuesKey = "chart-set"
 	ChartSetFile   ValuesKey = "chart-set-file"
@@ -72,6 +75,7 @@ const (
 	ProviderDelivery BooleanKey = "provider-delivery" // Deprecated in 1.10
 	SuppressErrorLog BooleanKey = "suppress-error-log"
 	SkipCleanup      BooleanKey = "skip-cleanup"
+	ServerSideApply  BooleanKey = "server-side-apply"
 
 	Timeout            DurationKey = "timeout"
 	HelmInstallTimeout DurationKey = "helm-install-timeout"
@@ -93,6 +97,8 @@ var setStringKeys = [...]StringKey{
 	ChartValues,
 	KubeAsGroups,
 	PGPPublicKey,
+	WaitStrategy,
+	EphemeralCluster,
 }
 
 var setValuesKeys = [...]ValuesKey{
@@ -102,7 +108,7 @@ var setValuesKeys = [...]ValuesKey{
 	ChartSetString,
 }
 
-var setBooleanKeys = [...]BooleanKey{WebCatalogOnly, SuppressErrorLog, SkipCleanup}
+var setBooleanKeys = [...]BooleanKey{WebCatalogOnly, SuppressErrorLog, SkipCleanup, ServerSideApply}
 
 var setDurationKeys = [...]DurationKey{Timeout, HelmInstallTimeout}
 
@@ -300,6 +306,8 @@ func (v *Verifier) Run(chartURI string) (APIVerifier, error) {
 	}
 
 	runOptions.Values = vals
+	runOptions.ValuesFiles = opts.ValueFiles
+	runOptions.SetValues = append(append(append([]string{}, opts.Values...), opts.StringValues...), opts.FileValues...)
 	runOptions.Overrides = v.Inputs.Flags.ValuesFlags[CommandSet]
 
 	for checkName, checkStatus := range v.Inputs.Flags.Checks {
@@ -326,6 +334,18 @@ func (v *Verifier) Run(chartURI string) (APIVerifier, error) {
 		runOptions.SkipCleanup = booleanValue
 	}
 
+	if booleanValue, ok := v.Inputs.Flags.BooleanFlags[ServerSideApply]; ok {
+		runOptions.ServerSideApply = booleanValue
+	}
+
+	if stringsValue, ok := v.Inputs.Flags.StringFlags[WaitStrategy]; ok && len(stringsValue) > 0 {
+		runOptions.WaitStrategy = stringsValue[0]
+	}
+
+	if stringsValue, ok := v.Inputs.Flags.StringFlags[EphemeralCluster]; ok && len(stringsValue) > 0 {
+		runOptions.EphemeralCluster = stringsValue[0]
+	}
+
 	if durationValue, ok := v.Inputs.Flags.DurationFlags[Timeout]; ok {
 		runOptions.ClientTimeout = durationValue
 	}
@@ -374,6 +394,7 @@ func (v *Verifier) initialize() {
 	v.Inputs.Flags.BooleanFlags[WebCatalogOnly] = false
 	v.Inputs.Flags.BooleanFlags[SuppressErrorLog] = false
 	v.Inputs.Flags.BooleanFlags[SkipCleanup] = false
+	v.Inputs.Flags.BooleanFlags[ServerSideApply] = false
 	v.Inputs.Flags.DurationFlags = make(map[DurationKey]time.Duration)
 	v.Inputs.Flags.Checks = make(map[checks.CheckName]CheckStatus)
 
@@ -403,9 +424,33 @@ func (v Verifier) checkInputs() error {
 	if err == nil {
 		err = validateStringKeys(v)
 	}
+	if err == nil {
+		err = validateWaitStrategy(v)
+	}
+	if err == nil {
+		err = validateEphemeralCluster(v)
+	}
 	return err
 }
 
+func validateWaitStrategy(v Verifier) error {
+	if stringsValue, ok := v.Inputs.Flags.StringFlags[WaitStrategy]; ok && len(stringsValue) > 0 {
+		if _, err := tool.ParseWaitStrategy(stringsValue[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEphemeralCluster(v Verifier) error {
+	if stringsValue, ok := v.Inputs.Flags.StringFlags[EphemeralCluster]; ok && len(stringsValue) > 0 && stringsValue[0] != "" {
+		if !slices.Contains(tool.EphemeralClusterProviders(), strings.ToLower(stringsValue[0])) {
+			return fmt.Errorf("invalid ephemeral cluster provider: %s", stringsValue[0])
+		}
+	}
+	return nil
+}
+
 func mapToStringSlice(valuesMap map[string]interface{}) []string {
 	var values []string
 	for name, value := range valuesMap {
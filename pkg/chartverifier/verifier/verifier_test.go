@@ -128,6 +128,18 @@ func TestBadFlags(t *testing.T) {
 	require.Error(t, runErr)
 	require.Contains(t, fmt.Sprint(runErr), "invalid duration key name: BadDurationKey")
 
+	_, runErr = NewVerifier().
+		SetString(WaitStrategy, []string{"eventually"}).
+		Run("../../../internal/chartverifier/checks/chart-0.1.0-v3.valid.tgz")
+	require.Error(t, runErr)
+	require.Contains(t, fmt.Sprint(runErr), `invalid wait strategy "eventually"`)
+
+	_, runErr = NewVerifier().
+		SetString(EphemeralCluster, []string{"minikube"}).
+		Run("../../../internal/chartverifier/checks/chart-0.1.0-v3.valid.tgz")
+	require.Error(t, runErr)
+	require.Contains(t, fmt.Sprint(runErr), "invalid ephemeral cluster provider: minikube")
+
 	_, runErr = NewVerifier().
 		Run("")
 	require.Error(t, runErr)